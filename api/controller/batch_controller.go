@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type BatchController struct {
+	ReportUC   *usecase.ReportUseCase
+	LocationUC *usecase.LocationUseCase
+}
+
+// POST /sync/batch — gửi bù report + location lưu khi offline
+func (bc *BatchController) Submit(c *gin.Context) {
+	userID := c.GetString("x-user-id")
+
+	var req domain.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resp, err := runBatch(c.Request.Context(), bc.ReportUC, bc.LocationUC, userID, req)
+	if errors.Is(err, errBatchTooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+var errBatchTooLarge = fmt.Errorf("batch too large: max %d items", domain.MaxBatchItems)
+
+// runBatch dùng chung cho REST và frame WS "batch"
+func runBatch(ctx context.Context, reportUC *usecase.ReportUseCase, locUC *usecase.LocationUseCase, userID string, req domain.BatchRequest) (domain.BatchResponse, error) {
+	if userID == "" {
+		return domain.BatchResponse{}, errors.New("missing user")
+	}
+	if len(req.Reports)+len(req.Locations) > domain.MaxBatchItems {
+		return domain.BatchResponse{}, errBatchTooLarge
+	}
+
+	return domain.BatchResponse{
+		Reports:   reportUC.SubmitBatch(ctx, userID, req.Reports),
+		Locations: locUC.SubmitBatch(ctx, userID, req.Locations),
+	}, nil
+}
@@ -1,10 +1,9 @@
 package controller
 
 import (
-	"math"
+	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/gin-gonic/gin"
@@ -20,29 +19,25 @@ type CellController struct {
 type UpdateCellRequest struct {
 	Lat       float64 `json:"lat" binding:"required"`
 	Lon       float64 `json:"lon" binding:"required"`
-	RiskScore float64 `json:"riskScore" binding:"required"`
-	Label     string  `json:"label" binding:"required"`
+	RiskScore float64 `json:"riskScore"` // chặn trong [0, 1]
+	Label     string  `json:"label"`     // rỗng = tính theo riskScore
 }
 
+// Mode "increment" (mặc định) cộng riskInc vào risk hiện có, "set" ghi đè bằng riskScore
 type UpdateCellsRadiusRequest struct {
-	Lat     float64 `json:"lat" binding:"required"`
-	Lon     float64 `json:"lon" binding:"required"`
-	Radius  float64 `json:"radius" binding:"required"`
-	RiskInc float64 `json:"riskInc" binding:"required"` // tăng/giảm risk
-	Label   string  `json:"label"`
+	Lat       float64  `json:"lat" binding:"required"`
+	Lon       float64  `json:"lon" binding:"required"`
+	Radius    float64  `json:"radius" binding:"required"` // mét
+	Mode      string   `json:"mode"`
+	RiskInc   float64  `json:"riskInc"` // tăng/giảm risk
+	RiskScore *float64 `json:"riskScore"`
+	Label     string   `json:"label"`
 }
 
-// ----------------------
-// Helpers
-// ----------------------
-
-// snapGrid chuẩn hóa 1 điểm thành cell ~100x100m
-func snapGrid(lat, lon float64) (float64, float64) {
-	const cellSize = 0.001
-	latNorm := math.Floor(lat/cellSize) * cellSize
-	lonNorm := math.Floor(lon/cellSize) * cellSize
-	return latNorm, lonNorm
-}
+const (
+	CellModeIncrement = "increment"
+	CellModeSet       = "set"
+)
 
 // ----------------------
 // Handlers
@@ -65,17 +60,6 @@ func (cc *CellController) GetCellByLatLon(c *gin.Context) {
 		return
 	}
 
-	// Nếu chưa có cell, trả về mặc định risk=0
-	if cell == nil {
-		latSnap, lonSnap := snapGrid(lat, lon)
-		cell = &domain.Cell{
-			Center:    [2]float64{lonSnap, latSnap}, // [lon, lat]
-			RiskScore: 0,
-			Label:     "SAFE",
-			UpdatedAt: time.Now(),
-		}
-	}
-
 	c.JSON(http.StatusOK, cell)
 }
 
@@ -118,15 +102,9 @@ func (cc *CellController) UpdateCell(c *gin.Context) {
 		return
 	}
 
-	latSnap, lonSnap := snapGrid(req.Lat, req.Lon)
-	cell := &domain.Cell{
-		Center:    [2]float64{lonSnap, latSnap}, // lưu [lon, lat] để index
-		RiskScore: req.RiskScore,
-		Label:     req.Label,
-		UpdatedAt: time.Now(),
-	}
-
-	if err := cc.CellUsecase.Upsert(c, cell); err != nil {
+	// usecase chuẩn hóa điểm về cell geohash chứa nó
+	cell, err := cc.CellUsecase.UpdateCell(c, req.Lat, req.Lon, req.RiskScore, req.Label)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
 	}
@@ -134,7 +112,7 @@ func (cc *CellController) UpdateCell(c *gin.Context) {
 	c.JSON(http.StatusOK, cell)
 }
 
-// UpdateCellsByRadius create/update cells within radius, increasing/decreasing risk
+// UpdateCellsByRadius create/update cells within radius (meters), increasing/decreasing or setting risk
 func (cc *CellController) UpdateCellsByRadius(c *gin.Context) {
 	var req UpdateCellsRadiusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -142,7 +120,31 @@ func (cc *CellController) UpdateCellsByRadius(c *gin.Context) {
 		return
 	}
 
-	updated, inserted, err := cc.CellUsecase.UpdateCellsByRadius(c, req.Lat, req.Lon, req.Radius, req.RiskInc, req.Label)
+	var (
+		updated, inserted int
+		err               error
+	)
+	switch req.Mode {
+	case "", CellModeIncrement:
+		if req.RiskInc == 0 {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "riskInc is required"})
+			return
+		}
+		updated, inserted, err = cc.CellUsecase.UpdateCellsByRadius(c, req.Lat, req.Lon, req.Radius, req.RiskInc, req.Label)
+	case CellModeSet:
+		if req.RiskScore == nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "riskScore is required"})
+			return
+		}
+		updated, inserted, err = cc.CellUsecase.SetCellsByRadius(c, req.Lat, req.Lon, req.Radius, *req.RiskScore, req.Label)
+	default:
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "mode must be increment or set"})
+		return
+	}
+	if errors.Is(err, domain.ErrGridTooLarge) || errors.Is(err, domain.ErrInvalidRadius) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
@@ -153,3 +155,37 @@ func (cc *CellController) UpdateCellsByRadius(c *gin.Context) {
 		"inserted": inserted,
 	})
 }
+
+// FetchGrid lưới risk trong bounding box: GET /cells/grid?minLat&minLon&maxLat&maxLon&level=7&zones=true
+// level nhỏ hơn là cell cha (zoom xa), zones=true chiếu risk của zone lên lưới
+func (cc *CellController) FetchGrid(c *gin.Context) {
+	minLat, ok1 := getFloatQuery(c, "minLat")
+	minLon, ok2 := getFloatQuery(c, "minLon")
+	maxLat, ok3 := getFloatQuery(c, "maxLat")
+	maxLon, ok4 := getFloatQuery(c, "maxLon")
+	if !(ok1 && ok2 && ok3 && ok4) || minLat > maxLat {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid bounding box"})
+		return
+	}
+
+	level, err := strconv.Atoi(c.DefaultQuery("level", strconv.Itoa(domain.CellLevel)))
+	if err != nil || level < domain.MinCellLevel || level > domain.CellLevel {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid level"})
+		return
+	}
+	withZones := c.Query("zones") == "true"
+
+	cells, err := cc.CellUsecase.FetchGrid(c, domain.BoundsFilter{
+		MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon,
+	}, level, withZones)
+	if errors.Is(err, domain.ErrGridTooLarge) {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cells)
+}
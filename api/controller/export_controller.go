@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/export"
+	"github.com/gin-gonic/gin"
+)
+
+type ExportController struct {
+	ExportUsecase domain.ExportUsecase
+}
+
+// =======================
+// GET /export/:layer?format=geojson|kml|csv
+// layer: reports | alerts | zones | cells, filter giống API list tương ứng:
+//
+//	reports, alerts: lat, lon, km
+//	cells:           lat, lon, radius (m)
+//	zones:           minLat, minLon, maxLat, maxLon
+//
+// =======================
+func (ec *ExportController) Export(c *gin.Context) {
+	layer := c.Param("layer")
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatGeoJSON))
+
+	switch format {
+	case export.FormatGeoJSON, export.FormatKML, export.FormatCSV:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"message": export.ErrUnsupportedFormat.Error()})
+		return
+	}
+
+	filter, err := parseExportFilter(c, layer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	mime, ext := export.ContentType(format)
+	c.Header("Content-Type", mime)
+	c.Header("Content-Disposition", `attachment; filename="`+layer+"."+ext+`"`)
+	c.Status(http.StatusOK)
+
+	// đã bắt đầu stream thì không đổi status được nữa, chỉ log và cắt kết nối
+	if err := ec.ExportUsecase.Export(c.Request.Context(), layer, format, filter, c.Writer); err != nil {
+		log.Printf("export %s (%s) failed: %v", layer, format, err)
+		c.Abort()
+	}
+}
+
+func parseExportFilter(c *gin.Context, layer string) (domain.ExportFilter, error) {
+	var f domain.ExportFilter
+
+	switch layer {
+	case domain.ExportLayerReports, domain.ExportLayerAlerts:
+		near, err := parseNear(c, "km", 1000)
+		f.Near = near
+		return f, err
+
+	case domain.ExportLayerCells:
+		near, err := parseNear(c, "radius", 1)
+		f.Near = near
+		return f, err
+
+	case domain.ExportLayerZones:
+		if c.Query("minLat") == "" && c.Query("minLon") == "" && c.Query("maxLat") == "" && c.Query("maxLon") == "" {
+			return f, nil
+		}
+		minLat, ok1 := getFloatQuery(c, "minLat")
+		minLon, ok2 := getFloatQuery(c, "minLon")
+		maxLat, ok3 := getFloatQuery(c, "maxLat")
+		maxLon, ok4 := getFloatQuery(c, "maxLon")
+		if !(ok1 && ok2 && ok3 && ok4) {
+			return f, errors.New("invalid bounding box")
+		}
+		f.Bounds = &domain.BoundsFilter{MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon}
+		return f, nil
+	}
+	return f, domain.ErrUnknownExportLayer
+}
+
+// parseNear đọc lat, lon và tham số bán kính; không truyền gì thì export toàn bộ
+func parseNear(c *gin.Context, radiusKey string, toMeters float64) (*domain.NearFilter, error) {
+	if c.Query("lat") == "" && c.Query("lon") == "" && c.Query(radiusKey) == "" {
+		return nil, nil
+	}
+	lat, ok1 := getFloatQuery(c, "lat")
+	lon, ok2 := getFloatQuery(c, "lon")
+	radius, ok3 := getFloatQuery(c, radiusKey)
+	if !(ok1 && ok2 && ok3) || radius <= 0 {
+		return nil, errors.New("invalid lat/lon/" + radiusKey)
+	}
+	return &domain.NearFilter{Lat: lat, Lon: lon, RadiusM: radius * toMeters}, nil
+}
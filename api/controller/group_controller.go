@@ -2,11 +2,15 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"slices"
+	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type GroupController struct {
@@ -25,10 +29,10 @@ func (gc *GroupController) CreateGroup(c *gin.Context) {
 		return
 	}
 
-	// Lấy user ID từ middleware JWT
-	// userID := c.GetString("x-user-id")
+	// Lấy user ID từ middleware JWT, người tạo là owner
+	userID := c.GetString("x-user-id")
 
-	group, err := gc.GroupUsecase.CreateGroup(c, req.Name)
+	group, err := gc.GroupUsecase.CreateGroup(c, userID, req.Name)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
 		return
@@ -43,27 +47,23 @@ func (gc *GroupController) GetByInviteCode(c *gin.Context) {
 
 	group, err := gc.GroupUsecase.GetByInviteCode(c, code)
 	if err != nil {
-		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Group not found"})
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, group)
 }
 
-// 📍 DELETE /group/:id — out nhóm
+// 📍 DELETE /groups/:groupId — xóa nhóm, chỉ owner
 func (gc *GroupController) Delete(c *gin.Context) {
-	idStr := c.Param("groupId")
-	groupID, err := primitive.ObjectIDFromHex(idStr)
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
 		return
 	}
 
-	userID := c.GetString("x-user-id")
-
-	err = gc.GroupUsecase.DeleteGroup(c, userID, groupID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+	if err := gc.GroupUsecase.DeleteGroup(c, c.GetString("x-user-id"), groupID); err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
@@ -72,25 +72,180 @@ func (gc *GroupController) Delete(c *gin.Context) {
 	})
 }
 
-// 📍 GET /groups/:groupId/invite — lấy mã invite của group
-func (gc *GroupController) GetInviteCodeByGroupID(c *gin.Context) {
-	idStr := c.Param("groupId")
+// 📍 DELETE /groups/:groupId/members — out nhóm
+func (gc *GroupController) Leave(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
+		return
+	}
+
+	if err := gc.GroupUsecase.LeaveGroup(c, c.GetString("x-user-id"), groupID); err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
 
-	groupID, err := primitive.ObjectIDFromHex(idStr)
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Left group successfully"})
+}
+
+// 📍 DELETE /groups/:groupId/members/:memberId — kick thành viên
+func (gc *GroupController) RemoveMember(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
 		return
 	}
 
-	code, err := gc.GroupUsecase.GetInviteCodeByGroupID(c, groupID)
+	if err := gc.GroupUsecase.RemoveMember(c, c.GetString("x-user-id"), groupID, c.Param("memberId")); err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Member removed successfully"})
+}
+
+// 📍 PUT /groups/:groupId/members/:memberId/role — nâng / hạ admin
+func (gc *GroupController) SetMemberRole(c *gin.Context) {
+	var req struct {
+		Role domain.GroupRole `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
 	if err != nil {
-		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Group not found"})
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"inviteCode": code,
-	})
+	if err := gc.GroupUsecase.SetMemberRole(c, c.GetString("x-user-id"), groupID, c.Param("memberId"), req.Role); err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Role updated successfully"})
+}
+
+// 📍 POST /groups/:groupId/transfer — chuyển owner
+func (gc *GroupController) TransferOwnership(c *gin.Context) {
+	var req struct {
+		UserID string `json:"userId" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
+		return
+	}
+
+	if err := gc.GroupUsecase.TransferOwnership(c, c.GetString("x-user-id"), groupID, req.UserID); err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Ownership transferred successfully"})
+}
+
+// groupErrorStatus: lỗi quyền / không tìm thấy của group usecase -> HTTP status
+func groupErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotGroupMember), errors.Is(err, domain.ErrGroupForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrGroupMemberNotFound), errors.Is(err, mongo.ErrNoDocuments),
+		errors.Is(err, domain.ErrInvalidInvite), errors.Is(err, domain.ErrInviteNotEnabled):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInviteExpired), errors.Is(err, domain.ErrInviteExhausted):
+		return http.StatusGone
+	case errors.Is(err, domain.ErrInvalidGroupRole), errors.Is(err, domain.ErrInviteSettings):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrOwnerMustTransfer), errors.Is(err, domain.ErrAlreadyInGroup):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// 📍 GET /groups/:groupId/invite — mã invite hiện tại (hạn, số lượt), chỉ thành viên
+func (gc *GroupController) GetInvite(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
+		return
+	}
+
+	inv, err := gc.GroupUsecase.GetInvite(c, c.GetString("x-user-id"), groupID)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, inv)
+}
+
+// 📍 GET /groups/:groupId/invite/link — deep link để chia sẻ / hiển thị QR
+func (gc *GroupController) GetInviteLink(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
+		return
+	}
+
+	link, err := gc.GroupUsecase.InviteLink(c, c.GetString("x-user-id"), groupID)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, link)
+}
+
+// 📍 POST /groups/:groupId/invite/rotate — tạo mã mới, owner / admin
+func (gc *GroupController) RotateInvite(c *gin.Context) {
+	var req struct {
+		TTLHours int `json:"ttlHours" binding:"omitempty,min=1,max=720"` // bỏ trống = hạn mặc định
+		MaxUses  int `json:"maxUses" binding:"omitempty,min=1,max=1000"` // bỏ trống = không giới hạn
+	}
+	// body không bắt buộc
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+			return
+		}
+	}
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
+		return
+	}
+
+	ttl := time.Duration(req.TTLHours) * time.Hour
+	inv, err := gc.GroupUsecase.RotateInvite(c, c.GetString("x-user-id"), groupID, ttl, req.MaxUses)
+	if err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, inv)
+}
+
+// 📍 DELETE /groups/:groupId/invite — thu hồi mã, owner / admin
+func (gc *GroupController) RevokeInvite(c *gin.Context) {
+	groupID, err := primitive.ObjectIDFromHex(c.Param("groupId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: "Invalid group ID"})
+		return
+	}
+
+	if err := gc.GroupUsecase.RevokeInvite(c, c.GetString("x-user-id"), groupID); err != nil {
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.SuccessResponse{Message: "Invite code revoked"})
 }
 
 // 📍 PUT /groups/join/:inviteCode
@@ -99,7 +254,7 @@ func (gc *GroupController) JoinGroup(c *gin.Context) {
 	userID := c.GetString("x-user-id")
 
 	if err := gc.GroupUsecase.JoinGroup(c, userID, inviteCode); err != nil {
-		c.JSON(http.StatusInternalServerError, domain.ErrorResponse{Message: err.Error()})
+		c.JSON(groupErrorStatus(err), domain.ErrorResponse{Message: err.Error()})
 		return
 	}
 
@@ -118,6 +273,12 @@ func (gc *GroupController) GetGroupByID(c *gin.Context) {
 		return
 	}
 
+	// mã mời chỉ cho thành viên thấy
+	viewerID, _ := primitive.ObjectIDFromHex(c.GetString("x-user-id"))
+	if !slices.Contains(group.MemberIDs, viewerID) {
+		group.InviteCode = ""
+	}
+
 	c.JSON(http.StatusOK, group)
 }
 
@@ -125,7 +286,11 @@ func (gc *GroupController) GetMemberInGroup(c *gin.Context) {
 	groupID := c.Param("groupId")
 	memberID := c.Param("memberId")
 
-	member, err := gc.GroupUsecase.GetMemberInGroup(c, groupID, memberID)
+	member, err := gc.GroupUsecase.GetMemberInGroup(c, c.GetString("x-user-id"), groupID, memberID)
+	if errors.Is(err, domain.ErrNotGroupMember) {
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
@@ -135,3 +300,22 @@ func (gc *GroupController) GetMemberInGroup(c *gin.Context) {
 
 	c.JSON(http.StatusOK, member)
 }
+
+// 📍 GET /groups/:groupId/members — mọi thành viên kèm vị trí mới nhất, chỉ thành viên nhóm được xem
+func (gc *GroupController) ListMembers(c *gin.Context) {
+	members, err := gc.GroupUsecase.ListMembers(c, c.GetString("x-user-id"), c.Param("groupId"))
+	if errors.Is(err, domain.ErrNotGroupMember) {
+		c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		c.JSON(http.StatusNotFound, domain.ErrorResponse{Message: "Group not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, domain.ErrorResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
@@ -2,15 +2,18 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
 	"github.com/gin-gonic/gin"
 )
 
 type ReportController struct {
 	ReportRepo domain.ReportRepository // dùng interface ReportRepository
+	ReportUC   *usecase.ReportUseCase
 	Timeout    time.Duration
 }
 
@@ -42,3 +45,77 @@ func (c *ReportController) MockReport(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"status": "ok", "report": input})
 }
+
+// GET /reports/:id
+func (c *ReportController) Get(ctx *gin.Context) {
+	report, err := c.ReportUC.GetByID(ctx, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GET /reports/:id/revisions
+func (c *ReportController) Revisions(ctx *gin.Context) {
+	revisions, err := c.ReportUC.ListRevisions(ctx, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+// PATCH /reports/:id — tác giả sửa report
+func (c *ReportController) Edit(ctx *gin.Context) {
+	var edit domain.ReportEdit
+	if err := ctx.ShouldBindJSON(&edit); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := ctx.GetString("x-user-id")
+	report, err := c.ReportUC.Edit(ctx, userID, ctx.Param("id"), edit)
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// POST /reports/:id/retract
+func (c *ReportController) Retract(ctx *gin.Context) {
+	userID := ctx.GetString("x-user-id")
+	report, err := c.ReportUC.Retract(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// POST /reports/:id/clear
+func (c *ReportController) Clear(ctx *gin.Context) {
+	userID := ctx.GetString("x-user-id")
+	report, err := c.ReportUC.Clear(ctx, userID, ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(reportErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+func reportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrReportNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrNotReportAuthor):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidReportLocation):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrReportRetracted), errors.Is(err, domain.ErrRevisionConflict):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
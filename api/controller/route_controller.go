@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type RouteController struct {
+	RouteUsecase domain.RouteUsecase
+}
+
+// =======================
+// POST /routes/assess
+// body: {"origin": {lat, lon}, "destination": {lat, lon}} hoặc {"polyline": [{lat, lon}, ...]}
+// =======================
+func (rc *RouteController) Assess(c *gin.Context) {
+	var req domain.RouteAssessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	resp, err := rc.RouteUsecase.Assess(c, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrRouteInput), errors.Is(err, domain.ErrRouteTooLong):
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
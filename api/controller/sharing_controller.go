@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SharingController struct {
+	SharingUsecase domain.SharingUsecase
+}
+
+type SharingRequest struct {
+	GroupIDs  []string `json:"groupIds"`                     // rỗng = mọi nhóm
+	Precision string   `json:"precision" binding:"required"` // EXACT | APPROX | AREA
+}
+
+type PauseSharingRequest struct {
+	Hours float64 `json:"hours" binding:"required"`
+}
+
+// GET /sharing — cài đặt chia sẻ vị trí của mình
+func (sc *SharingController) Get(c *gin.Context) {
+	s, err := sc.SharingUsecase.Get(c, c.GetString("x-user-id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// PUT /sharing
+func (sc *SharingController) Update(c *gin.Context) {
+	var req SharingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	groupIDs := make([]primitive.ObjectID, 0, len(req.GroupIDs))
+	for _, hex := range req.GroupIDs {
+		id, err := primitive.ObjectIDFromHex(hex)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid group id"})
+			return
+		}
+		groupIDs = append(groupIDs, id)
+	}
+
+	s, err := sc.SharingUsecase.Update(c, c.GetString("x-user-id"), groupIDs, req.Precision)
+	sc.reply(c, s, err)
+}
+
+// POST /sharing/pause — tạm dừng chia sẻ trong N giờ (SOS vẫn chia sẻ)
+func (sc *SharingController) Pause(c *gin.Context) {
+	var req PauseSharingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	s, err := sc.SharingUsecase.Pause(c, c.GetString("x-user-id"), time.Duration(req.Hours*float64(time.Hour)))
+	sc.reply(c, s, err)
+}
+
+// DELETE /sharing/pause — chia sẻ lại ngay
+func (sc *SharingController) Resume(c *gin.Context) {
+	s, err := sc.SharingUsecase.Resume(c, c.GetString("x-user-id"))
+	sc.reply(c, s, err)
+}
+
+func (sc *SharingController) reply(c *gin.Context, s *domain.SharingSettings, err error) {
+	if errors.Is(err, domain.ErrInvalidSharing) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
@@ -0,0 +1,313 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ShelterController struct {
+	ShelterUsecase domain.ShelterUsecase
+}
+
+// =======================
+// Request models
+// =======================
+type ShelterRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Address       string   `json:"address"`
+	Phone         string   `json:"phone"`
+	Lat           float64  `json:"lat" binding:"required"`
+	Lon           float64  `json:"lon" binding:"required"`
+	Capacity      int      `json:"capacity" binding:"required"`
+	Occupancy     int      `json:"occupancy"` // chỉ dùng khi tạo mới
+	Amenities     []string `json:"amenities"`
+	Accessibility []string `json:"accessibility"`
+	Status        string   `json:"status"` // mặc định OPEN
+}
+
+// PATCH /shelters/:id — chỉ đổi các field được gửi
+type PatchShelterRequest struct {
+	Name          *string   `json:"name"`
+	Address       *string   `json:"address"`
+	Phone         *string   `json:"phone"`
+	Lat           *float64  `json:"lat"`
+	Lon           *float64  `json:"lon"`
+	Capacity      *int      `json:"capacity"`
+	Amenities     *[]string `json:"amenities"`
+	Accessibility *[]string `json:"accessibility"`
+	Status        *string   `json:"status"`
+}
+
+// PATCH /shelters/:id/occupancy — gửi delta (check-in / check-out) hoặc occupancy (đếm lại)
+type OccupancyRequest struct {
+	Delta     *int `json:"delta"`
+	Occupancy *int `json:"occupancy"`
+}
+
+// =======================
+// POST /shelters
+// =======================
+func (sc *ShelterController) Create(c *gin.Context) {
+	var req ShelterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	s := &domain.Shelter{Occupancy: req.Occupancy, UpdatedBy: c.GetString("x-user-id")}
+	req.apply(s)
+
+	if err := sc.ShelterUsecase.Create(c, s); err != nil {
+		shelterError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, s)
+}
+
+func (req ShelterRequest) apply(s *domain.Shelter) {
+	s.Name = strings.TrimSpace(req.Name)
+	s.Address = req.Address
+	s.Phone = req.Phone
+	s.Location = domain.GeoPoint{Type: "Point", Coordinates: [2]float64{req.Lon, req.Lat}}
+	s.Capacity = req.Capacity
+	s.Amenities = req.Amenities
+	s.Accessibility = req.Accessibility
+	s.Status = strings.ToUpper(req.Status)
+	if s.Status == "" {
+		s.Status = domain.ShelterOpen
+	}
+}
+
+// =======================
+// PUT /shelters/:id
+// =======================
+func (sc *ShelterController) Replace(c *gin.Context) {
+	s, ok := sc.loadShelter(c)
+	if !ok {
+		return
+	}
+
+	var req ShelterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	req.apply(s)
+	sc.saveShelter(c, s)
+}
+
+// =======================
+// PATCH /shelters/:id
+// =======================
+func (sc *ShelterController) Patch(c *gin.Context) {
+	s, ok := sc.loadShelter(c)
+	if !ok {
+		return
+	}
+
+	var req PatchShelterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		s.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Address != nil {
+		s.Address = *req.Address
+	}
+	if req.Phone != nil {
+		s.Phone = *req.Phone
+	}
+	if req.Lat != nil {
+		s.Location.Coordinates[1] = *req.Lat
+	}
+	if req.Lon != nil {
+		s.Location.Coordinates[0] = *req.Lon
+	}
+	if req.Capacity != nil {
+		s.Capacity = *req.Capacity
+	}
+	if req.Amenities != nil {
+		s.Amenities = *req.Amenities
+	}
+	if req.Accessibility != nil {
+		s.Accessibility = *req.Accessibility
+	}
+	if req.Status != nil {
+		s.Status = strings.ToUpper(*req.Status)
+	}
+	sc.saveShelter(c, s)
+}
+
+// =======================
+// PATCH /shelters/:id/occupancy
+// =======================
+func (sc *ShelterController) Occupancy(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid shelter id"})
+		return
+	}
+
+	var req OccupancyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	var s *domain.Shelter
+	switch {
+	case req.Occupancy != nil:
+		s, err = sc.ShelterUsecase.SetOccupancy(c, id, *req.Occupancy, c.GetString("x-user-id"))
+	case req.Delta != nil:
+		s, err = sc.ShelterUsecase.AdjustOccupancy(c, id, *req.Delta, c.GetString("x-user-id"))
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"message": "delta or occupancy is required"})
+		return
+	}
+	if err != nil {
+		shelterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+// =======================
+// DELETE /shelters/:id
+// =======================
+func (sc *ShelterController) Delete(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid shelter id"})
+		return
+	}
+
+	if err := sc.ShelterUsecase.Delete(c, id); err != nil {
+		shelterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "shelter deleted"})
+}
+
+// =======================
+// GET /shelters/:id
+// =======================
+func (sc *ShelterController) Get(c *gin.Context) {
+	if s, ok := sc.loadShelter(c); ok {
+		c.JSON(http.StatusOK, s)
+	}
+}
+
+// =======================
+// GET /shelters?minLat&minLon&maxLat&maxLon
+// =======================
+func (sc *ShelterController) FetchInBounds(c *gin.Context) {
+	minLat, ok1 := getFloatQuery(c, "minLat")
+	minLon, ok2 := getFloatQuery(c, "minLon")
+	maxLat, ok3 := getFloatQuery(c, "maxLat")
+	maxLon, ok4 := getFloatQuery(c, "maxLon")
+	if !(ok1 && ok2 && ok3 && ok4) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid bounding box"})
+		return
+	}
+
+	shelters, err := sc.ShelterUsecase.FetchInBounds(c, domain.BoundsFilter{
+		MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shelters)
+}
+
+// =======================
+// GET /shelters/nearest?lat&lon&km=20&limit=5&includeFull=false&accessibility=wheelchair,pets&amenities=medical
+// =======================
+func (sc *ShelterController) Nearest(c *gin.Context) {
+	lat, ok1 := getFloatQuery(c, "lat")
+	lon, ok2 := getFloatQuery(c, "lon")
+	if !(ok1 && ok2) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid lat/lon"})
+		return
+	}
+
+	q := domain.ShelterQuery{
+		Lat:           lat,
+		Lon:           lon,
+		IncludeFull:   c.Query("includeFull") == "true",
+		Accessibility: splitList(c.Query("accessibility")),
+		Amenities:     splitList(c.Query("amenities")),
+	}
+	if km, ok := getFloatQuery(c, "km"); ok {
+		q.MaxKm = km
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "invalid limit"})
+			return
+		}
+		q.Limit = limit
+	}
+
+	shelters, err := sc.ShelterUsecase.Nearest(c, q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, shelters)
+}
+
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func (sc *ShelterController) loadShelter(c *gin.Context) (*domain.Shelter, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid shelter id"})
+		return nil, false
+	}
+
+	s, err := sc.ShelterUsecase.GetByID(c, id)
+	if err != nil {
+		shelterError(c, err)
+		return nil, false
+	}
+	return s, true
+}
+
+func (sc *ShelterController) saveShelter(c *gin.Context, s *domain.Shelter) {
+	s.UpdatedBy = c.GetString("x-user-id")
+	if err := sc.ShelterUsecase.Update(c, s); err != nil {
+		shelterError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, s)
+}
+
+func shelterError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrShelterNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+	case errors.Is(err, domain.ErrInvalidShelter):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
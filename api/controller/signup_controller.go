@@ -46,7 +46,7 @@ func (sc *SignupController) Signup(c *gin.Context) {
 		Name:     request.Name,
 		Phone:    request.Phone,
 		Password: request.Password,
-		Role:     "member",
+		Role:     domain.RoleMember,
 		GroupIDs: []primitive.ObjectID{},
 	}
 
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type TileController struct {
+	TileUsecase domain.TileUsecase
+}
+
+// GET /tiles/:layer/:z/:x/:y — y có thể kèm đuôi .mvt / .pbf / .png, mặc định là vector tile
+func (tc *TileController) Get(c *gin.Context) {
+	yParam, format := c.Param("y"), domain.TileFormatMVT
+	if i := strings.LastIndexByte(yParam, '.'); i >= 0 {
+		switch yParam[i+1:] {
+		case "mvt", "pbf":
+		case "png":
+			format = domain.TileFormatPNG
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"message": domain.ErrUnsupportedTileFormat.Error()})
+			return
+		}
+		yParam = yParam[:i]
+	}
+
+	z, errZ := strconv.Atoi(c.Param("z"))
+	x, errX := strconv.Atoi(c.Param("x"))
+	y, errY := strconv.Atoi(yParam)
+	if errZ != nil || errX != nil || errY != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": domain.ErrInvalidTile.Error()})
+		return
+	}
+
+	t, err := tc.TileUsecase.Tile(c, c.Param("layer"), format, z, x, y)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnknownTileLayer):
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		case errors.Is(err, domain.ErrInvalidTile), errors.Is(err, domain.ErrUnsupportedTileFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		}
+		return
+	}
+
+	c.Header("ETag", t.ETag)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(t.MaxAge.Seconds())))
+	if match := c.GetHeader("If-None-Match"); match != "" && match == t.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, t.ContentType, t.Data)
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type TrackController struct {
+	TrackUsecase domain.TrackUsecase
+}
+
+// GET /users/:id/track?from&to (unix ms, mặc định 7 ngày gần nhất)
+// chỉ chính user, người cùng nhóm và coordinator / admin được xem
+func (tc *TrackController) Track(c *gin.Context) {
+	from, to, ok := getTimeRange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid from/to"})
+		return
+	}
+
+	track, err := tc.TrackUsecase.Track(c, c.GetString("x-user-id"), c.Param("id"), from, to)
+	if errors.Is(err, domain.ErrTrackForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, track)
+}
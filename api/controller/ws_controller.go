@@ -4,6 +4,7 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 var upgrader = websocket.Upgrader{
@@ -61,7 +63,7 @@ func (c *WSController) handleFrames(client *ws.Client) {
 			client.UserID = frame.Headers["user-id"]
 			c.WSManager.PromoteTempClient(client)
 			resp := "CONNECTED\nversion:1.2\n\n\x00"
-			client.Conn.WriteMessage(websocket.TextMessage, []byte(resp))
+			client.Write(resp)
 
 		// chọn client muốn nhận thông báo
 		case "SUBSCRIBE":
@@ -99,6 +101,7 @@ func (c *WSController) handleFrames(client *ws.Client) {
 				switch body.Action {
 				case "raise":
 					alert := &domain.Alert{
+						ID:     primitive.NewObjectID(),
 						UserID: client.UserID,
 						Body:   body.Body,
 						Location: domain.GeoPoint{
@@ -113,7 +116,6 @@ func (c *WSController) handleFrames(client *ws.Client) {
 						PhoneNumber: body.PhoneNumber,
 					}
 					c.AlertUC.Handle(client, alert)
-					c.ZoneUC.AddRiskOrCreate(context.Background(), body.Lat, body.Lon, 0.2, body.RadiusM)
 
 				case "resolve":
 					if body.AlertID == "" {
@@ -185,6 +187,108 @@ func (c *WSController) handleFrames(client *ws.Client) {
 				}
 
 				c.ReportUC.Handle(client, report)
+
+			case "viewport":
+				// đăng ký vùng bản đồ đang xem để nhận "zone_event", clear = hủy
+				var body struct {
+					domain.Viewport
+					Clear bool `json:"clear"`
+				}
+				if err := json.Unmarshal([]byte(frame.Body), &body); err != nil {
+					break
+				}
+
+				if body.Clear {
+					c.WSManager.ClearViewport(client)
+					c.WSManager.SendToClient(client, "viewport_response", map[string]interface{}{"ok": true})
+					break
+				}
+
+				v := body.Viewport
+				if v.MinLat > v.MaxLat || v.MinLat < -90 || v.MaxLat > 90 ||
+					v.MinLon < -180 || v.MaxLon > 180 {
+					c.WSManager.SendToClient(client, "viewport_response", map[string]interface{}{
+						"ok":    false,
+						"error": "invalid viewport",
+					})
+					break
+				}
+				c.WSManager.SetViewport(client, v)
+
+				// trả kèm các zone hiện có trong viewport để client đồng bộ trước khi nhận event
+				zones, err := c.ZoneUC.FetchInBounds(context.Background(), v.MinLat, v.MinLon, v.MaxLat, v.MaxLon)
+				if err != nil {
+					c.WSManager.SendToClient(client, "viewport_response", map[string]interface{}{
+						"ok":    false,
+						"error": err.Error(),
+					})
+					break
+				}
+				c.WSManager.SendToClient(client, "viewport_response", map[string]interface{}{
+					"ok":    true,
+					"zones": zones,
+				})
+
+			case "batch":
+				// gửi bù report / location lưu khi offline, cùng body với POST /sync/batch
+				var body domain.BatchRequest
+				if err := json.Unmarshal([]byte(frame.Body), &body); err != nil {
+					break
+				}
+
+				resp, err := runBatch(context.Background(), c.ReportUC, c.LocationUC, client.UserID, body)
+				if err != nil {
+					c.WSManager.SendToClient(client, "batch_response", map[string]interface{}{
+						"ok":    false,
+						"error": err.Error(),
+					})
+					break
+				}
+				c.WSManager.SendToClient(client, "batch_response", map[string]interface{}{
+					"ok":        true,
+					"reports":   resp.Reports,
+					"locations": resp.Locations,
+				})
+
+			case "report_update":
+				var body struct {
+					Action   string `json:"action"`   // "edit" | "retract" | "clear"
+					ReportID string `json:"reportId"` // report cần đổi
+					domain.ReportEdit
+				}
+
+				if err := json.Unmarshal([]byte(frame.Body), &body); err != nil {
+					break
+				}
+
+				ctx := context.Background()
+				var (
+					report *domain.Report
+					err    error
+				)
+				switch body.Action {
+				case "edit":
+					report, err = c.ReportUC.Edit(ctx, client.UserID, body.ReportID, body.ReportEdit)
+				case "retract":
+					report, err = c.ReportUC.Retract(ctx, client.UserID, body.ReportID)
+				case "clear":
+					report, err = c.ReportUC.Clear(ctx, client.UserID, body.ReportID)
+				default:
+					err = errors.New("unknown action: " + body.Action)
+				}
+
+				if err != nil {
+					c.WSManager.SendToClient(client, "report_update_response", map[string]interface{}{
+						"ok":       false,
+						"reportId": body.ReportID,
+						"error":    err.Error(),
+					})
+					break
+				}
+				c.WSManager.SendToClient(client, "report_update_response", map[string]interface{}{
+					"ok":     true,
+					"report": report,
+				})
 			}
 		}
 	}
@@ -1,15 +1,22 @@
 package controller
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ZoneController struct {
-	ZoneUsecase domain.ZoneUsecase
+	ZoneUsecase        domain.ZoneUsecase
+	ZoneHistoryUsecase domain.ZoneHistoryUsecase
 }
 
 // =======================
@@ -21,6 +28,28 @@ type CreateZoneRequest struct {
 	Radius    float64 `json:"radius" binding:"required"`
 	RiskScore float64 `json:"riskScore"`
 	Label     string  `json:"label"`
+	Hazard    string  `json:"hazard"`
+	Declared  bool    `json:"declared"` // zone công bố chính thức, decay không xuống dưới floor
+}
+
+// PUT /zones/:id — thay toàn bộ thông tin zone
+type ReplaceZoneRequest struct {
+	CreateZoneRequest
+	Override bool `json:"override"` // giữ risk đặt tay, report không làm thay đổi
+	Locked   bool `json:"locked"`   // không decay, không bị gộp / xóa tự động
+}
+
+// PATCH /zones/:id — chỉ đổi các field được gửi
+type PatchZoneRequest struct {
+	Lat       *float64 `json:"lat"`
+	Lon       *float64 `json:"lon"`
+	Radius    *float64 `json:"radius"`
+	RiskScore *float64 `json:"riskScore"`
+	Label     *string  `json:"label"`
+	Hazard    *string  `json:"hazard"`
+	Declared  *bool    `json:"declared"`
+	Override  *bool    `json:"override"`
+	Locked    *bool    `json:"locked"`
 }
 
 // =======================
@@ -41,9 +70,15 @@ func (zc *ZoneController) Create(c *gin.Context) {
 		Radius:    req.Radius,
 		RiskScore: req.RiskScore,
 		Label:     req.Label,
+		Hazard:    strings.ToUpper(req.Hazard),
+		Declared:  req.Declared,
+	}
+	if msg := validateZone(zone); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": msg})
+		return
 	}
 
-	if err := zc.ZoneUsecase.Create(c, zone); err != nil {
+	if err := zc.ZoneUsecase.Create(manualCause(c), zone); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
@@ -51,6 +86,164 @@ func (zc *ZoneController) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, zone)
 }
 
+// =======================
+// PUT /zones/:id
+// =======================
+func (zc *ZoneController) Replace(c *gin.Context) {
+	zone, ok := zc.loadZone(c)
+	if !ok {
+		return
+	}
+
+	var req ReplaceZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	zone.Center = domain.GeoPoint{Type: "Point", Coordinates: [2]float64{req.Lon, req.Lat}}
+	zone.Radius = req.Radius
+	zone.RiskScore = req.RiskScore
+	zone.Label = req.Label
+	zone.Hazard = strings.ToUpper(req.Hazard)
+	zone.Declared = req.Declared
+	zone.Override = req.Override
+	zone.Locked = req.Locked
+
+	zc.saveZone(c, zone)
+}
+
+// =======================
+// PATCH /zones/:id
+// =======================
+func (zc *ZoneController) Patch(c *gin.Context) {
+	zone, ok := zc.loadZone(c)
+	if !ok {
+		return
+	}
+
+	var req PatchZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+
+	if req.Lat != nil {
+		zone.Center.Coordinates[1] = *req.Lat
+	}
+	if req.Lon != nil {
+		zone.Center.Coordinates[0] = *req.Lon
+	}
+	if req.Radius != nil {
+		zone.Radius = *req.Radius
+	}
+	if req.RiskScore != nil {
+		zone.RiskScore = *req.RiskScore
+		// label cũ không còn đúng với risk mới
+		zone.Label = ""
+	}
+	if req.Label != nil {
+		zone.Label = *req.Label
+	}
+	if req.Hazard != nil {
+		zone.Hazard = strings.ToUpper(*req.Hazard)
+	}
+	if req.Declared != nil {
+		zone.Declared = *req.Declared
+	}
+	if req.Override != nil {
+		zone.Override = *req.Override
+	}
+	if req.Locked != nil {
+		zone.Locked = *req.Locked
+	}
+
+	zc.saveZone(c, zone)
+}
+
+// =======================
+// DELETE /zones/:id
+// =======================
+func (zc *ZoneController) Delete(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid zone id"})
+		return
+	}
+
+	if err := zc.ZoneUsecase.Delete(manualCause(c), id); err != nil {
+		if errors.Is(err, domain.ErrZoneNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "zone deleted"})
+}
+
+func (zc *ZoneController) loadZone(c *gin.Context) (*domain.Zone, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid zone id"})
+		return nil, false
+	}
+
+	zone, err := zc.ZoneUsecase.GetByID(c, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrZoneNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return nil, false
+	}
+	return zone, true
+}
+
+// saveZone kiểm tra rồi ghi zone đã sửa; sửa tay là mốc mới cho decay
+func (zc *ZoneController) saveZone(c *gin.Context, zone *domain.Zone) {
+	if msg := validateZone(zone); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"message": msg})
+		return
+	}
+	if zone.Label == "" {
+		zone.Label = domain.RiskLabel(zone.RiskScore)
+	}
+	zone.UpdatedAt = time.Now().UnixMilli()
+
+	if err := zc.ZoneUsecase.Update(manualCause(c), zone); err != nil {
+		if errors.Is(err, domain.ErrZoneNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, zone)
+}
+
+// validateZone trả về lỗi cho client, "" = hợp lệ
+func validateZone(zone *domain.Zone) string {
+	lat, lon := zone.Center.Coordinates[1], zone.Center.Coordinates[0]
+	switch {
+	case lat < -90 || lat > 90 || lon < -180 || lon > 180:
+		return "lat must be in [-90, 90] and lon in [-180, 180]"
+	case zone.Radius <= 0:
+		return "radius must be > 0"
+	case zone.RiskScore < 0 || zone.RiskScore > 1:
+		return "riskScore must be in [0, 1]"
+	}
+	return ""
+}
+
+// manualCause gắn người sửa vào history của zone
+func manualCause(c *gin.Context) context.Context {
+	return domain.WithZoneCause(c, domain.ZoneCause{Type: domain.ZoneCauseManual, RefID: c.GetString("x-user-id")})
+}
+
 // =======================
 // GET /zones?minLat&minLon&maxLat&maxLon
 // Lấy zone trong bounding box
@@ -114,6 +307,97 @@ func (zc *ZoneController) FetchAll(c *gin.Context) {
 	c.JSON(http.StatusOK, zones)
 }
 
+// =======================
+// GET /zones/:id/history?from&to (unix ms, mặc định 7 ngày gần nhất)
+// =======================
+func (zc *ZoneController) History(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid zone id"})
+		return
+	}
+
+	from, to, ok := getTimeRange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid from/to"})
+		return
+	}
+
+	points, err := zc.ZoneHistoryUsecase.History(c, id, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, points)
+}
+
+// =======================
+// GET /zones/history?minLat&minLon&maxLat&maxLon&from&to&bucket=1h
+// Tổng hợp risk trong khu vực theo từng khoảng thời gian
+// =======================
+func (zc *ZoneController) AreaHistory(c *gin.Context) {
+	minLat, ok1 := getFloatQuery(c, "minLat")
+	minLon, ok2 := getFloatQuery(c, "minLon")
+	maxLat, ok3 := getFloatQuery(c, "maxLat")
+	maxLon, ok4 := getFloatQuery(c, "maxLon")
+	if !(ok1 && ok2 && ok3 && ok4) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid bounding box"})
+		return
+	}
+
+	from, to, ok := getTimeRange(c)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid from/to"})
+		return
+	}
+
+	bucket, err := time.ParseDuration(c.DefaultQuery("bucket", "1h"))
+	if err != nil || bucket < time.Minute {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "invalid bucket"})
+		return
+	}
+	if to.Sub(from)/bucket > maxHistoryBuckets {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "too many buckets, use a larger bucket"})
+		return
+	}
+
+	buckets, err := zc.ZoneHistoryUsecase.Aggregate(c, domain.BoundsFilter{
+		MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon,
+	}, from, to, bucket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, buckets)
+}
+
+const maxHistoryBuckets = 2000
+
+// getTimeRange đọc from/to (unix ms), mặc định 7 ngày gần nhất
+func getTimeRange(c *gin.Context) (time.Time, time.Time, bool) {
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		to = time.UnixMilli(ms)
+	}
+
+	from := to.Add(-7 * 24 * time.Hour)
+	if v := c.Query("from"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		from = time.UnixMilli(ms)
+	}
+
+	return from, to, !from.After(to)
+}
+
 // =======================
 // Helper — get float query
 // =======================
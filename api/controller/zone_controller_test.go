@@ -0,0 +1,133 @@
+package controller_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// zoneStore giữ zone trong bộ nhớ, ghi lại cause của mỗi lần sửa
+type zoneStore struct {
+	domain.ZoneUsecase
+	zones  map[primitive.ObjectID]domain.Zone
+	causes []domain.ZoneCause
+}
+
+func (f *zoneStore) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Zone, error) {
+	z, ok := f.zones[id]
+	if !ok {
+		return nil, domain.ErrZoneNotFound
+	}
+	return &z, nil
+}
+
+func (f *zoneStore) Update(ctx context.Context, z *domain.Zone) error {
+	f.zones[z.ID] = *z
+	f.causes = append(f.causes, domain.ZoneCauseFrom(ctx))
+	return nil
+}
+
+func (f *zoneStore) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, ok := f.zones[id]; !ok {
+		return domain.ErrZoneNotFound
+	}
+	delete(f.zones, id)
+	f.causes = append(f.causes, domain.ZoneCauseFrom(ctx))
+	return nil
+}
+
+func newZoneRouter(store *zoneStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	zc := &controller.ZoneController{ZoneUsecase: store}
+	r := gin.New()
+	r.Use(setUserID("coord-1"))
+	r.PUT("/zones/:id", zc.Replace)
+	r.PATCH("/zones/:id", zc.Patch)
+	r.DELETE("/zones/:id", zc.Delete)
+	return r
+}
+
+func TestZoneManage(t *testing.T) {
+	id := primitive.NewObjectID()
+	newStore := func() *zoneStore {
+		return &zoneStore{zones: map[primitive.ObjectID]domain.Zone{id: {
+			ID:        id,
+			Center:    domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106.7, 10.77}},
+			Radius:    500,
+			RiskScore: 0.4,
+			Label:     "MEDIUM",
+		}}}
+	}
+	send := func(r *gin.Engine, method, path, body string) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	path := "/zones/" + id.Hex()
+
+	t.Run("replace", func(t *testing.T) {
+		store := newStore()
+		code := send(newZoneRouter(store), http.MethodPut, path,
+			`{"lat":10.8,"lon":106.6,"radius":800,"riskScore":0.9,"hazard":"flood","override":true}`)
+		require.Equal(t, http.StatusOK, code)
+
+		z := store.zones[id]
+		assert.Equal(t, [2]float64{106.6, 10.8}, z.Center.Coordinates)
+		assert.Equal(t, "HIGH", z.Label)
+		assert.Equal(t, "FLOOD", z.Hazard)
+		assert.True(t, z.Override)
+		assert.Equal(t, []domain.ZoneCause{{Type: domain.ZoneCauseManual, RefID: "coord-1"}}, store.causes)
+	})
+
+	t.Run("patch keeps other fields", func(t *testing.T) {
+		store := newStore()
+		require.Equal(t, http.StatusOK, send(newZoneRouter(store), http.MethodPatch, path, `{"riskScore":0.1,"locked":true}`))
+
+		z := store.zones[id]
+		assert.Equal(t, 500.0, z.Radius)
+		assert.Equal(t, "LOW", z.Label) // label tính lại theo risk mới
+		assert.True(t, z.Locked)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		store := newStore()
+		r := newZoneRouter(store)
+		for _, tc := range []struct{ method, body string }{
+			{http.MethodPut, `{"lat":91,"lon":106.6,"radius":800}`},
+			{http.MethodPut, `{"lat":10.8,"lon":106.6,"radius":800,"riskScore":1.5}`},
+			{http.MethodPatch, `{"lon":-181}`},
+			{http.MethodPatch, `{"radius":-10}`},
+			{http.MethodPatch, `{"riskScore":-0.1}`},
+		} {
+			assert.Equal(t, http.StatusBadRequest, send(r, tc.method, path, tc.body), tc.body)
+		}
+		assert.Empty(t, store.causes)
+		assert.Equal(t, 0.4, store.zones[id].RiskScore)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store := newStore()
+		r := newZoneRouter(store)
+		assert.Equal(t, http.StatusOK, send(r, http.MethodDelete, path, ""))
+		assert.Empty(t, store.zones)
+		assert.Equal(t, http.StatusNotFound, send(r, http.MethodDelete, path, ""))
+		assert.Equal(t, http.StatusBadRequest, send(r, http.MethodDelete, "/zones/not-an-id", ""))
+	})
+
+	t.Run("unknown zone", func(t *testing.T) {
+		r := newZoneRouter(newStore())
+		other := "/zones/" + primitive.NewObjectID().Hex()
+		assert.Equal(t, http.StatusNotFound, send(r, http.MethodPatch, other, `{"radius":100}`))
+	})
+}
@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// số key tối đa trước khi dọn các cửa sổ đã hết hạn
+const rateLimitSweepAt = 10000
+
+type rateWindow struct {
+	count int
+	reset time.Time
+}
+
+// RateLimitUserAndIP đếm riêng theo user và theo IP, vượt một trong hai là 429
+// (một người dùng nhiều tài khoản từ cùng IP vẫn bị chặn). Đếm trong bộ nhớ nên mỗi instance đếm riêng.
+func RateLimitUserAndIP(limit int, window time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	windows := map[string]*rateWindow{}
+
+	return func(c *gin.Context) {
+		keys := []string{"ip:" + c.ClientIP()}
+		if id := c.GetString("x-user-id"); id != "" {
+			keys = append(keys, id)
+		}
+		now := time.Now()
+
+		mu.Lock()
+		if len(windows) >= rateLimitSweepAt {
+			for k, w := range windows {
+				if !now.Before(w.reset) {
+					delete(windows, k)
+				}
+			}
+		}
+		allowed, retry := true, time.Duration(0)
+		for _, key := range keys {
+			w, ok := windows[key]
+			if !ok || !now.Before(w.reset) {
+				w = &rateWindow{reset: now.Add(window)}
+				windows[key] = w
+			}
+			w.count++
+			if w.count > limit {
+				allowed, retry = false, max(retry, w.reset.Sub(now))
+			}
+		}
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(retry.Seconds())+1))
+			c.JSON(http.StatusTooManyRequests, domain.ErrorResponse{Message: "Too many attempts, try again later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,42 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitUserAndIP(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/join", func(c *gin.Context) {
+		c.Set("x-user-id", c.GetHeader("X-Test-User"))
+		c.Next()
+	}, middleware.RateLimitUserAndIP(2, time.Minute), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	join := func(user, ip string) int {
+		req := httptest.NewRequest(http.MethodPut, "/join", nil)
+		req.Header.Set("X-Test-User", user)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// đổi tài khoản không qua được hạn mức của IP
+	assert.Equal(t, http.StatusOK, join("u1", "10.0.0.1"))
+	assert.Equal(t, http.StatusOK, join("u2", "10.0.0.1"))
+	assert.Equal(t, http.StatusTooManyRequests, join("u3", "10.0.0.1"))
+
+	// đổi IP không qua được hạn mức của user
+	assert.Equal(t, http.StatusOK, join("u4", "10.0.0.2"))
+	assert.Equal(t, http.StatusOK, join("u4", "10.0.0.3"))
+	assert.Equal(t, http.StatusTooManyRequests, join("u4", "10.0.0.4"))
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole chỉ cho user có một trong các role đi tiếp, chạy sau JwtAuthMiddleware.
+// Role đọc lại từ DB mỗi request để thu hồi quyền có hiệu lực ngay.
+func RequireRole(userRepo domain.UserRepository, roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, r := range roles {
+		allowed[r] = true
+	}
+
+	return func(c *gin.Context) {
+		userID := c.GetString("x-user-id")
+		if userID == "" {
+			c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "Not authorized"})
+			c.Abort()
+			return
+		}
+
+		user, err := userRepo.GetByID(c, userID)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, domain.ErrorResponse{Message: "User not found"})
+			c.Abort()
+			return
+		}
+
+		if !allowed[user.Role] {
+			c.JSON(http.StatusForbidden, domain.ErrorResponse{Message: "Permission denied"})
+			c.Abort()
+			return
+		}
+
+		c.Set("x-user-role", user.Role)
+		c.Next()
+	}
+}
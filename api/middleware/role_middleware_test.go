@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/middleware"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// roleUserRepo trả về lần lượt các role trong roles, role cuối giữ cho các lần sau
+type roleUserRepo struct {
+	domain.UserRepository
+	roles []string
+	calls int
+}
+
+func (f *roleUserRepo) GetByID(ctx context.Context, id string) (domain.User, error) {
+	if len(f.roles) == 0 {
+		return domain.User{}, errors.New("not found")
+	}
+	role := f.roles[min(f.calls, len(f.roles)-1)]
+	f.calls++
+	return domain.User{Role: role}, nil
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(ur domain.UserRepository) *gin.Engine {
+		r := gin.New()
+		r.POST("/zones", func(c *gin.Context) {
+			c.Set("x-user-id", c.GetHeader("X-Test-User"))
+			// role cũ trong context (vd từ claim) không được tin
+			c.Set("x-user-role", domain.RoleCoordinator)
+			c.Next()
+		}, middleware.RequireRole(ur, domain.RoleCoordinator, domain.RoleAdmin), func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString("x-user-role"))
+		})
+		return r
+	}
+	call := func(r *gin.Engine, user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/zones", nil)
+		req.Header.Set("X-Test-User", user)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("coordinator allowed", func(t *testing.T) {
+		rec := call(newRouter(&roleUserRepo{roles: []string{domain.RoleCoordinator}}), "u1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, domain.RoleCoordinator, rec.Body.String())
+	})
+
+	t.Run("plain user forbidden", func(t *testing.T) {
+		ur := &roleUserRepo{roles: []string{domain.RoleMember}}
+		assert.Equal(t, http.StatusForbidden, call(newRouter(ur), "u1").Code)
+	})
+
+	t.Run("role read from DB each request", func(t *testing.T) {
+		ur := &roleUserRepo{roles: []string{domain.RoleCoordinator, domain.RoleMember}}
+		r := newRouter(ur)
+
+		assert.Equal(t, http.StatusOK, call(r, "u1").Code)
+		// bị thu hồi quyền: có hiệu lực ngay request sau
+		assert.Equal(t, http.StatusForbidden, call(r, "u1").Code)
+		assert.Equal(t, 2, ur.calls)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		ur := &roleUserRepo{}
+		assert.Equal(t, http.StatusUnauthorized, call(newRouter(ur), "u1").Code)
+		assert.Equal(t, http.StatusUnauthorized, call(newRouter(ur), "").Code)
+	})
+}
@@ -0,0 +1,35 @@
+package route
+
+import (
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Gửi bù report / location lưu khi offline
+func NewBatchRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, wsManager *ws.WSManager, zoneNotifier domain.ZoneNotifier, geofence *usecase.Geofence, group *gin.RouterGroup) {
+	reportRepo := repository.NewReportRepo(db, domain.CollectionReport)
+	locRepo := repository.NewLocationRepo(db, domain.CollectionLocation)
+	zoneRepo := repository.NewZoneRepository(db, domain.CollectionZone)
+	zoneHistoryRepo := repository.NewZoneHistoryRepo(db, domain.CollectionZoneHistory)
+	jobRepo := repository.NewEnrichmentJobRepo(db, domain.CollectionEnrichmentJob)
+
+	zoneUC := usecase.NewZoneUsecase(zoneRepo, zoneHistoryRepo, zoneNotifier, zoneMergeConfig(env), timeout)
+	// không cần queue: batch ghi trực tiếp, AI chạy qua EnrichmentWorker
+	reportUC := usecase.NewReportUC(nil, nil, wsManager, reportRepo, jobRepo, zoneUC, enrichmentRetryConfig(env), timeout)
+	locUC := usecase.NewLocationUC(nil, wsManager, locRepo, geofence, trackRecorder(env, db), nil, timeout)
+
+	bc := &controller.BatchController{
+		ReportUC:   reportUC,
+		LocationUC: locUC,
+	}
+
+	group.POST("/sync/batch", bc.Submit)
+}
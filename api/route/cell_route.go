@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/middleware"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
@@ -13,10 +14,11 @@ import (
 )
 
 // NewCellRouter sets up routes for Cell operations
-func NewCellRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, group *gin.RouterGroup) {
+func NewCellRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, tiles domain.TileInvalidator, group *gin.RouterGroup) {
 	// Repository + Usecase
 	cr := repository.NewCellRepository(db, domain.CollectionCell)
-	cu := usecase.NewCellUsecase(cr, timeout)
+	zr := repository.NewZoneRepository(db, domain.CollectionZone)
+	cu := usecase.NewCellUsecase(cr, zr, tiles, timeout)
 
 	// Controller
 	cc := &controller.CellController{
@@ -24,8 +26,13 @@ func NewCellRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database,
 	}
 
 	// --- Routes ---
-	group.GET("/cell", cc.GetCellByLatLon)              // fetch 1 cell tại vị trí
-	group.GET("/cells", cc.GetCellsByRadius)            // fetch nhiều cell theo radius
-	group.POST("/cell", cc.UpdateCell)                  // gửi & chỉnh sửa 1 cell
-	group.POST("/cells/radius", cc.UpdateCellsByRadius) // gửi & chỉnh sửa theo radius
+	group.GET("/cell", cc.GetCellByLatLon)   // fetch 1 cell tại vị trí
+	group.GET("/cells", cc.GetCellsByRadius) // fetch nhiều cell theo radius
+	group.GET("/cells/grid", cc.FetchGrid)   // lưới risk nhiều level trong bounding box
+
+	// ghi risk: chỉ coordinator và admin (group đã qua JwtAuthMiddleware)
+	ur := repository.NewUserRepository(db, domain.CollectionUser)
+	manage := group.Group("", middleware.RequireRole(ur, domain.RoleCoordinator, domain.RoleAdmin))
+	manage.POST("/cell", cc.UpdateCell)                  // gửi & chỉnh sửa 1 cell
+	manage.POST("/cells/radius", cc.UpdateCellsByRadius) // gửi & chỉnh sửa theo radius
 }
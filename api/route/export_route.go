@@ -0,0 +1,24 @@
+package route
+
+import (
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Export GeoJSON / KML / CSV cho đối tác (QGIS, ArcGIS, Excel)
+func NewExportRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, group *gin.RouterGroup) {
+	er := repository.NewExportRepo(db)
+
+	// export lớn có thể lâu hơn nhiều so với CONTEXT_TIMEOUT
+	ec := &controller.ExportController{
+		ExportUsecase: usecase.NewExportUsecase(er, time.Duration(env.ExportTimeoutSec)*time.Second),
+	}
+
+	group.GET("/export/:layer", ec.Export)
+}
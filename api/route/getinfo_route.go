@@ -21,14 +21,16 @@ func NewNearbyRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Databas
 	reportRepo := repository.NewReportRepo(db, domain.CollectionReport)
 	locRepo := repository.NewLocationRepo(db, domain.CollectionLocation)
 	zoneRepo := repository.NewZoneRepository(db, domain.CollectionZone)
+	zoneHistoryRepo := repository.NewZoneHistoryRepo(db, domain.CollectionZoneHistory)
+	jobRepo := repository.NewEnrichmentJobRepo(db, domain.CollectionEnrichmentJob)
 
 	// -----------------------
 	// 2️⃣ UseCases
 	// -----------------------
-	locUC := usecase.NewLocationUC(nil, nil, locRepo, timeout)
-	alertUC := usecase.NewAlertUC(nil, nil, alertRepo, locUC, timeout)
-	zoneUC := usecase.NewZoneUsecase(zoneRepo, timeout)
-	reportUC := usecase.NewReportUC(nil, nil, nil, reportRepo, zoneUC, timeout)
+	locUC := usecase.NewLocationUC(nil, nil, locRepo, nil, nil, nil, timeout)
+	alertUC := usecase.NewAlertUC(nil, nil, alertRepo, locUC, nil, timeout)
+	zoneUC := usecase.NewZoneUsecase(zoneRepo, zoneHistoryRepo, nil, domain.ZoneMergeConfig{}, timeout)
+	reportUC := usecase.NewReportUC(nil, nil, nil, reportRepo, jobRepo, zoneUC, enrichmentRetryConfig(env), timeout)
 
 	// -----------------------
 	// 3️⃣ WS Manager (optional, nếu cần broadcast realtime)
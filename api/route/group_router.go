@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/middleware"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
@@ -12,36 +13,58 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewGroupRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, group *gin.RouterGroup) {
+func NewGroupRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, sharing domain.SharingUsecase, group *gin.RouterGroup) {
 	gr := repository.NewGroupRepository(db, domain.CollectionGroup) // tạo user repository để làm việc với collection user
 	mr := repository.NewMemberRepository(db, domain.CollectionMember)
 	ur := repository.NewUserRepository(db, domain.CollectionUser)
 	lr := repository.NewLocationRepo(db, domain.CollectionLocation)
 
+	invite := usecase.InviteConfig{
+		TTL:      time.Duration(env.InviteTTLHours) * time.Hour,
+		LinkBase: env.InviteLinkBase,
+	}
 	gc := &controller.GroupController{ // tạo controller
-		GroupUsecase: usecase.NewGroupUsecase(gr, mr, ur, lr, timeout),
+		GroupUsecase: usecase.NewGroupUsecase(gr, mr, ur, lr, sharing, invite, timeout),
 	}
+	// chặn dò mã mời: join và tra mã dùng chung một hạn mức, theo cả user và IP
+	inviteLimit := middleware.RateLimitUserAndIP(env.InviteJoinLimit, time.Duration(env.InviteJoinWindowMin)*time.Minute)
 
 	groupRoutes := group.Group("/groups")
 	{
 		// tạo group mới
 		groupRoutes.POST("/create", gc.CreateGroup)
 
-		// get invite code từ group id
-		groupRoutes.GET("/:groupId/invite", gc.GetInviteCodeByGroupID)
+		// mã invite hiện tại và deep link / QR, chỉ thành viên
+		groupRoutes.GET("/:groupId/invite", gc.GetInvite)
+		groupRoutes.GET("/:groupId/invite/link", gc.GetInviteLink)
+
+		// owner / admin đổi mã (kèm hạn, số lượt) hoặc thu hồi mã
+		groupRoutes.POST("/:groupId/invite/rotate", gc.RotateInvite)
+		groupRoutes.DELETE("/:groupId/invite", gc.RevokeInvite)
 
 		// lấy thông tin group bằng invite code
-		groupRoutes.GET("/invite/:code", gc.GetByInviteCode)
+		groupRoutes.GET("/invite/:code", inviteLimit, gc.GetByInviteCode)
 
 		// join group + add thông tin group vào user
 		// tham gia group bằng code
-		groupRoutes.PUT("/join/:code", gc.JoinGroup)
+		groupRoutes.PUT("/join/:code", inviteLimit, gc.JoinGroup)
+
+		// xóa group (chỉ owner), xóa luôn member và groupID trong user
+		groupRoutes.DELETE("/:groupId", gc.Delete)
 
-		// xóa group chưa xóa người (index groupid & userid trong member) -> nên chuyển thành xóa member khỏi group
-		groupRoutes.DELETE("/:groupId/members", gc.Delete)
+		// rời group
+		groupRoutes.DELETE("/:groupId/members", gc.Leave)
+
+		// owner / admin kick thành viên, owner nâng / hạ admin và chuyển owner
+		groupRoutes.DELETE("/:groupId/members/:memberId", gc.RemoveMember)
+		groupRoutes.PUT("/:groupId/members/:memberId/role", gc.SetMemberRole)
+		groupRoutes.POST("/:groupId/transfer", gc.TransferOwnership)
 
 		groupRoutes.GET("/:groupId", gc.GetGroupByID)
 
+		// bản đồ nhóm: mọi thành viên kèm vị trí, trạng thái, khoảng cách
+		groupRoutes.GET("/:groupId/members", gc.ListMembers)
+
 		groupRoutes.GET("/:groupId/members/:memberId", gc.GetMemberInGroup)
 	}
 }
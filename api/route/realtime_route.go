@@ -0,0 +1,43 @@
+package route
+
+import (
+	"log"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ai"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/handlers"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Presence / SOS / phân loại report qua REST cho client không dùng WS (SMS bridge, app đối tác).
+// Presence và SOS lưu như qua WS (location / alert, trail, geofence, risk zone) rồi broadcast qua WSManager.
+func NewRealtimeRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, wsManager *ws.WSManager, zoneNotifier domain.ZoneNotifier, geofence *usecase.Geofence, group *gin.RouterGroup) {
+	aiClient, err := ai.New()
+	if err != nil {
+		log.Fatal("Failed to create AI client:", err)
+	}
+
+	locRepo := repository.NewLocationRepo(db, domain.CollectionLocation)
+	alertRepo := repository.NewAlertRepo(db, domain.CollectionAlert)
+	zoneRepo := repository.NewZoneRepository(db, domain.CollectionZone)
+	zoneHistoryRepo := repository.NewZoneHistoryRepo(db, domain.CollectionZoneHistory)
+
+	zoneUC := usecase.NewZoneUsecase(zoneRepo, zoneHistoryRepo, zoneNotifier, zoneMergeConfig(env), timeout)
+	// REST lưu đồng bộ qua Save / Raise, không dùng worker queue
+	locUC := usecase.NewLocationUC(nil, wsManager, locRepo, geofence, trackRecorder(env, db), nil, timeout)
+	alertUC := usecase.NewAlertUC(nil, wsManager, alertRepo, locUC, zoneUC, timeout)
+
+	ph := &handlers.PresenceHandler{AI: aiClient, Locations: locUC, Broadcaster: wsManager}
+	sh := &handlers.SOSHandler{AI: aiClient, Alerts: alertUC, Broadcaster: wsManager}
+	rh := &handlers.ReportHandler{AI: aiClient}
+
+	group.POST("/presence", ph.Update)
+	group.POST("/sos", sh.Raise)
+	group.POST("/reports/classify", rh.Create)
+}
@@ -5,11 +5,41 @@ import (
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
 	"github.com/gin-gonic/gin"
 )
 
+// Sửa / rút lại / đóng report của chính mình
+func NewReportRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, wsManager *ws.WSManager, zoneNotifier domain.ZoneNotifier, group *gin.RouterGroup) {
+	reportRepo := repository.NewReportRepo(db, domain.CollectionReport)
+	zoneRepo := repository.NewZoneRepository(db, domain.CollectionZone)
+	zoneHistoryRepo := repository.NewZoneHistoryRepo(db, domain.CollectionZoneHistory)
+	jobRepo := repository.NewEnrichmentJobRepo(db, domain.CollectionEnrichmentJob)
+
+	zoneUC := usecase.NewZoneUsecase(zoneRepo, zoneHistoryRepo, zoneNotifier, zoneMergeConfig(env), timeout)
+	// không cần queue: AI chạy lại qua job, EnrichmentWorker của WS router sẽ xử lý
+	reportUC := usecase.NewReportUC(nil, nil, wsManager, reportRepo, jobRepo, zoneUC, enrichmentRetryConfig(env), timeout)
+
+	rc := &controller.ReportController{
+		ReportRepo: reportRepo,
+		ReportUC:   reportUC,
+		Timeout:    timeout,
+	}
+
+	reportRoutes := group.Group("/reports")
+	{
+		reportRoutes.GET("/:id", rc.Get)
+		reportRoutes.GET("/:id/revisions", rc.Revisions)
+		reportRoutes.PATCH("/:id", rc.Edit)
+		reportRoutes.POST("/:id/retract", rc.Retract)
+		reportRoutes.POST("/:id/clear", rc.Clear)
+	}
+}
+
 // Chỉ dùng để mock dữ liệu report
 func NewReportMockRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, group *gin.RouterGroup) {
 	// -----------------------
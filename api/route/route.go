@@ -6,12 +6,66 @@ import (
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/middleware"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/tile"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/worker"
 	"github.com/gin-gonic/gin"
 )
 
 func Setup(env *bootstrap.Env, timeout time.Duration, db mongo.Database, gin *gin.Engine) {
 
+	// WS manager dùng chung cho WS route và các REST route cần broadcast realtime
+	wsManager := ws.NewWSManager()
+	// cache tile bản đồ, bị xóa theo vùng khi zone / cell thay đổi
+	tileCache := tile.NewCache(env.TileCacheEntries)
+	zoneNotifier := domain.ZoneNotifiers{wsManager, tileCache}
+
+	// ai được xem vị trí của ai, chính xác tới đâu; WS chỉ gửi location theo chính sách này
+	sharing := usecase.NewSharingUsecase(
+		repository.NewSharingRepo(db, domain.CollectionSharingSettings),
+		repository.NewUserRepository(db, domain.CollectionUser),
+		repository.NewGroupRepository(db, domain.CollectionGroup),
+		repository.NewAlertRepo(db, domain.CollectionAlert),
+		timeout,
+	)
+	wsManager.SetLocationSharing(sharing)
+
+	// user mất tín hiệu quá lâu -> UNKNOWN, báo nhóm qua "member_stale"
+	staleDetector := usecase.NewStaleDetector(
+		repository.NewLocationRepo(db, domain.CollectionLocation),
+		repository.NewZoneRepository(db, domain.CollectionZone),
+		repository.NewUserRepository(db, domain.CollectionUser),
+		repository.NewGroupRepository(db, domain.CollectionGroup),
+		wsManager,
+		usecase.StalePolicy{
+			Default: time.Duration(env.StaleLocationDefaultMin) * time.Minute,
+			Safe:    time.Duration(env.StaleLocationSafeMin) * time.Minute,
+			Urgent:  time.Duration(env.StaleLocationUrgentMin) * time.Minute,
+		},
+		timeout,
+	)
+	worker.NewStaleLocationWorker(staleDetector, time.Duration(env.StaleLocationIntervalMin)*time.Minute).Start()
+
+	// trạng thái vào / ra vùng nguy hiểm dùng chung cho location qua WS và qua batch
+	geofence := usecase.NewGeofence(
+		repository.NewZoneRepository(db, domain.CollectionZone),
+		repository.NewAlertRepo(db, domain.CollectionAlert),
+		repository.NewUserRepository(db, domain.CollectionUser),
+		sharing,
+		wsManager,
+		usecase.GeofenceConfig{
+			ExitMarginM:  env.GeofenceExitMarginM,
+			Cooldown:     time.Duration(env.GeofenceCooldownMin) * time.Minute,
+			NotifyGroups: env.GeofenceNotifyGroups,
+		},
+		timeout,
+	)
+	geofence.Start()
+
 	publicRouter := gin.Group("")
 	// All Public APIs
 	NewSignupRouter(env, timeout, db, publicRouter)
@@ -27,12 +81,36 @@ func Setup(env *bootstrap.Env, timeout time.Duration, db mongo.Database, gin *gi
 	NewTaskRouter(env, timeout, db, protectedRouter)
 
 	// về group
-	NewGroupRouter(env, timeout, db, protectedRouter)
+	NewGroupRouter(env, timeout, db, sharing, protectedRouter)
+
+	// cài đặt chia sẻ vị trí
+	NewSharingRouter(env, timeout, db, sharing, protectedRouter)
+
+	// sửa / rút lại / đóng report
+	NewReportRouter(env, timeout, db, wsManager, zoneNotifier, protectedRouter)
+
+	// presence / SOS qua REST, broadcast qua WS
+	NewRealtimeRouter(env, timeout, db, wsManager, zoneNotifier, geofence, protectedRouter)
+
+	// gửi bù dữ liệu offline
+	NewBatchRouter(env, timeout, db, wsManager, zoneNotifier, geofence, protectedRouter)
+
+	// đánh giá risk đường đi, gợi ý đường tránh
+	NewRouteAssessRouter(env, timeout, db, protectedRouter)
+
+	// lưới risk theo cell: đọc cho user đã đăng nhập, ghi cho coordinator / admin
+	NewCellRouter(env, timeout, db, tileCache, protectedRouter)
+
+	// trail vị trí cho người cùng nhóm / đội cứu hộ
+	NewTrackRouter(env, timeout, db, sharing, protectedRouter)
+
+	// export GeoJSON / KML / CSV
+	NewExportRouter(env, timeout, db, protectedRouter)
 
 	// --- Thêm WebSocket route vào cùng hàm Setup ---
 	// NewWSRouter(env, timeout, db, protectedRouter)
 
-	NewWSRouter(env, timeout, db, publicRouter)
+	NewWSRouter(env, timeout, db, wsManager, zoneNotifier, geofence, publicRouter)
 
 	// --- Thêm các route lấy thông tin gần đó ---
 	NewNearbyRouter(env, timeout, db, publicRouter)
@@ -41,7 +119,13 @@ func Setup(env *bootstrap.Env, timeout time.Duration, db mongo.Database, gin *gi
 	// NewReportMockRouter(env, timeout, db, publicRouter)
 	// NewAlertMockRouter(env, timeout, db, publicRouter)
 
-	NewZoneRouter(env, timeout, db, publicRouter)
+	NewZoneRouter(env, timeout, db, zoneNotifier, publicRouter)
+
+	// điểm sơ tán, số chỗ trống đẩy qua WS "shelter_event"
+	NewShelterRouter(env, timeout, db, wsManager, publicRouter)
+
+	// tile bản đồ: lưới risk, zone, cụm report, alert
+	NewTileRouter(env, timeout, db, tileCache, publicRouter)
 
 	// route POST /ai/analyze
 	aiCtrl := controller.NewAIController(env)
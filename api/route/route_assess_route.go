@@ -0,0 +1,41 @@
+package route
+
+import (
+	"log"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/roadgraph"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// NewRouteAssessRouter: đánh giá risk đường đi, đồ thị đường OSM nạp một lần lúc khởi động
+func NewRouteAssessRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, group *gin.RouterGroup) {
+	var graph *roadgraph.Graph
+	if env.RoadGraphFile != "" {
+		g, err := roadgraph.Load(env.RoadGraphFile)
+		if err != nil {
+			log.Fatal("Can't load road graph: ", err)
+		}
+		log.Printf("Loaded road graph with %d nodes", g.Len())
+		graph = g
+	}
+
+	rc := &controller.RouteController{
+		RouteUsecase: usecase.NewRouteUsecase(
+			repository.NewZoneRepository(db, domain.CollectionZone),
+			repository.NewCellRepository(db, domain.CollectionCell),
+			repository.NewReportRepo(db, domain.CollectionReport),
+			repository.NewAlertRepo(db, domain.CollectionAlert),
+			graph,
+			timeout,
+		),
+	}
+
+	group.POST("/routes/assess", rc.Assess)
+}
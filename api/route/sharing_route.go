@@ -0,0 +1,23 @@
+package route
+
+import (
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/gin-gonic/gin"
+)
+
+// Cài đặt chia sẻ vị trí của user đang đăng nhập
+func NewSharingRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, sharing domain.SharingUsecase, group *gin.RouterGroup) {
+	sc := &controller.SharingController{
+		SharingUsecase: sharing,
+	}
+
+	group.GET("/sharing", sc.Get)
+	group.PUT("/sharing", sc.Update)
+	group.POST("/sharing/pause", sc.Pause)
+	group.DELETE("/sharing/pause", sc.Resume)
+}
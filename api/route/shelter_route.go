@@ -0,0 +1,41 @@
+package route
+
+import (
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/middleware"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func NewShelterRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, notifier domain.ShelterNotifier, group *gin.RouterGroup) {
+	sc := &controller.ShelterController{
+		ShelterUsecase: usecase.NewShelterUsecase(
+			repository.NewShelterRepository(db, domain.CollectionShelter),
+			repository.NewZoneRepository(db, domain.CollectionZone),
+			notifier,
+			timeout,
+		),
+	}
+
+	group.GET("/shelters", sc.FetchInBounds)
+	group.GET("/shelters/nearest", sc.Nearest)
+	group.GET("/shelters/:id", sc.Get)
+
+	// thêm / sửa / xóa shelter và cập nhật số người: chỉ coordinator và admin
+	ur := repository.NewUserRepository(db, domain.CollectionUser)
+	manage := group.Group("",
+		middleware.JwtAuthMiddleware(env.AccessTokenSecret),
+		middleware.RequireRole(ur, domain.RoleCoordinator, domain.RoleAdmin),
+	)
+	manage.POST("/shelters", sc.Create)
+	manage.PUT("/shelters/:id", sc.Replace)
+	manage.PATCH("/shelters/:id", sc.Patch)
+	manage.PATCH("/shelters/:id/occupancy", sc.Occupancy)
+	manage.DELETE("/shelters/:id", sc.Delete)
+}
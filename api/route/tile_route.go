@@ -0,0 +1,30 @@
+package route
+
+import (
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/tile"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// NewTileRouter: tile dùng chung cache với zone / cell usecase để bị xóa khi dữ liệu đổi
+func NewTileRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, tiles *tile.Cache, group *gin.RouterGroup) {
+	tc := &controller.TileController{
+		TileUsecase: usecase.NewTileUsecase(
+			repository.NewCellRepository(db, domain.CollectionCell),
+			repository.NewZoneRepository(db, domain.CollectionZone),
+			repository.NewReportRepo(db, domain.CollectionReport),
+			repository.NewAlertRepo(db, domain.CollectionAlert),
+			tiles,
+			timeout,
+		),
+	}
+
+	group.GET("/tiles/:layer/:z/:x/:y", tc.Get)
+}
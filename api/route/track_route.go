@@ -0,0 +1,36 @@
+package route
+
+import (
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// Lịch sử di chuyển của user
+func NewTrackRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, sharing domain.SharingUsecase, group *gin.RouterGroup) {
+	hr := repository.NewLocationHistoryRepo(db, domain.CollectionLocationHistory)
+	ur := repository.NewUserRepository(db, domain.CollectionUser)
+
+	tc := &controller.TrackController{
+		TrackUsecase: usecase.NewTrackUsecase(hr, ur, sharing, timeout),
+	}
+
+	group.GET("/users/:id/track", tc.Track)
+}
+
+func trackRecorder(env *bootstrap.Env, db mongo.Database) *usecase.TrackRecorder {
+	return usecase.NewTrackRecorder(
+		repository.NewLocationHistoryRepo(db, domain.CollectionLocationHistory),
+		usecase.TrackConfig{
+			Retention: time.Duration(env.LocationHistoryRetentionDays) * 24 * time.Hour,
+			MinMoveM:  env.LocationHistoryMinMoveM,
+			Heartbeat: time.Duration(env.LocationHistoryHeartbeatMin) * time.Minute,
+		},
+	)
+}
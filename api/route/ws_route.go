@@ -14,7 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewWSRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, group *gin.RouterGroup) {
+func NewWSRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, wsManager *ws.WSManager, zoneNotifier domain.ZoneNotifier, geofence *usecase.Geofence, group *gin.RouterGroup) {
 
 	// ================== //
 	// 1. PRIORITY QUEUE (core realtime)
@@ -29,9 +29,8 @@ func NewWSRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, g
 	aiQueue.Start(1) // 1 worker chạy nhẹ thôi
 
 	// ================== //
-	// 3. WS MANAGER
+	// 3. WS MANAGER: tạo trong Setup, dùng chung với các REST route cần broadcast
 	// ================== //
-	wsManager := ws.NewWSManager()
 
 	// ================== //
 	// 4. REPOSITORIES
@@ -40,14 +39,24 @@ func NewWSRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, g
 	alertRepo := repository.NewAlertRepo(db, domain.CollectionAlert)
 	reportRepo := repository.NewReportRepo(db, domain.CollectionReport)
 	zoneRepo := repository.NewZoneRepository(db, domain.CollectionZone)
+	zoneHistoryRepo := repository.NewZoneHistoryRepo(db, domain.CollectionZoneHistory)
+	jobRepo := repository.NewEnrichmentJobRepo(db, domain.CollectionEnrichmentJob)
 
 	// ================== //
 	// 5. USE CASES
 	// ================== //
-	zoneUC := usecase.NewZoneUsecase(zoneRepo, timeout)
-	locUC := usecase.NewLocationUC(queue, wsManager, locRepo, timeout)
-	alertUC := usecase.NewAlertUC(queue, wsManager, alertRepo, locUC, timeout)
-	reportUC := usecase.NewReportUC(queue, aiQueue, wsManager, reportRepo, zoneUC, timeout)
+	zoneUC := usecase.NewZoneUsecase(zoneRepo, zoneHistoryRepo, zoneNotifier, zoneMergeConfig(env), timeout)
+	locUC := usecase.NewLocationUC(queue, wsManager, locRepo, geofence, trackRecorder(env, db), usecase.NewLocationThrottle(usecase.ThrottleConfig{
+		Window:    time.Duration(env.LocationThrottleWindowSec) * time.Second,
+		Heartbeat: time.Duration(env.LocationHeartbeatMin) * time.Minute,
+		MinMoveM:  env.LocationMinMoveM,
+	}), timeout)
+	alertUC := usecase.NewAlertUC(queue, wsManager, alertRepo, locUC, zoneUC, timeout)
+	reportUC := usecase.NewReportUC(queue, aiQueue, wsManager, reportRepo, jobRepo, zoneUC, enrichmentRetryConfig(env), timeout)
+
+	// retry AI enrichment + quét lại report chưa enrich khi khởi động
+	enrichmentWorker := worker.NewEnrichmentWorker(reportUC, time.Duration(env.EnrichmentPollSec)*time.Second)
+	enrichmentWorker.Start()
 
 	// ================== //
 	// 6. CONTROLLER
@@ -65,3 +74,13 @@ func NewWSRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, g
 	// ================== //
 	group.GET("/ws", c.HandleWS)
 }
+
+func enrichmentRetryConfig(env *bootstrap.Env) usecase.EnrichmentRetryConfig {
+	return usecase.EnrichmentRetryConfig{
+		MaxAttempts: env.EnrichmentMaxAttempts,
+		BaseDelay:   time.Duration(env.EnrichmentRetryBaseSec) * time.Second,
+		MaxDelay:    time.Duration(env.EnrichmentRetryMaxSec) * time.Second,
+		Lease:       2 * time.Minute,
+		BatchSize:   50,
+	}
+}
@@ -1,29 +1,85 @@
 package route
 
 import (
+	"log"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/controller"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/middleware"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/worker"
 	"github.com/gin-gonic/gin"
 )
 
-func NewZoneRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, group *gin.RouterGroup) {
+func NewZoneRouter(env *bootstrap.Env, timeout time.Duration, db mongo.Database, zoneNotifier domain.ZoneNotifier, group *gin.RouterGroup) {
 	zr := repository.NewZoneRepository(db, domain.CollectionZone)
+	hr := repository.NewZoneHistoryRepo(db, domain.CollectionZoneHistory)
 
 	zc := &controller.ZoneController{
-		ZoneUsecase: usecase.NewZoneUsecase(zr, timeout),
+		ZoneUsecase:        usecase.NewZoneUsecase(zr, hr, zoneNotifier, zoneMergeConfig(env), timeout),
+		ZoneHistoryUsecase: usecase.NewZoneHistoryUsecase(hr, timeout),
 	}
 
-	group.POST("/zones", zc.Create)
 	group.GET("/zones", zc.FetchInBounds)
 	group.GET("/zones/by-location", zc.FetchByLatLon)
 	group.GET("/zones/all", zc.FetchAll)
 
-	// decayWorker := worker.NewRiskDecayWorker(zc.ZoneUsecase, 15*time.Minute)
-	// decayWorker.Start()
+	// lịch sử risk
+	group.GET("/zones/history", zc.AreaHistory)
+	group.GET("/zones/:id/history", zc.History)
+
+	// tạo / sửa / xóa zone: chỉ coordinator và admin
+	ur := repository.NewUserRepository(db, domain.CollectionUser)
+	manage := group.Group("",
+		middleware.JwtAuthMiddleware(env.AccessTokenSecret),
+		middleware.RequireRole(ur, domain.RoleCoordinator, domain.RoleAdmin),
+	)
+	manage.POST("/zones", zc.Create)
+	manage.PUT("/zones/:id", zc.Replace)
+	manage.PATCH("/zones/:id", zc.Patch)
+	manage.DELETE("/zones/:id", zc.Delete)
+
+	// xóa history quá thời gian lưu trữ (ZONE_HISTORY_RETENTION_DAYS <= 0 = giữ mãi)
+	retentionWorker := worker.NewRetentionWorker("zone_history", hr, time.Duration(env.ZoneHistoryRetentionDays)*24*time.Hour, time.Hour)
+	retentionWorker.Start()
+
+	// giảm risk zone theo thời gian: client đang xem vùng đó nhận "zone_event", mọi client nhận "zone_label" khi label đổi
+	decayWorker := worker.NewRiskDecayWorker(zc.ZoneUsecase, riskDecayPolicy(env), time.Duration(env.RiskDecayIntervalMin)*time.Minute)
+	decayWorker.Start()
+
+	// gộp các zone chồng lấn: "zone_event" (merged) cho viewport, "zone_replaced" cho mọi client
+	// (ZONE_MERGE_INLINE chỉ gộp quanh zone mới tạo, worker vẫn chạy để bắt các zone nở to dần)
+	coalesceWorker := worker.NewZoneCoalesceWorker(zc.ZoneUsecase, env.ZoneMergeOverlap, env.ZoneMergeMaxRadiusM, time.Duration(env.ZoneMergeIntervalMin)*time.Minute)
+	coalesceWorker.Start()
+}
+
+func zoneMergeConfig(env *bootstrap.Env) domain.ZoneMergeConfig {
+	return domain.ZoneMergeConfig{
+		Overlap:   env.ZoneMergeOverlap,
+		MaxRadius: env.ZoneMergeMaxRadiusM,
+		Inline:    env.ZoneMergeInline,
+	}
+}
+
+func riskDecayPolicy(env *bootstrap.Env) worker.DecayPolicy {
+	halfLives, err := worker.ParseHalfLives(env.RiskHalfLifeHours)
+	if err != nil {
+		log.Fatal("Invalid RISK_HALF_LIFE_HOURS: ", err)
+	}
+
+	defaultHalfLife := 6 * time.Hour
+	if d, ok := halfLives["DEFAULT"]; ok {
+		defaultHalfLife = d
+	}
+
+	return worker.DecayPolicy{
+		HalfLife:        halfLives,
+		DefaultHalfLife: defaultHalfLife,
+		DeclaredFloor:   env.RiskDeclaredFloor,
+		RemoveBelow:     env.RiskRemoveBelow,
+	}
 }
@@ -1,6 +1,11 @@
 package bootstrap
 
-import "github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+import (
+	"log"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/textnorm"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+)
 
 type Application struct {
 	Env   *Env
@@ -10,6 +15,11 @@ type Application struct {
 func App() Application {
 	app := &Application{}
 	app.Env = NewEnv()
+	if app.Env.HazardKeywordsFile != "" {
+		if err := textnorm.Hazards.LoadFile(app.Env.HazardKeywordsFile); err != nil {
+			log.Fatal("Can't load hazard keywords: ", err)
+		}
+	}
 	app.Mongo = NewMongoDatabase(app.Env)
 	return *app
 }
@@ -76,6 +76,53 @@ type Env struct {
 	RedisPass              string
 	RedisDB                int
 	GeminiAPIKey           string
+
+	EnrichmentMaxAttempts  int
+	EnrichmentRetryBaseSec int
+	EnrichmentRetryMaxSec  int
+	EnrichmentPollSec      int
+
+	ExportTimeoutSec int
+
+	TileCacheEntries int
+
+	RoadGraphFile string
+
+	HazardKeywordsFile string
+
+	RiskDecayIntervalMin int
+	RiskHalfLifeHours    string // "FLOOD=24,FIRE=6,DEFAULT=6"
+	RiskDeclaredFloor    float64
+	RiskRemoveBelow      float64
+
+	ZoneMergeIntervalMin int
+	ZoneMergeOverlap     float64
+	ZoneMergeMaxRadiusM  float64
+	ZoneMergeInline      bool // gộp ngay khi tạo zone mới, ngoài worker định kỳ
+
+	ZoneHistoryRetentionDays int
+
+	GeofenceExitMarginM  float64
+	GeofenceCooldownMin  int
+	GeofenceNotifyGroups bool
+
+	LocationHistoryRetentionDays int
+	LocationHistoryMinMoveM      float64
+	LocationHistoryHeartbeatMin  int
+
+	LocationThrottleWindowSec int
+	LocationHeartbeatMin      int
+	LocationMinMoveM          float64
+
+	StaleLocationDefaultMin  int
+	StaleLocationSafeMin     int
+	StaleLocationUrgentMin   int
+	StaleLocationIntervalMin int
+
+	InviteTTLHours      int
+	InviteJoinLimit     int
+	InviteJoinWindowMin int
+	InviteLinkBase      string
 }
 
 func NewEnv() *Env {
@@ -88,7 +135,7 @@ func NewEnv() *Env {
 	// lấy từ viper hoặc fallback sang os.Getenv
 	env.AppEnv = getString("APP_ENV", "production")
 	env.ServerAddress = getString("SERVER_ADDRESS", ":8080")
-	env.ContextTimeout = getInt("CONTEXT_TIMEOUT", 10)
+	env.ContextTimeout = getPositiveInt("CONTEXT_TIMEOUT", 10)
 	env.DBHost = getString("DB_HOST", "localhost")
 	env.GeminiAPIKey = getString("GEMINI_API_KEY", "")
 	env.DBPort = getString("DB_PORT", "5432")
@@ -96,8 +143,8 @@ func NewEnv() *Env {
 	env.DBPass = getString("DB_PASS", "pass")
 	env.DBName = getString("DB_NAME", "dbname")
 	env.MongoURI = getString("MONGO_URI", "")
-	env.AccessTokenExpiryHour = getInt("ACCESS_TOKEN_EXPIRY_HOUR", 1)
-	env.RefreshTokenExpiryHour = getInt("REFRESH_TOKEN_EXPIRY_HOUR", 24)
+	env.AccessTokenExpiryHour = getPositiveInt("ACCESS_TOKEN_EXPIRY_HOUR", 1)
+	env.RefreshTokenExpiryHour = getPositiveInt("REFRESH_TOKEN_EXPIRY_HOUR", 24)
 	env.AccessTokenSecret = getString("ACCESS_TOKEN_SECRET", "")
 	env.RefreshTokenSecret = getString("REFRESH_TOKEN_SECRET", "")
 	env.RedisHost = getString("REDIS_HOST", "localhost")
@@ -105,6 +152,66 @@ func NewEnv() *Env {
 	env.RedisPass = getString("REDIS_PASS", "")
 	env.RedisDB = getInt("REDIS_DB", 0)
 
+	// AI enrichment retry
+	env.EnrichmentMaxAttempts = getPositiveInt("ENRICHMENT_MAX_ATTEMPTS", 6)
+	env.EnrichmentRetryBaseSec = getPositiveInt("ENRICHMENT_RETRY_BASE_SEC", 30)
+	env.EnrichmentRetryMaxSec = getPositiveInt("ENRICHMENT_RETRY_MAX_SEC", 1800)
+	env.EnrichmentPollSec = getPositiveInt("ENRICHMENT_POLL_SEC", 15)
+
+	// export GeoJSON / KML / CSV
+	env.ExportTimeoutSec = getPositiveInt("EXPORT_TIMEOUT_SEC", 300)
+
+	// số tile bản đồ giữ trong bộ nhớ
+	env.TileCacheEntries = getPositiveInt("TILE_CACHE_ENTRIES", 5000)
+
+	// file OSM XML (.osm / .osm.gz) đã cắt theo khu vực, để trống thì không gợi ý đường tránh
+	env.RoadGraphFile = getString("ROAD_GRAPH_FILE", "")
+
+	// file JSON thêm keyword cho bộ phân loại hazard local
+	env.HazardKeywordsFile = getString("HAZARD_KEYWORDS_FILE", "")
+
+	// zone risk decay
+	env.RiskDecayIntervalMin = getPositiveInt("RISK_DECAY_INTERVAL_MIN", 15)
+	env.RiskHalfLifeHours = getString("RISK_HALF_LIFE_HOURS", "FLOOD=24,LANDSLIDE=48,STORM=12,FIRE=6,ACCIDENT=2,DEFAULT=6")
+	env.RiskDeclaredFloor = getFloat("RISK_DECLARED_FLOOR", 0.6)
+	env.RiskRemoveBelow = getFloat("RISK_REMOVE_BELOW", 0.05)
+
+	// gộp zone chồng lấn
+	env.ZoneMergeIntervalMin = getPositiveInt("ZONE_MERGE_INTERVAL_MIN", 10)
+	env.ZoneMergeOverlap = getFloat("ZONE_MERGE_OVERLAP", 0.5)
+	env.ZoneMergeMaxRadiusM = getFloat("ZONE_MERGE_MAX_RADIUS_M", 3000)
+	env.ZoneMergeInline = getBool("ZONE_MERGE_INLINE", false)
+
+	// lịch sử risk của zone (retention <= 0 = giữ mãi)
+	env.ZoneHistoryRetentionDays = getInt("ZONE_HISTORY_RETENTION_DAYS", 180)
+
+	// cảnh báo vào / ra zone nguy hiểm
+	env.GeofenceExitMarginM = getFloat("GEOFENCE_EXIT_MARGIN_M", 50)
+	env.GeofenceCooldownMin = getInt("GEOFENCE_COOLDOWN_MIN", 10)
+	env.GeofenceNotifyGroups = getString("GEOFENCE_NOTIFY_GROUPS", "true") == "true"
+
+	// lịch sử di chuyển
+	env.LocationHistoryRetentionDays = getPositiveInt("LOCATION_HISTORY_RETENTION_DAYS", 30)
+	env.LocationHistoryMinMoveM = getFloat("LOCATION_HISTORY_MIN_MOVE_M", 25)
+	env.LocationHistoryHeartbeatMin = getPositiveInt("LOCATION_HISTORY_HEARTBEAT_MIN", 15)
+
+	// gộp fix location qua WS
+	env.LocationThrottleWindowSec = getPositiveInt("LOCATION_THROTTLE_WINDOW_SEC", 5)
+	env.LocationHeartbeatMin = getPositiveInt("LOCATION_HEARTBEAT_MIN", 5)
+	env.LocationMinMoveM = getFloat("LOCATION_MIN_MOVE_M", 10)
+
+	// mất tín hiệu quá lâu -> UNKNOWN
+	env.StaleLocationDefaultMin = getPositiveInt("STALE_LOCATION_DEFAULT_MIN", 120)
+	env.StaleLocationSafeMin = getPositiveInt("STALE_LOCATION_SAFE_MIN", 360)
+	env.StaleLocationUrgentMin = getPositiveInt("STALE_LOCATION_URGENT_MIN", 30)
+	env.StaleLocationIntervalMin = getPositiveInt("STALE_LOCATION_INTERVAL_MIN", 1)
+
+	// mã mời vào nhóm: hạn mặc định, giới hạn số lần thử join / tra mã, link mở app
+	env.InviteTTLHours = getPositiveInt("INVITE_TTL_HOURS", 24)
+	env.InviteJoinLimit = getPositiveInt("INVITE_JOIN_LIMIT", 10)
+	env.InviteJoinWindowMin = getPositiveInt("INVITE_JOIN_WINDOW_MIN", 10)
+	env.InviteLinkBase = getString("INVITE_LINK_BASE", "stormwatch://join")
+
 	if env.AppEnv == "development" {
 		log.Println("The App is running in development env")
 	}
@@ -134,3 +241,37 @@ func getInt(key string, defaultVal int) int {
 	}
 	return defaultVal
 }
+
+// getPositiveInt dùng cho mọi thời hạn / chu kỳ / giới hạn không có nghĩa khi <= 0:
+// giá trị <= 0 quay về mặc định. Giá trị mà 0 có nghĩa riêng (vd giữ mãi, không cooldown) dùng getInt
+func getPositiveInt(key string, defaultVal int) int {
+	if i := getInt(key, defaultVal); i > 0 {
+		return i
+	}
+	log.Printf("%s must be > 0, using default %d", key, defaultVal)
+	return defaultVal
+}
+
+func getBool(key string, defaultVal bool) bool {
+	if viper.IsSet(key) {
+		return viper.GetBool(key)
+	}
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return defaultVal
+}
+
+func getFloat(key string, defaultVal float64) float64 {
+	if viper.IsSet(key) {
+		return viper.GetFloat64(key)
+	}
+	if val := os.Getenv(key); val != "" {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return defaultVal
+}
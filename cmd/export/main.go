@@ -0,0 +1,76 @@
+// export ghi một layer ra file GeoJSON / KML / CSV, dùng cùng cấu hình .env với server.
+//
+//	go run ./cmd/export -layer zones -format kml -out zones.kml
+//	go run ./cmd/export -layer reports -lat 10.77 -lon 106.7 -km 5 -format csv
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+)
+
+func main() {
+	layer := flag.String("layer", domain.ExportLayerReports, "reports | alerts | zones | cells")
+	format := flag.String("format", "geojson", "geojson | kml | csv")
+	out := flag.String("out", "", "file output (mặc định stdout)")
+
+	lat := flag.Float64("lat", 0, "reports / alerts / cells: tâm lat")
+	lon := flag.Float64("lon", 0, "reports / alerts / cells: tâm lon")
+	km := flag.Float64("km", 0, "reports / alerts: bán kính km")
+	radius := flag.Float64("radius", 0, "cells: bán kính mét")
+
+	minLat := flag.Float64("minLat", 0, "zones: bounding box")
+	minLon := flag.Float64("minLon", 0, "zones: bounding box")
+	maxLat := flag.Float64("maxLat", 0, "zones: bounding box")
+	maxLon := flag.Float64("maxLon", 0, "zones: bounding box")
+	flag.Parse()
+
+	var filter domain.ExportFilter
+	switch {
+	case *km > 0:
+		filter.Near = &domain.NearFilter{Lat: *lat, Lon: *lon, RadiusM: *km * 1000}
+	case *radius > 0:
+		filter.Near = &domain.NearFilter{Lat: *lat, Lon: *lon, RadiusM: *radius}
+	}
+	if *minLat != 0 || *minLon != 0 || *maxLat != 0 || *maxLon != 0 {
+		filter.Bounds = &domain.BoundsFilter{MinLat: *minLat, MinLon: *minLon, MaxLat: *maxLat, MaxLon: *maxLon}
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+
+	app := bootstrap.App()
+	defer app.CloseDBConnection()
+	db := app.Mongo.Database(app.Env.DBName)
+
+	uc := usecase.NewExportUsecase(repository.NewExportRepo(db), time.Duration(app.Env.ExportTimeoutSec)*time.Second)
+	if err := uc.Export(context.Background(), *layer, *format, filter, bw); err != nil {
+		log.Fatal(err)
+	}
+	if err := bw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "exported %s to %s\n", *layer, *out)
+	}
+}
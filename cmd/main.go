@@ -1,10 +1,13 @@
 package main
 
 import (
+	"context"
+	"log"
 	"time"
 
 	route "github.com/Storm-Watch-Platform/Storm_Watch_Backend/api/route"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/bootstrap"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -22,11 +25,38 @@ func main() {
 
 	timeout := time.Duration(env.ContextTimeout) * time.Second
 
+	// chuyển dữ liệu cũ trước, rồi mới tạo index (unique hash của cell)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	if n, err := repository.BackfillZoneAreas(ctx, db); err != nil {
+		log.Fatal("Backfill zone areas failed: ", err)
+	} else if n > 0 {
+		log.Printf("Backfilled area for %d zones", n)
+	}
+	if n, err := repository.BackfillCellHashes(ctx, db); err != nil {
+		log.Fatal("Backfill cell hashes failed: ", err)
+	} else if n > 0 {
+		log.Printf("Moved %d cells to geohash grid", n)
+	}
+	if n, err := repository.BackfillInviteCodes(ctx, db); err != nil {
+		log.Fatal("Backfill invite codes failed: ", err)
+	} else if n > 0 {
+		log.Printf("Replaced %d legacy invite codes", n)
+	}
+	if n, err := repository.BackfillGroupOwners(ctx, db); err != nil {
+		log.Fatal("Backfill group owners failed: ", err)
+	} else if n > 0 {
+		log.Printf("Assigned owners to %d legacy groups", n)
+	}
+	if err := repository.EnsureIndexes(ctx, db); err != nil {
+		log.Fatal(err)
+	}
+	cancel()
+
 	gin := gin.Default()
 
 	gin.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://127.0.0.1:5500", "https://stormwatch-two.vercel.app", "https://storm-watch-web.vercel.app"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		AllowCredentials: true,
 	}))
@@ -29,6 +29,12 @@ type AlertRepository interface {
 	FetchByID(ctx context.Context, alertID string) (*Alert, error)
 	FetchByRadius(ctx context.Context, lat, lng, km float64) ([]Alert, error)
 	GetNearbyAlerts(ctx context.Context, lat, lon, km float64) ([]*Alert, error)
+	// alert chưa RESOLVED và chưa hết hạn trong bounding box
+	FetchActiveInBounds(ctx context.Context, b BoundsFilter) ([]*Alert, error)
+	// HasActiveByUser: user có SOS chưa RESOLVED và chưa hết hạn
+	HasActiveByUser(ctx context.Context, userID string) (bool, error)
+	// ActiveUserIDs: trong userIDs, những user đang có SOS mở
+	ActiveUserIDs(ctx context.Context, userIDs []string) ([]string, error)
 }
 
 type AlertUsecase interface {
@@ -0,0 +1,54 @@
+package domain
+
+// Gửi bù dữ liệu offline: app lưu report / location khi mất mạng rồi gửi một lần.
+// Mỗi item có key do client sinh ra; gửi lại cùng key không tạo bản ghi mới.
+
+const MaxBatchItems = 200
+
+// kết quả từng item
+const (
+	BatchItemCreated    = "created"    // lưu mới
+	BatchItemDuplicate  = "duplicate"  // key đã gửi trước đó / location không mới hơn bản đã lưu
+	BatchItemSuperseded = "superseded" // location cũ hơn một điểm khác trong cùng batch
+	BatchItemInvalid    = "invalid"
+	BatchItemFailed     = "failed" // lỗi server, client có thể gửi lại cùng key
+)
+
+type BatchReportItem struct {
+	Key         string  `json:"key"`
+	Type        string  `json:"type"`
+	Detail      string  `json:"detail"`
+	Description string  `json:"description"`
+	Image       string  `json:"image"`
+	Lat         float64 `json:"lat"`
+	Lon         float64 `json:"lon"`
+	Timestamp   int64   `json:"timestamp"` // thời điểm tạo trên máy
+	UserName    string  `json:"user_name"`
+	PhoneNumber string  `json:"phone_number"`
+}
+
+type BatchLocationItem struct {
+	Key       string  `json:"key"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	AccuracyM float64 `json:"accuracy_m"`
+	Status    string  `json:"status"`
+	Timestamp int64   `json:"timestamp"` // ms, giống frame location
+}
+
+type BatchItemResult struct {
+	Key    string `json:"key"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BatchRequest struct {
+	Reports   []BatchReportItem   `json:"reports"`
+	Locations []BatchLocationItem `json:"locations"`
+}
+
+type BatchResponse struct {
+	Reports   []BatchItemResult `json:"reports"`
+	Locations []BatchItemResult `json:"locations"`
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,23 +12,55 @@ const (
 	CollectionCell = "cells"
 )
 
+// Lưới cell dùng geohash: CellLevel là độ dài hash của cell lưu trong DB (~150m),
+// level nhỏ hơn là cell cha gộp từ các cell con có cùng prefix
+const (
+	CellLevel    = 7
+	MinCellLevel = 1
+)
+
+var ErrGridTooLarge = errors.New("too many cells for this area, use a lower level")
+var ErrInvalidRadius = errors.New("radius must be positive")
+
 // Cell represents a grid cell for danger zones
 type Cell struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Center    [2]float64         `bson:"center" json:"center"` // [lon, lat] để index 2dsphere
+	Hash      string             `bson:"hash" json:"hash"`     // geohash CellLevel ký tự, khóa của cell
+	Center    [2]float64         `bson:"center" json:"center"` // [lon, lat] tâm cell, để index 2dsphere
 	RiskScore float64            `bson:"riskScore" json:"riskScore"`
 	Label     string             `bson:"label" json:"label"`
 	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
 
+// CellAggregate — một cell ở level bất kỳ, gộp từ cell lưu trữ và zone chiếu lên lưới
+type CellAggregate struct {
+	Hash      string     `bson:"_id" json:"hash"`
+	Level     int        `bson:"-" json:"level"`
+	Center    [2]float64 `bson:"-" json:"center"`
+	RiskScore float64    `bson:"riskScore" json:"riskScore"` // max của CellRisk và ZoneRisk
+	CellRisk  float64    `bson:"cellRisk" json:"cellRisk"`   // max risk các cell con
+	AvgRisk   float64    `bson:"avgRisk" json:"avgRisk"`
+	Count     int        `bson:"count" json:"count"` // số cell con có dữ liệu
+	ZoneRisk  float64    `bson:"-" json:"zoneRisk"`  // max risk các zone phủ lên cell
+	Label     string     `bson:"-" json:"label"`
+	UpdatedAt time.Time  `bson:"updatedAt" json:"updatedAt"`
+}
+
 // ----------------------
 // Repository interface
 // ----------------------
 type CellRepository interface {
-	GetByLatLon(ctx context.Context, lat, lon float64) (*Cell, error)
+	GetByHash(ctx context.Context, hash string) (*Cell, error)
 	GetByRadius(ctx context.Context, lat, lon, radius float64) ([]Cell, error)
+
+	// gộp cell trong bounding box theo prefix hash dài level ký tự
+	AggregateInBounds(ctx context.Context, b BoundsFilter, level int) ([]CellAggregate, error)
 	Upsert(ctx context.Context, cell *Cell) error
-	UpsertMany(ctx context.Context, cells []*Cell) error
+	// UpsertMany ghi đè risk / label của nhiều cell trong một lần BulkWrite
+	UpsertMany(ctx context.Context, cells []*Cell) (updated int, inserted int, err error)
+	// IncrementMany cộng inc vào risk của các cell (tạo mới nếu chưa có), kết quả chặn trong [0, 1].
+	// label rỗng thì label tính lại theo risk mới.
+	IncrementMany(ctx context.Context, hashes []string, inc float64, label string, at time.Time) (updated int, inserted int, err error)
 }
 
 // ----------------------
@@ -38,6 +71,12 @@ type CellUsecase interface {
 	GetCellByLatLon(ctx context.Context, lat, lon float64) (*Cell, error)
 	GetCellsByRadius(ctx context.Context, lat, lon, radius float64) ([]Cell, error)
 	UpdateCell(ctx context.Context, lat, lon, risk float64, label string) (*Cell, error)
+	// UpdateCellsByRadius cộng riskInc cho mọi cell chạm vòng tròn (bán kính mét)
 	UpdateCellsByRadius(ctx context.Context, lat, lon, radius, riskInc float64, label string) (updated int, inserted int, err error)
+	// SetCellsByRadius đặt risk cho mọi cell chạm vòng tròn
+	SetCellsByRadius(ctx context.Context, lat, lon, radius, risk float64, label string) (updated int, inserted int, err error)
 	Upsert(ctx context.Context, cell *Cell) error
+
+	// lưới risk ở level (MinCellLevel..CellLevel) trong bounding box, withZones = chiếu zone lên lưới
+	FetchGrid(ctx context.Context, b BoundsFilter, level int, withZones bool) ([]CellAggregate, error)
 }
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CollectionEnrichmentJob = "enrichment_jobs"
+
+const (
+	JobStatusQueued = "QUEUED"
+	JobStatusDone   = "DONE"
+	JobStatusFailed = "FAILED"
+)
+
+// EnrichmentJob lưu job phân loại AI của một report để không mất khi restart.
+// _id chính là ID của report nên mỗi report chỉ có một job.
+type EnrichmentJob struct {
+	ID        primitive.ObjectID `bson:"_id" json:"reportId"`
+	Status    string             `bson:"status" json:"status"`
+	Attempts  int                `bson:"attempts" json:"attempts"`
+	NextRunAt time.Time          `bson:"next_run_at" json:"next_run_at"`
+	LastError string             `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	// Claim đổi mỗi lần job được lấy ra; chỉ bên giữ claim hiện tại mới được ghi kết quả
+	Claim     primitive.ObjectID `bson:"claim,omitempty" json:"-"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type EnrichmentJobRepository interface {
+	// Enqueue tạo job (giữ bởi claim) nếu report chưa có job, job đã tồn tại thì giữ nguyên
+	Enqueue(ctx context.Context, reportID primitive.ObjectID, runAt time.Time, claim primitive.ObjectID) error
+	// Restart đưa job về QUEUED với attempts = 0 (vd khi report bị sửa nội dung), claim cũ mất hiệu lực
+	Restart(ctx context.Context, reportID primitive.ObjectID, runAt time.Time) error
+	// ClaimDue lấy các job đến hạn và giữ chúng trong khoảng lease để worker khác không lấy trùng
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]EnrichmentJob, error)
+	// Renew gia hạn lease trước khi chạy; false nếu job đã bị lấy lại (claim khác) hoặc không còn QUEUED
+	Renew(ctx context.Context, job EnrichmentJob, until time.Time) (bool, error)
+	// Reschedule / Finish chỉ ghi khi claim vẫn là của job
+	Reschedule(ctx context.Context, job EnrichmentJob, attempts int, nextRunAt time.Time, lastErr string) error
+	Finish(ctx context.Context, job EnrichmentJob, status string, attempts int, lastErr string) error
+}
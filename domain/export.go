@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// các layer có thể export
+const (
+	ExportLayerReports = "reports"
+	ExportLayerAlerts  = "alerts"
+	ExportLayerZones   = "zones"
+	ExportLayerCells   = "cells"
+)
+
+var ErrUnknownExportLayer = errors.New("unknown export layer")
+
+// ExportFilter dùng lại filter của các API list:
+//   - reports / alerts: lat, lon, km (giống /nearby)
+//   - cells: lat, lon, radius mét (giống /cells)
+//   - zones: bounding box (giống GET /zones)
+//
+// Để nil thì export toàn bộ layer.
+type ExportFilter struct {
+	Near   *NearFilter
+	Bounds *BoundsFilter
+}
+
+type NearFilter struct {
+	Lat     float64
+	Lon     float64
+	RadiusM float64
+}
+
+type BoundsFilter struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// ExportRepository đọc dữ liệu bằng cursor, gọi fn cho từng document
+// để không phải giữ cả layer trong bộ nhớ.
+type ExportRepository interface {
+	StreamReports(ctx context.Context, f ExportFilter, fn func(*Report) error) error
+	StreamAlerts(ctx context.Context, f ExportFilter, fn func(*Alert) error) error
+	StreamZones(ctx context.Context, f ExportFilter, fn func(*Zone) error) error
+	StreamCells(ctx context.Context, f ExportFilter, fn func(*Cell) error) error
+}
+
+type ExportUsecase interface {
+	// Export ghi layer ra w theo format (geojson | kml | csv)
+	Export(ctx context.Context, layer, format string, f ExportFilter, w io.Writer) error
+}
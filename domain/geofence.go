@@ -0,0 +1,40 @@
+package domain
+
+// Loại sự kiện geofence gửi cho user ("geofence_event") và nhóm ("member_geofence")
+const (
+	GeofenceEnter    = "enter"
+	GeofenceExit     = "exit"
+	GeofenceEscalate = "escalate" // đang ở trong vùng và vùng lên mức nguy hiểm hơn
+)
+
+// Loại vùng được theo dõi
+const (
+	GeofenceZone  = "zone"  // zone MEDIUM / HIGH
+	GeofenceAlert = "alert" // vùng alert đang mở
+)
+
+// GeofenceArea — vùng tròn mà user có thể đi vào / ra
+type GeofenceArea struct {
+	Kind      string  `json:"kind"`
+	ID        string  `json:"id"`
+	Label     string  `json:"label"`
+	RiskScore float64 `json:"riskScore"`
+	Hazard    string  `json:"hazard,omitempty"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	RadiusM   float64 `json:"radiusM"`
+}
+
+// Key phân biệt zone và alert trùng id
+func (a GeofenceArea) Key() string {
+	return a.Kind + "/" + a.ID
+}
+
+type GeofenceEvent struct {
+	Type   string       `json:"type"`
+	UserID string       `json:"userId"`
+	Area   GeofenceArea `json:"area"`
+	Lat    float64      `json:"lat"`
+	Lon    float64      `json:"lon"`
+	At     int64        `json:"at"` // timestamp (s)
+}
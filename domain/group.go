@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -16,18 +17,41 @@ const (
 type Group struct {
 	ID         primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
 	Name       string               `bson:"name" json:"name"`
-	InviteCode string               `bson:"inviteCode" json:"inviteCode"`
+	InviteCode string               `bson:"inviteCode,omitempty" json:"inviteCode,omitempty"` // rỗng = đã thu hồi
 	MemberIDs  []primitive.ObjectID `bson:"memberIDs" json:"memberIDs"`
-	ExpiresAt  time.Time            `bson:"expiresAt" json:"expiresAt"`
+	ExpiresAt  time.Time            `bson:"expiresAt" json:"expiresAt"` // hạn của mã mời
 	CreatedAt  time.Time            `bson:"createdAt" json:"createdAt"`
+
+	InviteMaxUses int `bson:"inviteMaxUses" json:"inviteMaxUses"` // 0 = không giới hạn
+	InviteUses    int `bson:"inviteUses" json:"inviteUses"`       // số người đã join bằng mã hiện tại
+}
+
+// Invite: mã mời hiện tại của nhóm
+type Invite struct {
+	GroupID   string    `json:"groupId"`
+	Code      string    `json:"inviteCode"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	MaxUses   int       `json:"maxUses"`
+	Uses      int       `json:"uses"`
+}
+
+// InviteLink: deep link mở app tới màn hình join, Link cũng là nội dung của mã QR
+type InviteLink struct {
+	Code      string    `json:"inviteCode"`
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
 type GroupMemberDetail struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
+	ID    string    `json:"id"`
+	Name  string    `json:"name"`
+	Phone string    `json:"phone"`
+	Role  GroupRole `json:"role"`
 
-	Location MemberLocation `json:"location"`
+	DistanceM *float64 `json:"distance_m,omitempty"` // từ vị trí mới nhất của người xem, nil nếu một trong hai chưa có vị trí
+
+	LocationShared bool           `json:"locationShared"` // false = member không chia sẻ vị trí cho người xem
+	Location       MemberLocation `json:"location"`
 }
 
 type MemberLocation struct {
@@ -36,18 +60,66 @@ type MemberLocation struct {
 	Status      string   `json:"status"`
 	UpdatedAt   int64    `json:"updated_at"`
 	Coordinates GeoPoint `json:"location"`
+	AgeSec      int64    `json:"age_s"`                 // số giây kể từ fix cuối
+	Stale       bool     `json:"stale"`                 // đã bị chuyển sang UNKNOWN vì mất tín hiệu
+	StaleSince  int64    `json:"stale_since,omitempty"` // (s) lúc bị chuyển sang UNKNOWN vì mất tín hiệu
+	LastStatus  string   `json:"last_status,omitempty"` // status trước khi mất tín hiệu
 }
 
-// Member: lưu thông tin từng người trong nhóm + vị trí realtime
+// Member: thành viên của nhóm; vị trí realtime lấy từ collection locations
 type Member struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
 	GroupID   primitive.ObjectID `bson:"groupId" json:"groupId"`
 	UserID    primitive.ObjectID `bson:"userId" json:"userId"`
-	Lat       float64            `bson:"lat" json:"lat"`
-	Lon       float64            `bson:"lon" json:"lon"`
+	Role      GroupRole          `bson:"role" json:"role"`
 	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
 }
 
+// GroupRole: owner (duy nhất, người tạo) > admin > member
+type GroupRole string
+
+const (
+	GroupRoleOwner  GroupRole = "owner"
+	GroupRoleAdmin  GroupRole = "admin"
+	GroupRoleMember GroupRole = "member"
+)
+
+// Rank để so quyền giữa hai thành viên
+func (r GroupRole) Rank() int {
+	switch r {
+	case GroupRoleOwner:
+		return 3
+	case GroupRoleAdmin:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Normalize: member cũ chưa có role coi như member
+func (r GroupRole) Normalize() GroupRole {
+	if r == GroupRoleOwner || r == GroupRoleAdmin {
+		return r
+	}
+	return GroupRoleMember
+}
+
+var (
+	ErrNotGroupMember      = errors.New("you are not in this group")
+	ErrGroupMemberNotFound = errors.New("user not in this group")
+	ErrGroupForbidden      = errors.New("your role in this group does not allow this")
+	ErrInvalidGroupRole    = errors.New("role must be admin or member")
+	ErrOwnerMustTransfer   = errors.New("owner must transfer ownership before leaving")
+	ErrAlreadyInGroup      = errors.New("user already in group")
+
+	ErrInvalidInvite    = errors.New("invalid invite code")
+	ErrInviteExpired    = errors.New("invite code has expired")
+	ErrInviteExhausted  = errors.New("invite code has reached its usage limit")
+	ErrInviteCodeTaken  = errors.New("invite code already in use")
+	ErrInviteNotEnabled = errors.New("group has no active invite code")
+	ErrInviteSettings   = errors.New("invite ttl and max uses must not be negative")
+)
+
 //
 // INTERFACES (Repository layer)
 //
@@ -58,20 +130,51 @@ type GroupRepository interface {
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	GetByInviteCode(ctx context.Context, code string) (Group, error)
 	GetByID(ctx context.Context, id primitive.ObjectID) (Group, error)
+	// GetByIDs lấy nhiều group trong một query, group không tồn tại thì bỏ qua
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]Group, error)
 	AddMember(ctx context.Context, groupID primitive.ObjectID, userID primitive.ObjectID) error
+	RemoveMember(ctx context.Context, groupID primitive.ObjectID, userID primitive.ObjectID) error
+	// Create / SetInvite trả ErrInviteCodeTaken nếu mã đã thuộc nhóm khác
+	SetInvite(ctx context.Context, groupID primitive.ObjectID, code string, expiresAt time.Time, maxUses int) error
+	RevokeInvite(ctx context.Context, groupID primitive.ObjectID) error
+	// UseInvite tăng số lần dùng nếu mã vẫn là mã hiện tại, chưa hết hạn và còn lượt; false nếu không
+	UseInvite(ctx context.Context, groupID primitive.ObjectID, code string, now time.Time) (bool, error)
 }
 
 type MemberRepository interface {
 	Add(c context.Context, m *Member) error
-	UpdateLocation(c context.Context, userID primitive.ObjectID, lat, lon float64, updatedAt time.Time) error
 	ListByGroup(c context.Context, groupID primitive.ObjectID) ([]Member, error)
+	// SetRole tạo bản ghi nếu chưa có (thành viên của nhóm tạo trước khi có role)
+	SetRole(c context.Context, groupID, userID primitive.ObjectID, role GroupRole) error
+	Remove(c context.Context, groupID, userID primitive.ObjectID) error
+	DeleteByGroup(c context.Context, groupID primitive.ObjectID) error
 }
 
+// Thay đổi thành viên giữ đồng bộ 3 nơi: collection members, group.memberIDs, user.groupIDs.
+// group.memberIDs quyết định quyền xem: khi thêm thì sửa sau cùng, khi bỏ thì sửa đầu tiên.
 type GroupUsecase interface {
-	CreateGroup(c context.Context, name string) (*Group, error)
+	// CreateGroup: người tạo thành owner và thành viên đầu tiên
+	CreateGroup(c context.Context, userID, name string) (*Group, error)
 	GetByInviteCode(c context.Context, code string) (*Group, error)
+	// DeleteGroup: chỉ owner
 	DeleteGroup(c context.Context, userID string, groupID primitive.ObjectID) error
-	GetInviteCodeByGroupID(c context.Context, groupID primitive.ObjectID) (string, error)
+	// LeaveGroup: owner phải chuyển quyền trước, trừ khi là thành viên cuối (nhóm bị xóa)
+	LeaveGroup(c context.Context, userID string, groupID primitive.ObjectID) error
+	// RemoveMember: owner kick được mọi người, admin chỉ kick được member
+	RemoveMember(c context.Context, actorID string, groupID primitive.ObjectID, memberID string) error
+	// SetMemberRole: owner đổi admin <-> member
+	SetMemberRole(c context.Context, actorID string, groupID primitive.ObjectID, memberID string, role GroupRole) error
+	// TransferOwnership: owner cũ thành admin
+	TransferOwnership(c context.Context, actorID string, groupID primitive.ObjectID, newOwnerID string) error
+	// GetInvite / InviteLink: mọi thành viên; RotateInvite / RevokeInvite: owner, admin
+	GetInvite(c context.Context, userID string, groupID primitive.ObjectID) (*Invite, error)
+	InviteLink(c context.Context, userID string, groupID primitive.ObjectID) (*InviteLink, error)
+	// RotateInvite: mã mới thay mã cũ; ttl = 0 dùng hạn mặc định, maxUses = 0 không giới hạn
+	RotateInvite(c context.Context, userID string, groupID primitive.ObjectID, ttl time.Duration, maxUses int) (*Invite, error)
+	RevokeInvite(c context.Context, userID string, groupID primitive.ObjectID) error
+	// JoinGroup: mã phải còn hạn và còn lượt
 	JoinGroup(ctx context.Context, userID string, inviteCode string) error
-	GetMemberInGroup(ctx context.Context, groupID, memberID string) (*GroupMemberDetail, error)
+	GetMemberInGroup(ctx context.Context, viewerID, groupID, memberID string) (*GroupMemberDetail, error)
+	// ListMembers: mọi thành viên kèm vị trí (theo cài đặt chia sẻ) và khoảng cách tới người xem
+	ListMembers(ctx context.Context, viewerID, groupID string) ([]GroupMemberDetail, error)
 }
@@ -8,6 +8,22 @@ type Location struct {
 	Status    string   `bson:"status" json:"status"`         // SAFE | CAUTION | DANGER | UNKNOWN
 	UpdatedAt int64    `bson:"updated_at" json:"updated_at"` // timestamp (s)
 	Location  GeoPoint `bson:"location" json:"location"`     // GeoJSON Point
+
+	// im lặng quá lâu thì status bị chuyển sang UNKNOWN; fix mới sẽ xóa 2 field này
+	StaleSince int64  `bson:"stale_since,omitempty" json:"stale_since,omitempty"` // (s) lúc bị chuyển
+	LastStatus string `bson:"last_status,omitempty" json:"last_status,omitempty"` // status trước khi bị chuyển
+}
+
+const LocationUnknown = "UNKNOWN"
+
+// StaleEvent gửi cho các nhóm khi một thành viên mất tín hiệu
+type StaleEvent struct {
+	GroupID    string `json:"groupId"`
+	UserID     string `json:"userId"`
+	UserName   string `json:"userName"`
+	LastStatus string `json:"lastStatus"`
+	LastSeen   int64  `json:"lastSeen"`   // (s)
+	StaleSince int64  `json:"staleSince"` // (s)
 }
 
 type GeoPoint struct {
@@ -17,9 +33,26 @@ type GeoPoint struct {
 
 const CollectionLocation = "locations"
 
+// SilentQuery: location chưa UNKNOWN có fix cuối trước Before (s)
+type SilentQuery struct {
+	Before          int64
+	Statuses        []string // chỉ lấy các status này, rỗng = mọi status
+	ExcludeStatuses []string
+	// RadiusM > 0: chỉ lấy fix cuối cách (Lat, Lon) không quá RadiusM
+	Lat, Lon, RadiusM float64
+}
+
 // Interface
 type LocationRepository interface {
 	Upsert(ctx context.Context, loc *Location) error
+	// UpsertIfNewer chỉ ghi khi loc.UpdatedAt mới hơn bản đang lưu
+	UpsertIfNewer(ctx context.Context, loc *Location) (bool, error)
 	GetByUserID(ctx context.Context, userID string) (*Location, error)
+	// GetByUserIDs lấy location của nhiều user trong một query, user chưa có location thì không có trong kết quả
+	GetByUserIDs(ctx context.Context, userIDs []string) ([]Location, error)
 	GetNearbyUserIDs(ctx context.Context, lat, lon, km float64) ([]string, error)
+	// FetchSilent lấy location khớp q, fix cũ nhất trước
+	FetchSilent(ctx context.Context, q SilentQuery, limit int) ([]Location, error)
+	// MarkStale chuyển sang UNKNOWN nếu từ lúc đọc tới giờ chưa có fix mới (updated_at vẫn bằng updatedAt)
+	MarkStale(ctx context.Context, userID string, updatedAt, staleSince int64) (bool, error)
 }
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CollectionLocationHistory = "location_history"
+
+var ErrTrackForbidden = errors.New("not allowed to view this user's track")
+
+// TrackPoint là một điểm trong lịch sử di chuyển của user (chỉ thêm, không sửa vị trí).
+// User đứng yên thì không thêm điểm mới mà kéo dài LastSeenAt của điểm cuối.
+type TrackPoint struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     string             `bson:"userId" json:"userId"`
+	Location   GeoPoint           `bson:"location" json:"location"`
+	AccuracyM  float64            `bson:"accuracy_m" json:"accuracy_m"`
+	Status     string             `bson:"status" json:"status"`
+	Timestamp  int64              `bson:"timestamp" json:"timestamp"`   // (s) lúc tới điểm này
+	LastSeenAt int64              `bson:"lastSeenAt" json:"lastSeenAt"` // (s) fix cuối cùng còn ở điểm này
+	ExpiresAt  time.Time          `bson:"expiresAt" json:"-"`           // TTL index xóa khi tới hạn
+}
+
+type LocationHistoryRepository interface {
+	// Append thêm điểm, đã có điểm cùng (userId, timestamp) thì bỏ qua
+	Append(ctx context.Context, p *TrackPoint) error
+	// Touch kéo dài thời gian user còn ở điểm id
+	Touch(ctx context.Context, id primitive.ObjectID, seenAt int64, expiresAt time.Time) error
+	// Last trả về điểm mới nhất, chưa có thì (nil, nil)
+	Last(ctx context.Context, userID string) (*TrackPoint, error)
+	// Covers: đã có điểm mà [timestamp, lastSeenAt] chứa at
+	Covers(ctx context.Context, userID string, at int64) (bool, error)
+	// Range lấy các điểm giao với [from, to] (s), tăng dần theo thời gian; quá limit thì giữ các điểm mới nhất
+	Range(ctx context.Context, userID string, from, to int64, limit int) (points []TrackPoint, truncated bool, err error)
+}
+
+type Track struct {
+	UserID    string       `json:"userId"`
+	From      int64        `json:"from"` // (s)
+	To        int64        `json:"to"`   // (s)
+	Points    []TrackPoint `json:"points"`
+	Truncated bool         `json:"truncated"` // quá nhiều điểm, chỉ trả phần gần nhất
+}
+
+type TrackUsecase interface {
+	// Track kiểm tra viewerID có quyền xem (chính mình, cùng nhóm, coordinator / admin) rồi lấy trail
+	Track(ctx context.Context, viewerID, userID string, from, to time.Time) (*Track, error)
+}
@@ -2,11 +2,38 @@ package domain
 
 import (
 	"context"
+	"errors"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-const CollectionReport = "reports"
+const (
+	CollectionReport         = "reports"
+	CollectionReportRevision = "report_revisions"
+)
+
+// Vòng đời của report (field Status)
+const (
+	ReportStatusOpen      = "OPEN"      // còn hiệu lực
+	ReportStatusCleared   = "CLEARED"   // tác giả xác nhận đã hết nguy hiểm (cây đã dọn, đường đã thông...)
+	ReportStatusRetracted = "RETRACTED" // tác giả rút lại report
+)
+
+var (
+	ErrReportNotFound        = errors.New("report not found")
+	ErrNotReportAuthor       = errors.New("only the author can change this report")
+	ErrReportRetracted       = errors.New("report has been retracted")
+	ErrRevisionConflict      = errors.New("report was modified, reload and try again")
+	ErrInvalidReportLocation = errors.New("invalid lat/lon")
+)
+
+// Trạng thái phân loại AI của report
+const (
+	EnrichmentPending  = "PENDING"  // đang chờ AI
+	EnrichmentDone     = "DONE"     // đã có kết quả AI thật
+	EnrichmentFailed   = "FAILED"   // hết số lần retry, giữ kết quả fallback
+	EnrichmentFallback = "FALLBACK" // AI lỗi, đang dùng giá trị mặc định và chờ retry
+)
 
 type Report struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -22,6 +49,45 @@ type Report struct {
 	PhoneNumber string            `bson:"phone_number" json:"phone_number"`
 	UserName    string            `bson:"user_name" json:"user_name"`
 	Enrichment  *ReportEnrichment `bson:"enrichment,omitempty" json:"enrichment,omitempty"`
+	ClientKey   string            `bson:"client_key,omitempty" json:"client_key,omitempty"` // key idempotency khi gửi bù offline
+
+	EnrichmentState string `bson:"enrichment_state,omitempty" json:"enrichment_state,omitempty"`
+	// risk report đã đẩy vào zone, kết quả AI sau chỉ được nâng phần vượt quá giá trị này
+	RiskApplied float64 `bson:"risk_applied,omitempty" json:"risk_applied,omitempty"`
+
+	Revision  int   `bson:"revision" json:"revision"`                         // tăng mỗi lần sửa / đổi trạng thái
+	UpdatedAt int64 `bson:"updated_at,omitempty" json:"updated_at,omitempty"` // timestamp (s)
+}
+
+// IsActive: report cũ chưa có status ("" / "RAISED") vẫn tính là OPEN
+func (r *Report) IsActive() bool {
+	return r.Status != ReportStatusCleared && r.Status != ReportStatusRetracted
+}
+
+// ReportRevision lưu bản chụp của report trước mỗi lần sửa hoặc đổi trạng thái
+type ReportRevision struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ReportID    primitive.ObjectID `bson:"report_id" json:"report_id"`
+	Revision    int                `bson:"revision" json:"revision"`
+	Type        string             `bson:"type" json:"type"`
+	Detail      string             `bson:"detail" json:"detail"`
+	Description string             `bson:"description" json:"description"`
+	Image       string             `bson:"image,omitempty" json:"image,omitempty"`
+	Location    GeoPoint           `bson:"location" json:"location"`
+	Status      string             `bson:"status" json:"status"`
+	EditedBy    string             `bson:"edited_by" json:"edited_by"`
+	EditedAt    int64              `bson:"edited_at" json:"edited_at"`
+}
+
+// ReportEdit: các field nil thì giữ nguyên
+type ReportEdit struct {
+	Type        *string  `json:"type"`
+	Detail      *string  `json:"detail"`
+	Description *string  `json:"description"`
+	Image       *string  `json:"image"`
+	Lat         *float64 `json:"lat"`
+	Lon         *float64 `json:"lon"`
+	Revision    *int     `json:"revision"` // nếu gửi lên thì phải khớp revision hiện tại
 }
 type ReportEnrichment struct {
 	Category    string `bson:"category" json:"category"`         // “flood”, “fire”, “accident”...
@@ -33,7 +99,21 @@ type ReportEnrichment struct {
 
 type ReportRepository interface {
 	Create(ctx context.Context, report *Report) error
+	// CreateOnce giống Create nhưng cho biết report có thực sự được insert hay đã tồn tại
+	CreateOnce(ctx context.Context, report *Report) (bool, error)
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Report, error)
+	UpdateEnrichment(ctx context.Context, id primitive.ObjectID, enrichment *ReportEnrichment, state string) error
+	// SetRiskApplied đổi risk_applied từ prev sang risk, false nếu giá trị trong DB đã khác prev
+	SetRiskApplied(ctx context.Context, id primitive.ObjectID, prev, risk float64) (bool, error)
+	// lấy ID các report chưa có kết quả AI thật (PENDING/FALLBACK hoặc chưa từng enrich)
+	FetchUnenrichedIDs(ctx context.Context) ([]primitive.ObjectID, error)
 	GetNearbyReports(ctx context.Context, lat, lon, km float64) ([]*Report, error)
+	// report còn hiệu lực trong bounding box, không kèm ảnh
+	FetchActiveInBounds(ctx context.Context, b BoundsFilter) ([]*Report, error)
+	// Update ghi nội dung + status, chỉ thành công nếu revision trong DB vẫn là expectedRevision
+	Update(ctx context.Context, report *Report, expectedRevision int) error
+	AddRevision(ctx context.Context, rev *ReportRevision) error
+	ListRevisions(ctx context.Context, reportID primitive.ObjectID) ([]ReportRevision, error)
 	// FetchByGroupID(ctx context.Context, groupID string) ([]Report, error)
 }
 
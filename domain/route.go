@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrRouteInput   = errors.New("provide origin and destination, or a polyline with at least 2 points")
+	ErrRouteTooLong = errors.New("route area is too large to assess")
+	ErrNoRoadGraph  = errors.New("road graph is not configured")
+	ErrRouteNoSnap  = errors.New("origin or destination is too far from the road network")
+	ErrNoRoute      = errors.New("no route found between origin and destination")
+	ErrNoSaferRoute = errors.New("no route avoids the high-risk areas")
+)
+
+// Nguồn của đường được đánh giá
+const (
+	RouteSourcePolyline  = "polyline"   // client gửi lên
+	RouteSourceRoadGraph = "road_graph" // tính trên đồ thị đường OSM
+	RouteSourceDirect    = "direct"     // không có đồ thị: nối thẳng origin -> destination
+)
+
+// Loại nguy hiểm mà đường đi qua
+const (
+	RouteHazardZone   = "zone"
+	RouteHazardCell   = "cell"
+	RouteHazardAlert  = "alert"
+	RouteHazardReport = "report" // report chặn đường (ngập, sạt lở, tai nạn, cháy)
+)
+
+type LatLon struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// RouteAssessRequest: gửi origin + destination, hoặc polyline (đã có sẵn đường từ app bản đồ)
+type RouteAssessRequest struct {
+	Origin      *LatLon  `json:"origin"`
+	Destination *LatLon  `json:"destination"`
+	Polyline    []LatLon `json:"polyline"`
+}
+
+// RouteStretch — đoạn liên tiếp cùng mức risk, tính theo mét dọc đường
+type RouteStretch struct {
+	FromM     float64 `json:"fromM"`
+	ToM       float64 `json:"toM"`
+	RiskScore float64 `json:"riskScore"`
+	Label     string  `json:"label"`
+	Blocked   bool    `json:"blocked,omitempty"`
+}
+
+// RouteHazard — một zone / cell / alert / report mà đường cắt qua
+type RouteHazard struct {
+	Kind      string  `json:"kind"`
+	ID        string  `json:"id"`
+	RiskScore float64 `json:"riskScore"`
+	Label     string  `json:"label"`
+	Detail    string  `json:"detail,omitempty"` // hazard của zone, loại report...
+	AtM       float64 `json:"atM"`              // vị trí đầu tiên gặp, mét từ điểm xuất phát
+	LengthM   float64 `json:"lengthM"`          // quãng đường nằm trong vùng nguy hiểm
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+}
+
+type RouteAssessment struct {
+	Source    string         `json:"source"`
+	Path      []LatLon       `json:"path"`
+	DistanceM float64        `json:"distanceM"`
+	MaxRisk   float64        `json:"maxRisk"`
+	Label     string         `json:"label"`   // label của MaxRisk
+	AvgRisk   float64        `json:"avgRisk"` // trung bình theo quãng đường
+	HighM     float64        `json:"highM"`   // số mét trong vùng HIGH
+	MediumM   float64        `json:"mediumM"`
+	Blocked   bool           `json:"blocked"` // đi qua report chặn đường
+	Stretches []RouteStretch `json:"stretches"`
+	Hazards   []RouteHazard  `json:"hazards"`
+}
+
+type RouteAssessResponse struct {
+	Route RouteAssessment `json:"route"`
+	// đường tránh vùng HIGH, chỉ có khi đường chính đi qua HIGH / bị chặn và có đồ thị đường
+	Alternative *RouteAssessment `json:"alternative,omitempty"`
+	// lý do không có đường tránh (không có đồ thị, không tìm được đường...)
+	AlternativeError string `json:"alternativeError,omitempty"`
+}
+
+type RouteUsecase interface {
+	Assess(ctx context.Context, req RouteAssessRequest) (*RouteAssessResponse, error)
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CollectionSharingSettings = "sharing_settings"
+
+// độ chính xác vị trí người khác nhận được
+const (
+	SharingExact  = "EXACT"
+	SharingApprox = "APPROX" // ~100m (cell geohash 7)
+	SharingArea   = "AREA"   // cỡ một khu phố (cell geohash 6)
+)
+
+var ErrInvalidSharing = errors.New("invalid sharing settings")
+
+// SharingSettings: user chia sẻ vị trí với nhóm nào, chính xác tới đâu.
+// Khi user có SOS đang mở thì bỏ qua giới hạn, mọi nhóm đều thấy vị trí chính xác.
+type SharingSettings struct {
+	UserID      string               `bson:"_id" json:"userId"`
+	GroupIDs    []primitive.ObjectID `bson:"groupIds" json:"groupIds"` // rỗng = mọi nhóm của user
+	Precision   string               `bson:"precision" json:"precision"`
+	PausedUntil int64                `bson:"pausedUntil" json:"pausedUntil"` // (s) 0 = đang chia sẻ
+	UpdatedAt   int64                `bson:"updatedAt" json:"updatedAt"`     // (s)
+}
+
+// DefaultSharing: chưa cấu hình thì chia sẻ chính xác với mọi nhóm
+func DefaultSharing(userID string) *SharingSettings {
+	return &SharingSettings{UserID: userID, GroupIDs: []primitive.ObjectID{}, Precision: SharingExact}
+}
+
+func (s *SharingSettings) Paused(now time.Time) bool {
+	return s.PausedUntil > now.Unix()
+}
+
+func ValidPrecision(p string) bool {
+	return p == SharingExact || p == SharingApprox || p == SharingArea
+}
+
+// SharingDecision: viewer có được xem vị trí của một user không và ở độ chính xác nào
+type SharingDecision struct {
+	Allowed   bool
+	Precision string
+	SOS       bool
+}
+
+// LocationAudience: những ai được nhận một fix vị trí và bản vị trí (đã làm mờ) gửi cho họ
+type LocationAudience struct {
+	Viewers  map[string]bool
+	Groups   map[string]primitive.ObjectID // viewer -> một nhóm chung đang được chia sẻ
+	Location *Location
+}
+
+type SharingRepository interface {
+	// Get trả về (nil, nil) nếu user chưa cấu hình
+	Get(ctx context.Context, userID string) (*SharingSettings, error)
+	// GetMany chỉ trả về user đã cấu hình
+	GetMany(ctx context.Context, userIDs []string) ([]SharingSettings, error)
+	Upsert(ctx context.Context, s *SharingSettings) error
+}
+
+// LocationSharing được WSManager dùng để lọc người nhận location
+type LocationSharing interface {
+	Audience(ctx context.Context, userID string, loc *Location) (*LocationAudience, error)
+}
+
+type SharingUsecase interface {
+	LocationSharing
+	Get(ctx context.Context, userID string) (*SharingSettings, error)
+	Update(ctx context.Context, userID string, groupIDs []primitive.ObjectID, precision string) (*SharingSettings, error)
+	Pause(ctx context.Context, userID string, d time.Duration) (*SharingSettings, error)
+	Resume(ctx context.Context, userID string) (*SharingSettings, error)
+	Decide(ctx context.Context, viewerID, userID string) (SharingDecision, error)
+	// DecideMany như Decide cho nhiều user, số query không phụ thuộc số user (key = user ID hex)
+	DecideMany(ctx context.Context, viewer User, users []User) (map[string]SharingDecision, error)
+}
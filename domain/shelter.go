@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CollectionShelter = "shelters"
+
+// Trạng thái điểm sơ tán
+const (
+	ShelterOpen   = "OPEN"
+	ShelterClosed = "CLOSED"
+)
+
+var (
+	ErrShelterNotFound = errors.New("shelter not found")
+	ErrInvalidShelter  = errors.New("shelter needs a name, a valid lat/lon, capacity > 0, 0 <= occupancy and status OPEN or CLOSED")
+)
+
+// Shelter — điểm sơ tán do coordinator quản lý
+type Shelter struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Address   string             `bson:"address,omitempty" json:"address,omitempty"`
+	Phone     string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	Location  GeoPoint           `bson:"location" json:"location"`
+	Capacity  int                `bson:"capacity" json:"capacity"`
+	Occupancy int                `bson:"occupancy" json:"occupancy"`
+	Amenities []string           `bson:"amenities,omitempty" json:"amenities,omitempty"` // "water", "food", "medical", "power"...
+	// "wheelchair", "elevator", "pets"...
+	Accessibility []string `bson:"accessibility,omitempty" json:"accessibility,omitempty"`
+	Status        string   `bson:"status" json:"status"`
+
+	UpdatedBy string `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt int64  `bson:"updated_at" json:"updated_at"` // timestamp (s)
+}
+
+// Available: số chỗ còn trống
+func (s Shelter) Available() int {
+	if s.Occupancy >= s.Capacity {
+		return 0
+	}
+	return s.Capacity - s.Occupancy
+}
+
+func (s Shelter) Valid() bool {
+	lat, lon := s.Location.Coordinates[1], s.Location.Coordinates[0]
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return false
+	}
+	return s.Name != "" && s.Capacity > 0 && s.Occupancy >= 0 && (s.Status == ShelterOpen || s.Status == ShelterClosed)
+}
+
+// Loại sự kiện shelter gửi qua WS ("shelter_event")
+const (
+	ShelterEventCreated   = "created"
+	ShelterEventUpdated   = "updated"
+	ShelterEventOccupancy = "occupancy"
+	ShelterEventDeleted   = "deleted"
+)
+
+type ShelterEvent struct {
+	Type      string  `json:"type"`
+	Shelter   Shelter `json:"shelter"`
+	Available int     `json:"available"`
+	Full      bool    `json:"full"`
+}
+
+// ShelterNotifier nhận thay đổi shelter để đẩy realtime
+type ShelterNotifier interface {
+	NotifyShelter(e ShelterEvent)
+}
+
+// ShelterQuery — tham số tìm shelter gần nhất
+type ShelterQuery struct {
+	Lat, Lon      float64
+	MaxKm         float64
+	Limit         int
+	IncludeFull   bool     // mặc định bỏ shelter đã đầy
+	Accessibility []string // shelter phải có đủ các mục này
+	Amenities     []string
+}
+
+// NearestShelter — shelter kèm khoảng cách tới người hỏi
+type NearestShelter struct {
+	Shelter
+	DistanceM float64 `json:"distanceM"`
+	Available int     `json:"available"`
+}
+
+type ShelterRepository interface {
+	Create(ctx context.Context, s *Shelter) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Shelter, error)
+	Update(ctx context.Context, s *Shelter) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	// AdjustOccupancy cộng delta vào occupancy (không xuống dưới 0) và trả shelter sau khi sửa
+	AdjustOccupancy(ctx context.Context, id primitive.ObjectID, delta int, updatedBy string, updatedAt int64) (*Shelter, error)
+	SetOccupancy(ctx context.Context, id primitive.ObjectID, occupancy int, updatedBy string, updatedAt int64) (*Shelter, error)
+	// FetchNearby: shelter OPEN trong bán kính, sắp theo khoảng cách tăng dần
+	FetchNearby(ctx context.Context, lat, lon, maxM float64, limit int) ([]Shelter, error)
+	FetchInBounds(ctx context.Context, b BoundsFilter) ([]Shelter, error)
+}
+
+type ShelterUsecase interface {
+	Create(ctx context.Context, s *Shelter) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Shelter, error)
+	Update(ctx context.Context, s *Shelter) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	AdjustOccupancy(ctx context.Context, id primitive.ObjectID, delta int, updatedBy string) (*Shelter, error)
+	SetOccupancy(ctx context.Context, id primitive.ObjectID, occupancy int, updatedBy string) (*Shelter, error)
+	FetchInBounds(ctx context.Context, b BoundsFilter) ([]Shelter, error)
+	// Nearest: shelter còn chỗ gần nhất, bỏ các shelter nằm trong zone HIGH
+	Nearest(ctx context.Context, q ShelterQuery) ([]NearestShelter, error)
+}
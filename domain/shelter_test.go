@@ -0,0 +1,25 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShelterAvailable(t *testing.T) {
+	assert.Equal(t, 30, domain.Shelter{Capacity: 100, Occupancy: 70}.Available())
+	// vượt sức chứa lúc khẩn cấp vẫn tính là hết chỗ
+	assert.Equal(t, 0, domain.Shelter{Capacity: 100, Occupancy: 120}.Available())
+}
+
+func TestShelterValid(t *testing.T) {
+	s := domain.Shelter{Name: "Trường THCS", Capacity: 200, Status: domain.ShelterOpen}
+	assert.True(t, s.Valid())
+
+	s.Status = "FULL"
+	assert.False(t, s.Valid())
+
+	s.Status, s.Capacity = domain.ShelterClosed, 0
+	assert.False(t, s.Valid())
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// các layer phục vụ qua GET /tiles/:layer/:z/:x/:y
+const (
+	TileLayerCells   = "cells"   // lưới risk (cell + zone chiếu lên lưới), level theo zoom
+	TileLayerZones   = "zones"   // polygon zone
+	TileLayerReports = "reports" // report OPEN, gom cụm theo zoom
+	TileLayerAlerts  = "alerts"  // alert còn hiệu lực, gom cụm theo zoom
+)
+
+const (
+	TileFormatMVT = "mvt" // Mapbox Vector Tile
+	TileFormatPNG = "png" // heatmap dự phòng cho client không vẽ được vector
+)
+
+var (
+	ErrUnknownTileLayer      = errors.New("unknown tile layer")
+	ErrInvalidTile           = errors.New("invalid tile coordinates")
+	ErrUnsupportedTileFormat = errors.New("unsupported tile format")
+)
+
+type Tile struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	MaxAge      time.Duration // Cache-Control cho client
+}
+
+// TileInvalidator xóa tile đã cache của các layer trong vùng có dữ liệu thay đổi
+type TileInvalidator interface {
+	InvalidateTiles(layers []string, b BoundsFilter)
+}
+
+type TileUsecase interface {
+	Tile(ctx context.Context, layer, format string, z, x, y int) (*Tile, error)
+}
@@ -10,6 +10,13 @@ const (
 	CollectionUser = "users"
 )
 
+// User.Role
+const (
+	RoleMember      = "member"
+	RoleCoordinator = "coordinator"
+	RoleAdmin       = "admin"
+)
+
 type User struct {
 	ID       primitive.ObjectID   `bson:"_id"`
 	Name     string               `bson:"name"`
@@ -24,5 +31,10 @@ type UserRepository interface {
 	Fetch(c context.Context) ([]User, error)
 	GetByPhone(c context.Context, phone string) (User, error)
 	GetByID(c context.Context, id string) (User, error)
+	// GetByIDs lấy nhiều user trong một query (không có password)
+	GetByIDs(c context.Context, ids []primitive.ObjectID) ([]User, error)
 	AddGroup(ctx context.Context, userID primitive.ObjectID, groupID primitive.ObjectID) error
+	RemoveGroup(ctx context.Context, userID primitive.ObjectID, groupID primitive.ObjectID) error
+	// RemoveGroupFromAll bỏ groupID khỏi mọi user (khi xóa nhóm)
+	RemoveGroupFromAll(ctx context.Context, groupID primitive.ObjectID) error
 }
@@ -2,12 +2,14 @@ package domain
 
 import (
 	"context"
+	"errors"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
-	CollectionZone = "zones"
+	CollectionZone        = "zones"
+	CollectionZoneLineage = "zone_lineage"
 )
 
 // Zone — khu vực cảnh báo dạng hình tròn
@@ -18,6 +20,81 @@ type Zone struct {
 	RiskScore float64            `bson:"riskScore" json:"riskScore"`
 	Label     string             `bson:"label" json:"label"`
 	UpdatedAt int64              `bson:"updatedAt" json:"updatedAt"`
+
+	// risk tại thời điểm UpdatedAt, RiskScore được decay từ giá trị này theo thời gian
+	BaseRisk float64 `bson:"baseRisk" json:"baseRisk"`
+	Hazard   string  `bson:"hazard,omitempty" json:"hazard,omitempty"`     // FLOOD | FIRE | ... quyết định half-life
+	Declared bool    `bson:"declared,omitempty" json:"declared,omitempty"` // zone do cơ quan chức năng công bố
+
+	// do coordinator / admin đặt qua PUT / PATCH /zones/:id
+	Override bool `bson:"override,omitempty" json:"override,omitempty"` // risk đặt tay, report không làm thay đổi
+	Locked   bool `bson:"locked,omitempty" json:"locked,omitempty"`     // không decay, không bị gộp / xóa tự động
+
+	// polygon bao ngoài vòng tròn, repository tự tính từ Center + Radius để query bằng index 2dsphere
+	Area *GeoPolygon `bson:"area,omitempty" json:"-"`
+}
+
+// GeoPolygon — GeoJSON Polygon, Coordinates[0] là ring ngoài [lon, lat] khép kín
+type GeoPolygon struct {
+	Type        string         `bson:"type" json:"type"` // "Polygon"
+	Coordinates [][][2]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+// Pinned: risk của zone do người điều phối giữ, các nguồn tự động (report) bỏ qua
+func (z Zone) Pinned() bool {
+	return z.Override || z.Locked
+}
+
+// ZoneRiskUpdate dùng cho cập nhật risk hàng loạt (decay)
+type ZoneRiskUpdate struct {
+	ID        primitive.ObjectID
+	RiskScore float64
+	Label     string
+
+	// giá trị trước khi decay, để ghi history
+	PrevRisk  float64
+	PrevLabel string
+	Center    GeoPoint
+	Radius    float64
+}
+
+var ErrZoneNotFound = errors.New("zone not found")
+
+// RiskLabel map riskScore (0..1) -> LOW | MEDIUM | HIGH
+func RiskLabel(risk float64) string {
+	switch {
+	case risk < 0.3:
+		return "LOW"
+	case risk < 0.6:
+		return "MEDIUM"
+	default:
+		return "HIGH"
+	}
+}
+
+// ZoneMerge là kết quả gộp: Zone thay thế cho các Sources
+type ZoneMerge struct {
+	Zone    Zone
+	Sources []Zone
+}
+
+// ZoneMergeConfig cấu hình gộp zone; Inline = gộp ngay khi AddRiskOrCreate tạo zone mới
+type ZoneMergeConfig struct {
+	Overlap   float64
+	MaxRadius float64
+	Inline    bool
+}
+
+// ZoneLineage ghi lại zone nào được gộp từ những zone nào.
+// Lineage được lưu trước khi xóa / tạo zone nên lần gộp bị ngắt giữa chừng có thể làm tiếp.
+type ZoneLineage struct {
+	ID        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	ZoneID    primitive.ObjectID   `bson:"zoneId" json:"zoneId"`       // zone mới
+	SourceIDs []primitive.ObjectID `bson:"sourceIds" json:"sourceIds"` // zone đã bị thay thế
+	Sources   []Zone               `bson:"sources" json:"sources"`     // snapshot lúc gộp
+	Zone      Zone                 `bson:"zone" json:"zone"`           // zone mới lúc gộp
+	Applied   bool                 `bson:"applied" json:"-"`           // đã xóa zone cũ và tạo zone mới
+	MergedAt  int64                `bson:"mergedAt" json:"mergedAt"`
 }
 
 // ============================
@@ -26,6 +103,7 @@ type Zone struct {
 
 type ZoneRepository interface {
 	Create(ctx context.Context, z *Zone) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Zone, error)
 	FetchAll(ctx context.Context) ([]Zone, error)
 
 	// lấy các zone trong bounding box (min/max lat/lon)
@@ -35,6 +113,17 @@ type ZoneRepository interface {
 	// tìm zone nào chứa lat/lon (lat/lon nằm trong vòng tròn)
 	FetchAllByLatLon(ctx context.Context, lat, lon float64) ([]Zone, error)
 	Update(ctx context.Context, z *Zone) error
+
+	// cập nhật riskScore + label hàng loạt, không đổi UpdatedAt / BaseRisk
+	BulkUpdateRisk(ctx context.Context, updates []ZoneRiskUpdate) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	DeleteMany(ctx context.Context, ids []primitive.ObjectID) error
+
+	AddLineage(ctx context.Context, l *ZoneLineage) error
+	// ApplyLineage xóa các zone nguồn, tạo zone mới (nếu chưa có) rồi đánh dấu applied; gọi lại nhiều lần vẫn an toàn
+	ApplyLineage(ctx context.Context, l *ZoneLineage) error
+	// FetchPendingLineages lấy các lần gộp chưa hoàn tất
+	FetchPendingLineages(ctx context.Context) ([]ZoneLineage, error)
 }
 
 // ============================
@@ -43,11 +132,18 @@ type ZoneRepository interface {
 
 type ZoneUsecase interface {
 	Create(ctx context.Context, z *Zone) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*Zone, error)
 	FetchAll(ctx context.Context) ([]Zone, error)
 	FetchInBounds(ctx context.Context, minLat, minLon, maxLat, maxLon float64) ([]Zone, error)
 	FetchAllByLatLon(ctx context.Context, lat, lon float64) ([]Zone, error)
 	AddRiskOrCreate(ctx context.Context, lat, lon, riskIncrement, defaultRadius float64) error
 	Update(ctx context.Context, z *Zone) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
-	SetMaxRisk(ctx context.Context, lat, lon, newRisk float64) error
+	SetMaxRisk(ctx context.Context, lat, lon, newRisk float64, hazard string) error
+	// RaiseRisk chỉ nâng risk lên newRisk, không cộng thêm khi zone đã cao hơn (nguồn đã được tính trước đó)
+	RaiseRisk(ctx context.Context, lat, lon, newRisk float64, hazard string) error
+	ApplyDecay(ctx context.Context, updates []ZoneRiskUpdate, removed []Zone) error
+
+	// gộp các zone chồng lấn quá ngưỡng overlap (0..1), trả về các lần gộp đã thực hiện
+	Coalesce(ctx context.Context, overlap, maxRadius float64) ([]ZoneMerge, error)
 }
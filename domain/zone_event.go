@@ -0,0 +1,75 @@
+package domain
+
+import "github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+
+// loại thay đổi zone gửi tới client đang xem vùng bản đồ
+const (
+	ZoneEventCreated = "created"
+	ZoneEventUpdated = "updated"
+	ZoneEventDecayed = "decayed"
+	ZoneEventMerged  = "merged"
+	ZoneEventDeleted = "deleted"
+)
+
+// ZoneEvent — một thay đổi của zone, Zone là trạng thái sau thay đổi
+// (với deleted là snapshot trước khi xóa)
+type ZoneEvent struct {
+	Type          string   `json:"type"`
+	Zone          Zone     `json:"zone"`
+	PreviousLabel string   `json:"previousLabel,omitempty"`
+	Replaced      []string `json:"replaced,omitempty"` // merged: ID các zone bị thay thế
+	Cause         string   `json:"cause,omitempty"`
+}
+
+// ZoneNotifier nhận thay đổi zone từ ZoneUsecase (WSManager đẩy tới viewport)
+type ZoneNotifier interface {
+	NotifyZone(e ZoneEvent)
+}
+
+// ZoneNotifiers gửi cùng một thay đổi tới nhiều notifier (WS + cache tile)
+type ZoneNotifiers []ZoneNotifier
+
+func (ns ZoneNotifiers) NotifyZone(e ZoneEvent) {
+	for _, n := range ns {
+		if n != nil {
+			n.NotifyZone(e)
+		}
+	}
+}
+
+// Viewport — vùng bản đồ client đang xem; MinLon > MaxLon nghĩa là vắt qua kinh tuyến 180
+type Viewport struct {
+	MinLat float64 `json:"minLat"`
+	MinLon float64 `json:"minLon"`
+	MaxLat float64 `json:"maxLat"`
+	MaxLon float64 `json:"maxLon"`
+}
+
+// IntersectsCircle kiểm tra vòng tròn (tâm lat/lon, bán kính m) có chạm viewport không,
+// dùng bounding box của vòng tròn nên có thể dư một chút ở góc
+func (v Viewport) IntersectsCircle(lat, lon, radiusM float64) bool {
+	dLat, dLon := geo.DegreesAround(lat, radiusM)
+	if lat+dLat < v.MinLat || lat-dLat > v.MaxLat {
+		return false
+	}
+	if dLon >= 180 {
+		return true // sát cực hoặc vòng quá lớn, coi như phủ mọi kinh độ
+	}
+
+	if v.MinLon <= v.MaxLon {
+		return lonRangesOverlap(lon-dLon, lon+dLon, v.MinLon, v.MaxLon)
+	}
+	// viewport vắt qua 180: tách thành 2 đoạn
+	return lonRangesOverlap(lon-dLon, lon+dLon, v.MinLon, 180) ||
+		lonRangesOverlap(lon-dLon, lon+dLon, -180, v.MaxLon)
+}
+
+// so 2 đoạn kinh độ, thử dịch ±360 để bắt trường hợp vòng tròn vắt qua 180
+func lonRangesOverlap(aMin, aMax, bMin, bMax float64) bool {
+	for _, shift := range [...]float64{0, 360, -360} {
+		if aMin+shift <= bMax && aMax+shift >= bMin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewportIntersectsCircle(t *testing.T) {
+	hcm := domain.Viewport{MinLat: 10.7, MinLon: 106.6, MaxLat: 10.9, MaxLon: 106.8}
+
+	assert.True(t, hcm.IntersectsCircle(10.8, 106.7, 100))
+	// tâm ngoài viewport nhưng bán kính chạm mép
+	assert.True(t, hcm.IntersectsCircle(10.8, 106.85, 6000))
+	assert.False(t, hcm.IntersectsCircle(10.8, 106.85, 1000))
+	assert.False(t, hcm.IntersectsCircle(21.0, 105.8, 5000))
+}
+
+func TestViewportIntersectsCircleAntimeridian(t *testing.T) {
+	fiji := domain.Viewport{MinLat: -20, MinLon: 175, MaxLat: -15, MaxLon: -175}
+
+	assert.True(t, fiji.IntersectsCircle(-18, 179, 1000))
+	assert.True(t, fiji.IntersectsCircle(-18, -178, 1000))
+	assert.False(t, fiji.IntersectsCircle(-18, 170, 1000))
+
+	// vòng tròn vắt qua 180 với viewport thường
+	east := domain.Viewport{MinLat: -20, MinLon: -180, MaxLat: -15, MaxLon: -179.9}
+	assert.True(t, east.IntersectsCircle(-18, 179.99, 5000))
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CollectionZoneHistory = "zone_risk_history"
+
+// nguyên nhân làm risk của zone thay đổi
+const (
+	ZoneCauseReport = "REPORT"
+	ZoneCauseAlert  = "ALERT"
+	ZoneCauseDecay  = "DECAY"
+	ZoneCauseMerge  = "MERGE"
+	ZoneCauseManual = "MANUAL"
+)
+
+// ZoneCause gắn vào context trước khi gọi ZoneUsecase để ghi vào history
+type ZoneCause struct {
+	Type  string
+	RefID string // report ID, alert ID, ...
+}
+
+type zoneCauseKey struct{}
+
+func WithZoneCause(ctx context.Context, cause ZoneCause) context.Context {
+	return context.WithValue(ctx, zoneCauseKey{}, cause)
+}
+
+// ZoneCauseFrom lấy cause trong context, không có thì coi là thay đổi thủ công
+func ZoneCauseFrom(ctx context.Context) ZoneCause {
+	if c, ok := ctx.Value(zoneCauseKey{}).(ZoneCause); ok {
+		return c
+	}
+	return ZoneCause{Type: ZoneCauseManual}
+}
+
+// ZoneRiskPoint là một điểm trong chuỗi thời gian risk của zone
+type ZoneRiskPoint struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ZoneID    primitive.ObjectID `bson:"zoneId" json:"zoneId"`
+	Time      time.Time          `bson:"time" json:"time"`
+	RiskScore float64            `bson:"riskScore" json:"riskScore"`
+	Label     string             `bson:"label" json:"label"`
+	PrevRisk  float64            `bson:"prevRisk" json:"prevRisk"`
+	PrevLabel string             `bson:"prevLabel,omitempty" json:"prevLabel,omitempty"`
+	Cause     string             `bson:"cause" json:"cause"`
+	RefID     string             `bson:"refId,omitempty" json:"refId,omitempty"`
+	Center    GeoPoint           `bson:"center" json:"center"` // tâm zone lúc ghi, dùng cho thống kê theo khu vực
+	Removed   bool               `bson:"removed,omitempty" json:"removed,omitempty"`
+}
+
+// ZoneRiskBucket là số liệu tổng hợp của một khoảng thời gian trong một khu vực
+type ZoneRiskBucket struct {
+	Start     time.Time `bson:"start" json:"start"`
+	MaxRisk   float64   `bson:"maxRisk" json:"maxRisk"`
+	AvgRisk   float64   `bson:"avgRisk" json:"avgRisk"`
+	Zones     int       `bson:"zones" json:"zones"`         // số zone có thay đổi
+	HighZones int       `bson:"highZones" json:"highZones"` // số zone từng ở mức HIGH
+	Changes   int       `bson:"changes" json:"changes"`
+}
+
+type ZoneHistoryRepository interface {
+	Add(ctx context.Context, points ...ZoneRiskPoint) error
+	ListByZone(ctx context.Context, zoneID primitive.ObjectID, from, to time.Time) ([]ZoneRiskPoint, error)
+	AggregateInBounds(ctx context.Context, b BoundsFilter, from, to time.Time, bucket time.Duration) ([]ZoneRiskBucket, error)
+	// xóa điểm cũ hơn before (retention)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+type ZoneHistoryUsecase interface {
+	History(ctx context.Context, zoneID primitive.ObjectID, from, to time.Time) ([]ZoneRiskPoint, error)
+	Aggregate(ctx context.Context, b BoundsFilter, from, to time.Time, bucket time.Duration) ([]ZoneRiskBucket, error)
+}
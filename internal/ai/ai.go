@@ -1,5 +1,7 @@
 package ai
 
+import "github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/textnorm"
+
 type AIResult struct {
 	Category   string `json:"category"`
 	Urgency    string `json:"urgency"`
@@ -14,25 +16,13 @@ func AnalyzeReport(text string) (*AIResult, error) {
 	}
 
 	// fake rules
-	if contains(text, "lụt") || contains(text, "ngập") {
+	t := textnorm.NewText(text)
+	if textnorm.Hazards.Has(t, textnorm.HazardFlood) {
 		return &AIResult{Category: "FLOOD", Urgency: "HIGH", Summary: "Flood reported", Confidence: 95}, nil
 	}
-	if contains(text, "cháy") {
+	if textnorm.Hazards.Has(t, textnorm.HazardFire) {
 		return &AIResult{Category: "FIRE", Urgency: "MEDIUM", Summary: "Fire reported", Confidence: 90}, nil
 	}
 
 	return &AIResult{Category: "OTHER", Urgency: "LOW", Summary: "Other incident", Confidence: 50}, nil
 }
-
-func contains(s, sub string) bool {
-	return len(s) >= len(sub) && stringIndex(s, sub) >= 0
-}
-
-func stringIndex(a, b string) int {
-	for i := 0; i+len(b) <= len(a); i++ {
-		if a[i:i+len(b)] == b {
-			return i
-		}
-	}
-	return -1
-}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// csvWriter ghi mỗi feature một dòng: id, lon, lat, name, rồi tới các column.
+// Polygon chỉ ghi tâm, các thông tin như bán kính nằm trong column.
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+	rows    int
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Begin(layer string, columns []string) error {
+	c.columns = columns
+	header := append([]string{"id", "lon", "lat", "name"}, columns...)
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) Write(f Feature) error {
+	row := make([]string, 0, len(c.columns)+4)
+	row = append(row,
+		f.ID,
+		strconv.FormatFloat(f.Center[0], 'f', -1, 64),
+		strconv.FormatFloat(f.Center[1], 'f', -1, 64),
+		f.Name,
+	)
+	for i := range c.columns {
+		var v interface{}
+		if i < len(f.Values) {
+			v = f.Values[i]
+		}
+		row = append(row, formatValue(v))
+	}
+	if err := c.w.Write(row); err != nil {
+		return err
+	}
+
+	// flush định kỳ để dữ liệu được stream ra thay vì giữ trong buffer
+	c.rows++
+	if c.rows%500 == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvWriter) End() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,58 @@
+// Package export ghi các lớp dữ liệu bản đồ (report, alert, zone, cell) ra
+// GeoJSON, KML hoặc CSV theo kiểu stream: mỗi feature được ghi ngay khi đọc từ DB.
+package export
+
+import (
+	"errors"
+	"io"
+)
+
+const (
+	FormatGeoJSON = "geojson"
+	FormatKML     = "kml"
+	FormatCSV     = "csv"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Feature là một đối tượng trên bản đồ.
+// Ring != nil thì geometry là polygon (vd zone hình tròn), ngược lại là Point tại Center.
+type Feature struct {
+	ID     string
+	Name   string
+	Center [2]float64    // [lon, lat]
+	Ring   [][2]float64  // polygon đã đóng (điểm đầu = điểm cuối)
+	Values []interface{} // cùng thứ tự với columns truyền vào Begin
+}
+
+// Writer ghi một layer: Begin -> Write nhiều lần -> End
+type Writer interface {
+	Begin(layer string, columns []string) error
+	Write(f Feature) error
+	End() error
+}
+
+// NewWriter tạo writer theo format
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatGeoJSON, "":
+		return &geoJSONWriter{w: w}, nil
+	case FormatKML:
+		return &kmlWriter{w: w}, nil
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ContentType trả về MIME type và đuôi file của format
+func ContentType(format string) (mime string, ext string) {
+	switch format {
+	case FormatKML:
+		return "application/vnd.google-earth.kml+xml", "kml"
+	case FormatCSV:
+		return "text/csv; charset=utf-8", "csv"
+	default:
+		return "application/geo+json", "geojson"
+	}
+}
@@ -0,0 +1,78 @@
+package export_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/export"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeLayer(t *testing.T, format string, features ...export.Feature) string {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf)
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Begin("zones", []string{"label", "risk_score"}))
+	for _, f := range features {
+		assert.NoError(t, w.Write(f))
+	}
+	assert.NoError(t, w.End())
+	return buf.String()
+}
+
+func TestGeoJSON(t *testing.T) {
+	point := export.Feature{ID: "a", Center: [2]float64{106.7, 10.8}, Values: []interface{}{"HIGH", 0.9}}
+	circle := export.Feature{ID: "b", Center: [2]float64{106.7, 10.8}, Ring: geo.CircleRing(106.7, 10.8, 500, 16), Values: []interface{}{"LOW", 0.1}}
+
+	out := writeLayer(t, export.FormatGeoJSON, point, circle)
+
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			ID       string `json:"id"`
+			Geometry struct {
+				Type string `json:"type"`
+			} `json:"geometry"`
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(out), &fc))
+	assert.Equal(t, "FeatureCollection", fc.Type)
+	assert.Len(t, fc.Features, 2)
+	assert.Equal(t, "Point", fc.Features[0].Geometry.Type)
+	assert.Equal(t, "HIGH", fc.Features[0].Properties["label"])
+	assert.Equal(t, "Polygon", fc.Features[1].Geometry.Type)
+}
+
+func TestEmptyGeoJSON(t *testing.T) {
+	out := writeLayer(t, export.FormatGeoJSON)
+	assert.Equal(t, `{"type":"FeatureCollection","name":"zones","features":[]}`, out)
+}
+
+func TestCSV(t *testing.T) {
+	out := writeLayer(t, export.FormatCSV,
+		export.Feature{ID: "a", Center: [2]float64{106.7, 10.8}, Values: []interface{}{"HIGH, flooded", nil}},
+	)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	assert.Equal(t, "id,lon,lat,name,label,risk_score", lines[0])
+	assert.Equal(t, `a,106.7,10.8,,"HIGH, flooded",`, lines[1])
+}
+
+func TestKMLEscapes(t *testing.T) {
+	out := writeLayer(t, export.FormatKML,
+		export.Feature{ID: "a", Name: "Cây đổ <đường>", Center: [2]float64{106.7, 10.8}, Values: []interface{}{"HIGH", 0.9}},
+	)
+
+	assert.Contains(t, out, "<name>Cây đổ &lt;đường&gt;</name>")
+	assert.Contains(t, out, "<coordinates>106.7,10.8</coordinates>")
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := export.NewWriter("shp", &bytes.Buffer{})
+	assert.ErrorIs(t, err, export.ErrUnsupportedFormat)
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type geoJSONWriter struct {
+	w       io.Writer
+	columns []string
+	count   int
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+func (g *geoJSONWriter) Begin(layer string, columns []string) error {
+	g.columns = columns
+	name, _ := json.Marshal(layer)
+	_, err := io.WriteString(g.w, `{"type":"FeatureCollection","name":`+string(name)+`,"features":[`)
+	return err
+}
+
+func (g *geoJSONWriter) Write(f Feature) error {
+	geom := geoJSONGeometry{Type: "Point", Coordinates: f.Center}
+	if f.Ring != nil {
+		geom = geoJSONGeometry{Type: "Polygon", Coordinates: [][][2]float64{f.Ring}}
+	}
+
+	props := make(map[string]interface{}, len(g.columns)+1)
+	if f.Name != "" {
+		props["name"] = f.Name
+	}
+	for i, col := range g.columns {
+		if i < len(f.Values) {
+			props[col] = f.Values[i]
+		}
+	}
+
+	data, err := json.Marshal(geoJSONFeature{
+		Type:       "Feature",
+		ID:         f.ID,
+		Geometry:   geom,
+		Properties: props,
+	})
+	if err != nil {
+		return err
+	}
+
+	if g.count > 0 {
+		if _, err := io.WriteString(g.w, ","); err != nil {
+			return err
+		}
+	}
+	g.count++
+	_, err = g.w.Write(data)
+	return err
+}
+
+func (g *geoJSONWriter) End() error {
+	_, err := io.WriteString(g.w, "]}")
+	return err
+}
@@ -0,0 +1,89 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type kmlWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (k *kmlWriter) Begin(layer string, columns []string) error {
+	k.columns = columns
+	_, err := io.WriteString(k.w, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+		`<kml xmlns="http://www.opengis.net/kml/2.2"><Document><name>`+escapeXML(layer)+`</name>`+"\n")
+	return err
+}
+
+func (k *kmlWriter) Write(f Feature) error {
+	var b strings.Builder
+
+	b.WriteString("<Placemark")
+	if f.ID != "" {
+		b.WriteString(` id="` + escapeXML(f.ID) + `"`)
+	}
+	b.WriteString(">")
+	if f.Name != "" {
+		b.WriteString("<name>" + escapeXML(f.Name) + "</name>")
+	}
+
+	b.WriteString("<ExtendedData>")
+	for i, col := range k.columns {
+		if i >= len(f.Values) {
+			break
+		}
+		b.WriteString(`<Data name="` + escapeXML(col) + `"><value>` + escapeXML(formatValue(f.Values[i])) + "</value></Data>")
+	}
+	b.WriteString("</ExtendedData>")
+
+	if f.Ring != nil {
+		b.WriteString("<Polygon><outerBoundaryIs><LinearRing><coordinates>")
+		for i, p := range f.Ring {
+			if i > 0 {
+				b.WriteString(" ")
+			}
+			b.WriteString(formatCoord(p))
+		}
+		b.WriteString("</coordinates></LinearRing></outerBoundaryIs></Polygon>")
+	} else {
+		b.WriteString("<Point><coordinates>" + formatCoord(f.Center) + "</coordinates></Point>")
+	}
+	b.WriteString("</Placemark>\n")
+
+	_, err := io.WriteString(k.w, b.String())
+	return err
+}
+
+func (k *kmlWriter) End() error {
+	_, err := io.WriteString(k.w, "</Document></kml>\n")
+	return err
+}
+
+func formatCoord(p [2]float64) string {
+	return strconv.FormatFloat(p[0], 'f', -1, 64) + "," + strconv.FormatFloat(p[1], 'f', -1, 64)
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// formatValue chuyển giá trị sang text cho KML / CSV, nil -> ""
+func formatValue(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprint(t)
+	}
+}
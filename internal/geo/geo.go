@@ -0,0 +1,143 @@
+// Package geo gồm các phép tính hình học trên mặt cầu dùng chung cho query và export
+package geo
+
+import "math"
+
+const (
+	EarthRadiusM    = 6371000.0
+	MetersPerDegree = 111320.0 // số mét trên 1 độ vĩ, dùng để tính khung bao
+)
+
+// DistanceMeters — khoảng cách Haversine giữa 2 điểm (mét)
+func DistanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	latRad1 := lat1 * math.Pi / 180
+	latRad2 := lat2 * math.Pi / 180
+	dLat := (lat2 - lat1) * math.Pi / 180
+	dLon := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(latRad1)*math.Cos(latRad2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return EarthRadiusM * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// DegreesAround đổi khoảng cách (m) quanh vĩ độ lat sang độ vĩ / độ kinh; sát cực dLon = 180 (phủ mọi kinh độ)
+func DegreesAround(lat, meters float64) (dLat, dLon float64) {
+	dLat = meters / MetersPerDegree
+	dLon = 180
+	if cos := math.Cos(lat * math.Pi / 180); cos > 1e-6 {
+		dLon = math.Min(180, dLat/cos)
+	}
+	return dLat, dLon
+}
+
+// BoundsAround trả về khung bao vòng tròn (tâm lat/lon, bán kính m). Vĩ độ kẹp trong [-90, 90],
+// kinh độ để nguyên (có thể vượt ±180), BoxRings chuẩn hóa khi query
+func BoundsAround(lat, lon, meters float64) (minLat, minLon, maxLat, maxLon float64) {
+	dLat, dLon := DegreesAround(lat, meters)
+	return math.Max(-90, lat-dLat), lon - dLon, math.Min(90, lat+dLat), lon + dLon
+}
+
+// CircleRing xấp xỉ vòng tròn (tâm lon/lat, bán kính mét) bằng polygon có segments cạnh
+func CircleRing(lon, lat, radiusM float64, segments int) [][2]float64 {
+	if segments < 8 {
+		segments = 8
+	}
+	latR := lat * math.Pi / 180
+	lonR := lon * math.Pi / 180
+	d := radiusM / EarthRadiusM
+
+	ring := make([][2]float64, 0, segments+1)
+	for i := 0; i < segments; i++ {
+		bearing := 2 * math.Pi * float64(i) / float64(segments)
+		pLat := math.Asin(math.Sin(latR)*math.Cos(d) + math.Cos(latR)*math.Sin(d)*math.Cos(bearing))
+		pLon := lonR + math.Atan2(math.Sin(bearing)*math.Sin(d)*math.Cos(latR), math.Cos(d)-math.Sin(latR)*math.Sin(pLat))
+		ring = append(ring, [2]float64{NormalizeLon(pLon * 180 / math.Pi), pLat * 180 / math.Pi})
+	}
+	return append(ring, ring[0])
+}
+
+// CoverRing giống CircleRing nhưng polygon bao ngoài vòng tròn (đỉnh đẩy ra xa hơn bán kính),
+// dùng làm hình lưu trong DB để query $geoIntersects không bỏ sót phần rìa
+func CoverRing(lon, lat, radiusM float64, segments int) [][2]float64 {
+	if segments < 8 {
+		segments = 8
+	}
+	return CircleRing(lon, lat, radiusM/math.Cos(math.Pi/float64(segments)), segments)
+}
+
+// NormalizeLon đưa kinh độ về [-180, 180]
+func NormalizeLon(lon float64) float64 {
+	for lon > 180 {
+		lon -= 360
+	}
+	for lon < -180 {
+		lon += 360
+	}
+	return lon
+}
+
+const (
+	maxPolarLat = 89.9999 // đỉnh polygon không được trùng nhau ở cực
+	minBoxSpan  = 1e-6    // box suy biến (1 điểm / 1 đường) nới ra một chút
+	maxBoxWidth = 90.0    // polygon GeoJSON phải nhỏ hơn nửa mặt cầu
+	edgeStep    = 1.0     // chia cạnh theo vĩ tuyến mỗi 1° để cạnh geodesic không lệch
+)
+
+// BoxRings chuyển bounding box thành các polygon GeoJSON dùng được với $geoIntersects.
+// MongoDB coi cạnh polygon là cung geodesic nên cạnh theo vĩ tuyến được chia nhỏ;
+// minLon > maxLon là box vắt qua kinh tuyến 180; box rộng được tách thành nhiều mảnh.
+func BoxRings(minLat, minLon, maxLat, maxLon float64) [][][2]float64 {
+	minLat = math.Max(minLat, -maxPolarLat)
+	maxLat = math.Min(maxLat, maxPolarLat)
+	if maxLat-minLat < minBoxSpan {
+		mid := (minLat + maxLat) / 2
+		minLat, maxLat = mid-minBoxSpan, mid+minBoxSpan
+	}
+
+	if maxLon-minLon >= 360 {
+		minLon, maxLon = -180, 180
+	} else {
+		minLon, maxLon = NormalizeLon(minLon), NormalizeLon(maxLon)
+		if minLon > maxLon {
+			// vắt qua 180: trải ra thành đoạn liên tục, đỉnh được chuẩn hóa lại khi tạo ring
+			maxLon += 360
+		}
+	}
+	if maxLon-minLon < minBoxSpan {
+		mid := (minLon + maxLon) / 2
+		minLon, maxLon = mid-minBoxSpan, mid+minBoxSpan
+	}
+
+	var rings [][][2]float64
+	for start := minLon; start < maxLon; start += maxBoxWidth {
+		end := math.Min(start+maxBoxWidth, maxLon)
+		rings = append(rings, boxRing(minLat, start, maxLat, end))
+	}
+	return rings
+}
+
+func boxRing(minLat, minLon, maxLat, maxLon float64) [][2]float64 {
+	steps := int(math.Ceil((maxLon - minLon) / edgeStep))
+	if steps < 1 {
+		steps = 1
+	}
+
+	ring := make([][2]float64, 0, 2*steps+3)
+	// cạnh dưới: tây -> đông
+	for i := 0; i <= steps; i++ {
+		ring = append(ring, [2]float64{wrapLon(minLon + (maxLon-minLon)*float64(i)/float64(steps)), minLat})
+	}
+	// cạnh trên: đông -> tây
+	for i := steps; i >= 0; i-- {
+		ring = append(ring, [2]float64{wrapLon(minLon + (maxLon-minLon)*float64(i)/float64(steps)), maxLat})
+	}
+	return append(ring, ring[0])
+}
+
+// wrapLon giống NormalizeLon nhưng giữ 180 ở mép đông (đỉnh -180/180 là cùng một điểm)
+func wrapLon(lon float64) float64 {
+	if lon > 180 {
+		return lon - 360
+	}
+	return lon
+}
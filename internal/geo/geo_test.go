@@ -0,0 +1,73 @@
+package geo_test
+
+import (
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircleRingClosed(t *testing.T) {
+	ring := geo.CircleRing(179.999, 0, 1000, 32)
+	assert.Len(t, ring, 33)
+	assert.Equal(t, ring[0], ring[len(ring)-1])
+	for _, p := range ring {
+		assert.True(t, p[0] >= -180 && p[0] <= 180)
+	}
+}
+
+func TestCoverRingContainsCircle(t *testing.T) {
+	// trung điểm mỗi cạnh polygon bao ngoài phải nằm ngoài (hoặc trên) vòng tròn
+	ring := geo.CoverRing(106.7, 10.8, 1000, 16)
+	for i := 0; i < len(ring)-1; i++ {
+		midLon := (ring[i][0] + ring[i+1][0]) / 2
+		midLat := (ring[i][1] + ring[i+1][1]) / 2
+		assert.GreaterOrEqual(t, geo.DistanceMeters(10.8, 106.7, midLat, midLon), 999.0)
+	}
+}
+
+func TestBoxRings(t *testing.T) {
+	t.Run("simple box", func(t *testing.T) {
+		rings := geo.BoxRings(10, 106, 11, 107)
+		assert.Len(t, rings, 1)
+		assert.Equal(t, rings[0][0], rings[0][len(rings[0])-1])
+	})
+
+	t.Run("antimeridian", func(t *testing.T) {
+		rings := geo.BoxRings(-20, 175, -15, -175)
+		assert.Len(t, rings, 1)
+		for _, p := range rings[0] {
+			assert.True(t, p[0] >= 175 || p[0] <= -175, "lon %v", p[0])
+		}
+	})
+
+	t.Run("whole world is split", func(t *testing.T) {
+		rings := geo.BoxRings(-90, -180, 90, 180)
+		assert.Len(t, rings, 4)
+		for _, r := range rings {
+			for _, p := range r {
+				assert.True(t, p[1] > -90 && p[1] < 90)
+			}
+		}
+	})
+
+	t.Run("degenerate box", func(t *testing.T) {
+		rings := geo.BoxRings(10, 106, 10, 106)
+		assert.Len(t, rings, 1)
+		assert.NotEqual(t, rings[0][0], rings[0][len(rings[0])/2])
+	})
+}
+
+func TestBoundsAround(t *testing.T) {
+	minLat, minLon, maxLat, maxLon := geo.BoundsAround(10.8, 106.7, 1000)
+	// cạnh khung cách tâm đúng bán kính theo cả 2 trục
+	assert.InDelta(t, 1000, geo.DistanceMeters(10.8, 106.7, maxLat, 106.7), 5)
+	assert.InDelta(t, 1000, geo.DistanceMeters(10.8, 106.7, 10.8, maxLon), 5)
+	assert.InDelta(t, 10.8, (minLat+maxLat)/2, 1e-9)
+	assert.InDelta(t, 106.7, (minLon+maxLon)/2, 1e-9)
+
+	// sát cực: phủ mọi kinh độ, vĩ độ không vượt 90
+	_, minLon, maxLat, maxLon = geo.BoundsAround(89.9999, 0, 1000)
+	assert.Equal(t, 90.0, maxLat)
+	assert.Equal(t, 360.0, maxLon-minLon)
+}
@@ -0,0 +1,157 @@
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+// Lưới geohash: mỗi ký tự thêm 5 bit, cell cha của một hash là hash bỏ ký tự cuối.
+// Precision 7 ≈ 153m x 153m ở xích đạo, 5 ≈ 4.9km, 3 ≈ 156km.
+const (
+	MaxPrecision = 12
+	base32       = "0123456789bcdefghjkmnpqrstuvwxyz"
+)
+
+// Encode trả về geohash của điểm ở precision ký tự
+func Encode(lat, lon float64, precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+	if precision > MaxPrecision {
+		precision = MaxPrecision
+	}
+	lon = NormalizeLon(lon)
+	lat = math.Max(-90, math.Min(90, lat))
+
+	latLo, latHi := -90.0, 90.0
+	lonLo, lonHi := -180.0, 180.0
+
+	var sb strings.Builder
+	sb.Grow(precision)
+	even := true // bit chẵn chia kinh độ
+	ch, bit := 0, 0
+	for sb.Len() < precision {
+		if even {
+			mid := (lonLo + lonHi) / 2
+			if lon >= mid {
+				ch = ch<<1 | 1
+				lonLo = mid
+			} else {
+				ch <<= 1
+				lonHi = mid
+			}
+		} else {
+			mid := (latLo + latHi) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				latLo = mid
+			} else {
+				ch <<= 1
+				latHi = mid
+			}
+		}
+		even = !even
+
+		if bit++; bit == 5 {
+			sb.WriteByte(base32[ch])
+			ch, bit = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// Bounds trả về khung của cell geohash; ok = false nếu hash có ký tự không hợp lệ
+func Bounds(hash string) (minLat, minLon, maxLat, maxLon float64, ok bool) {
+	minLat, maxLat = -90, 90
+	minLon, maxLon = -180, 180
+	even := true
+	for i := 0; i < len(hash); i++ {
+		idx := strings.IndexByte(base32, hash[i])
+		if idx < 0 {
+			return 0, 0, 0, 0, false
+		}
+		for b := 4; b >= 0; b-- {
+			on := idx>>b&1 == 1
+			if even {
+				mid := (minLon + maxLon) / 2
+				if on {
+					minLon = mid
+				} else {
+					maxLon = mid
+				}
+			} else {
+				mid := (minLat + maxLat) / 2
+				if on {
+					minLat = mid
+				} else {
+					maxLat = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return minLat, minLon, maxLat, maxLon, true
+}
+
+// CellCenter trả về tâm cell dạng [lon, lat]
+func CellCenter(hash string) [2]float64 {
+	minLat, minLon, maxLat, maxLon, _ := Bounds(hash)
+	return [2]float64{(minLon + maxLon) / 2, (minLat + maxLat) / 2}
+}
+
+// CellSize trả về kích thước cell (độ lat, độ lon) ở precision
+func CellSize(precision int) (latDeg, lonDeg float64) {
+	bits := 5 * precision
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lonBits))
+}
+
+// CountBoxCells ước lượng số cell ở precision phủ bounding box (để chặn query quá lớn)
+func CountBoxCells(minLat, minLon, maxLat, maxLon float64, precision int) float64 {
+	latDeg, lonDeg := CellSize(precision)
+	width := maxLon - minLon
+	if width < 0 {
+		width += 360
+	}
+	return math.Ceil((maxLat-minLat)/latDeg+1) * math.Ceil(width/lonDeg+1)
+}
+
+// CoverCircle trả về các cell ở precision có phần chạm vào vòng tròn (tâm lat/lon, bán kính mét)
+func CoverCircle(lat, lon, radiusM float64, precision int) []string {
+	latDeg, lonDeg := CellSize(precision)
+	dLat, dLon := DegreesAround(lat, radiusM)
+
+	seen := map[string]bool{}
+	var out []string
+	// quét theo tâm các cell trong khung bao vòng tròn, nới thêm 1 cell mỗi phía
+	startLat := math.Max(-90, lat-dLat-latDeg)
+	endLat := math.Min(90, lat+dLat+latDeg)
+	for y := startLat; y <= endLat; y += latDeg {
+		for x := lon - dLon - lonDeg; x <= lon+dLon+lonDeg; x += lonDeg {
+			h := Encode(y, x, precision)
+			if seen[h] {
+				continue
+			}
+			seen[h] = true
+			if cellDistance(h, lat, lon) <= radiusM {
+				out = append(out, h)
+			}
+		}
+	}
+	return out
+}
+
+// cellDistance — khoảng cách (m) từ điểm tới điểm gần nhất trong cell
+func cellDistance(hash string, lat, lon float64) float64 {
+	minLat, minLon, maxLat, maxLon, _ := Bounds(hash)
+	nLat := math.Max(minLat, math.Min(maxLat, lat))
+
+	// kinh độ so theo hướng ngắn nhất để đúng cả khi vắt qua 180
+	center := (minLon + maxLon) / 2
+	half := (maxLon - minLon) / 2
+	diff := NormalizeLon(lon - center)
+	nLon := center + math.Max(-half, math.Min(half, diff))
+
+	return DistanceMeters(lat, lon, nLat, nLon)
+}
@@ -0,0 +1,52 @@
+package geo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	// giá trị chuẩn của geohash
+	assert.Equal(t, "u4pruydqqvj", geo.Encode(57.64911, 10.40744, 11))
+	// cell cha là prefix của cell con
+	assert.True(t, strings.HasPrefix(geo.Encode(10.7769, 106.7009, 7), geo.Encode(10.7769, 106.7009, 4)))
+}
+
+func TestBoundsContainPoint(t *testing.T) {
+	h := geo.Encode(10.7769, 106.7009, 7)
+	minLat, minLon, maxLat, maxLon, ok := geo.Bounds(h)
+	assert.True(t, ok)
+	assert.True(t, minLat <= 10.7769 && 10.7769 <= maxLat)
+	assert.True(t, minLon <= 106.7009 && 106.7009 <= maxLon)
+
+	_, _, _, _, ok = geo.Bounds("a") // 'a' không có trong bảng geohash
+	assert.False(t, ok)
+}
+
+func TestCoverCircle(t *testing.T) {
+	center := geo.Encode(10.7769, 106.7009, 7)
+
+	small := geo.CoverCircle(10.7769, 106.7009, 10, 7)
+	assert.Contains(t, small, center)
+	assert.LessOrEqual(t, len(small), 4)
+
+	// 500m với cell ~150m: khoảng π·500² / 150² ≈ 35 cell
+	big := geo.CoverCircle(10.7769, 106.7009, 500, 7)
+	assert.Contains(t, big, center)
+	assert.Greater(t, len(big), 25)
+	assert.Less(t, len(big), 80)
+}
+
+func TestCoverCircleAntimeridian(t *testing.T) {
+	cells := geo.CoverCircle(0, 179.9995, 500, 7)
+	east, west := false, false
+	for _, h := range cells {
+		c := geo.CellCenter(h)
+		east = east || c[0] > 0
+		west = west || c[0] < 0
+	}
+	assert.True(t, east && west)
+}
@@ -4,23 +4,30 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/realtime"
 	"github.com/gin-gonic/gin"
 )
 
 type PresenceHandler struct {
-	AI aiPresenceClient
-	RT realtime.Broadcaster
+	AI          aiPresenceClient
+	Locations   presenceLocations
+	Broadcaster realtime.Broadcaster
+}
+
+// presenceLocations: lưu location, trail, geofence như WS; LocationUseCase đã cài sẵn
+type presenceLocations interface {
+	Save(ctx context.Context, userID string, loc *domain.Location) error
 }
 
 type aiPresenceClient interface {
 	PresenceUpdate(ctx context.Context, lat, lon, acc *float64, status string) (displayUntil string, err error)
 }
 
+// Lat / Lon là con trỏ để 0 vẫn là toạ độ hợp lệ
 type PresenceUpdateRequest struct {
-	UserID    string   `json:"userId" binding:"required"`
-	Lat       float64  `json:"lat" binding:"required"`
-	Lon       float64  `json:"lon" binding:"required"`
+	Lat       *float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Lon       *float64 `json:"lon" binding:"required,min=-180,max=180"`
 	AccuracyM *float64 `json:"accuracy_m"`
 	Status    string   `json:"status" binding:"oneof=SAFE CAUTION DANGER UNKNOWN"`
 }
@@ -31,15 +38,26 @@ func (h *PresenceHandler) Update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	displayUntil, err := h.AI.PresenceUpdate(c.Request.Context(), &in.Lat, &in.Lon, in.AccuracyM, in.Status)
+	userID := c.GetString("x-user-id") // lấy từ token, không tin userId trong body
+
+	displayUntil, err := h.AI.PresenceUpdate(c.Request.Context(), in.Lat, in.Lon, in.AccuracyM, in.Status)
 	if err != nil {
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Broadcast cho room/phạm vi group bạn đang active
-	if h.RT != nil {
-		h.RT.BroadcastPresence(in.UserID, in.Lat, in.Lon, in.Status, displayUntil)
+	loc := &domain.Location{
+		Status:   in.Status,
+		Location: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{*in.Lon, *in.Lat}},
+	}
+	if in.AccuracyM != nil {
+		loc.AccuracyM = *in.AccuracyM
 	}
+	if err := h.Locations.Save(c.Request.Context(), userID, loc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.Broadcaster.BroadcastPresence(userID, loc)
+
 	c.JSON(http.StatusOK, gin.H{"ok": true, "display_until": displayUntil})
 }
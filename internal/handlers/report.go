@@ -16,7 +16,6 @@ type AIClient interface {
 }
 
 type CreateReportRequest struct {
-	UserID      string  `json:"userId" binding:"required"`
 	Description string  `json:"description" binding:"required"`
 	Lat         float64 `json:"lat"`
 	Lon         float64 `json:"lon"`
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"time"
 
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	aiclient "github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/aiapi" // generated by oapi-codegen
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/realtime"
 
@@ -12,21 +14,30 @@ import (
 )
 
 type SOSHandler struct {
-	AI aiSOSClient
-	RT realtime.Broadcaster
+	AI          aiSOSClient
+	Alerts      sosAlerts
+	Broadcaster realtime.Broadcaster
 }
 
 type aiSOSClient interface {
-	SosRaise(ctx context.Context, body aiclient.PostSosRaiseJSONRequestBody) (*aiclient.PostSosRaiseResponse, error)
+	SosRaise(ctx context.Context, body aiclient.PostSosRaiseJSONRequestBody) (*aiclient.SosRaiseResponse, error)
 }
 
+// sosAlerts: lưu alert và cộng risk zone như SOS qua WS, trả về user gần đó; AlertUseCase đã cài sẵn
+type sosAlerts interface {
+	Raise(ctx context.Context, userID string, alert *domain.Alert) ([]string, error)
+}
+
+// Lat / Lon là con trỏ để 0 vẫn là toạ độ hợp lệ
 type SosRaiseRequest struct {
-	UserID    string  `json:"userId" binding:"required"`
-	AlertBody string  `json:"alert_body" binding:"required"`
-	Lat       float64 `json:"lat" binding:"required"`
-	Lon       float64 `json:"lon" binding:"required"`
-	RadiusM   int     `json:"radius_m" binding:"required"`
-	TtlMin    int     `json:"ttl_min" binding:"required"`
+	AlertBody string   `json:"alert_body" binding:"required"`
+	Lat       *float64 `json:"lat" binding:"required,min=-90,max=90"`
+	Lon       *float64 `json:"lon" binding:"required,min=-180,max=180"`
+	RadiusM   int      `json:"radius_m" binding:"required,min=1"`
+	TtlMin    int      `json:"ttl_min" binding:"required,min=1"`
+
+	UserName    string `json:"user_name"`
+	PhoneNumber string `json:"phone_number"`
 }
 
 func (h *SOSHandler) Raise(c *gin.Context) {
@@ -35,30 +46,42 @@ func (h *SOSHandler) Raise(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	resp, err := h.AI.SosRaise(c.Request.Context(), aiclient.PostSosRaiseJSONRequestBody{
-		AlertBody: in.AlertBody, Lat: float32(in.Lat), Lon: float32(in.Lon), RadiusM: in.RadiusM, TtlMin: in.TtlMin,
-	})
+	lat, lon := *in.Lat, *in.Lon
+
+	alert := &domain.Alert{
+		Body:        in.AlertBody,
+		Location:    domain.GeoPoint{Type: "Point", Coordinates: [2]float64{lon, lat}},
+		RadiusM:     float64(in.RadiusM),
+		TTLMin:      in.TtlMin,
+		ExpiresAt:   time.Now().Add(time.Duration(in.TtlMin) * time.Minute),
+		UserName:    in.UserName,
+		PhoneNumber: in.PhoneNumber,
+	}
+	// lưu và broadcast trước: AI lỗi không được làm mất SOS. Người gửi lấy từ token, không tin body
+	userIDs, err := h.Alerts.Raise(c.Request.Context(), c.GetString("x-user-id"), alert)
 	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.Broadcaster.BroadcastSOS(userIDs, alert)
 
-	if h.RT != nil && resp.JSON200 != nil {
-		h.RT.BroadcastSOS(
-			resp.JSON200.SosId,
-			in.Lat,
-			in.Lon,
-			in.RadiusM,
-			resp.JSON200.ExpiresAt.Format(time.RFC3339),
-			in.AlertBody,
-		)
-	}
-
-	c.JSON(http.StatusOK, gin.H{
+	out := gin.H{
 		"ok":         true,
-		"sos_id":     resp.JSON200.SosId,
-		"center":     []float64{in.Lat, in.Lon},
+		"alert_id":   alert.ID.Hex(),
+		"center":     []float64{lat, lon},
 		"radius_m":   in.RadiusM,
-		"expires_at": resp.JSON200.ExpiresAt,
+		"expires_at": alert.ExpiresAt,
+	}
+
+	// AI chỉ bổ sung sos_id, lỗi thì bỏ qua
+	resp, err := h.AI.SosRaise(c.Request.Context(), aiclient.PostSosRaiseJSONRequestBody{
+		AlertBody: in.AlertBody, Lat: float32(lat), Lon: float32(lon), RadiusM: in.RadiusM, TtlMin: in.TtlMin,
 	})
+	switch {
+	case err != nil:
+		log.Println("sos: ai raise failed:", err)
+	case resp != nil && resp.SosId != "":
+		out["sos_id"] = resp.SosId
+	}
+	c.JSON(http.StatusOK, out)
 }
@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	aiclient "github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/aiapi"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/handlers"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type downAI struct{}
+
+func (downAI) SosRaise(ctx context.Context, body aiclient.PostSosRaiseJSONRequestBody) (*aiclient.SosRaiseResponse, error) {
+	return nil, errors.New("ai unavailable")
+}
+
+type fakeAlerts struct {
+	userID string
+	saved  []*domain.Alert
+}
+
+func (f *fakeAlerts) Raise(ctx context.Context, userID string, alert *domain.Alert) ([]string, error) {
+	alert.ID = primitive.NewObjectID()
+	f.userID = userID
+	f.saved = append(f.saved, alert)
+	return []string{"u2"}, nil
+}
+
+type fakeBroadcaster struct {
+	sos [][]string
+}
+
+func (f *fakeBroadcaster) BroadcastPresence(userID string, loc *domain.Location) {}
+
+func (f *fakeBroadcaster) BroadcastSOS(userIDs []string, alert *domain.Alert) {
+	f.sos = append(f.sos, userIDs)
+}
+
+func newSOSRouter(h *handlers.SOSHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/sos", func(c *gin.Context) {
+		c.Set("x-user-id", "u1")
+		c.Next()
+	}, h.Raise)
+	return r
+}
+
+func postSOS(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/sos", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSOSRaiseWithoutAI(t *testing.T) {
+	alerts, bc := &fakeAlerts{}, &fakeBroadcaster{}
+	r := newSOSRouter(&handlers.SOSHandler{AI: downAI{}, Alerts: alerts, Broadcaster: bc})
+
+	before := time.Now()
+	// toạ độ 0 vẫn hợp lệ
+	rec := postSOS(r, `{"alert_body":"kẹt trên mái nhà","lat":0,"lon":0,"radius_m":500,"ttl_min":30}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	require.Len(t, alerts.saved, 1)
+	assert.Equal(t, "u1", alerts.userID)
+	assert.WithinDuration(t, before.Add(30*time.Minute), alerts.saved[0].ExpiresAt, 5*time.Second)
+	assert.Equal(t, [][]string{{"u2"}}, bc.sos)
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+	assert.Equal(t, alerts.saved[0].ID.Hex(), out["alert_id"])
+	assert.NotContains(t, out, "sos_id")
+}
+
+func TestSOSRaiseRejectsOutOfRange(t *testing.T) {
+	alerts := &fakeAlerts{}
+	r := newSOSRouter(&handlers.SOSHandler{AI: downAI{}, Alerts: alerts, Broadcaster: &fakeBroadcaster{}})
+
+	for _, body := range []string{
+		`{"alert_body":"x","lat":91,"lon":0,"radius_m":500,"ttl_min":30}`,
+		`{"alert_body":"x","lat":0,"lon":-181,"radius_m":500,"ttl_min":30}`,
+		`{"alert_body":"x","lon":0,"radius_m":500,"ttl_min":30}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, postSOS(r, body).Code, body)
+	}
+	assert.Empty(t, alerts.saved)
+}
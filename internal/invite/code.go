@@ -0,0 +1,61 @@
+// Package invite sinh và chuẩn hóa mã mời vào nhóm.
+package invite
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+// Alphabet bỏ 0/O, 1/I/L để đọc qua điện thoại không nhầm
+const Alphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// Length: 31^8 ≈ 8.5e11 mã, đủ để không đoán được khi đã giới hạn số lần thử
+const Length = 8
+
+// NewCode sinh mã ngẫu nhiên từ crypto/rand; trùng mã do unique index trên groups.inviteCode chặn
+func NewCode() (string, error) {
+	// bỏ byte >= 248 (= 31*8) để mọi ký tự có xác suất như nhau
+	const limit = 256 - 256%len(Alphabet)
+
+	code := make([]byte, 0, Length)
+	buf := make([]byte, Length*2)
+	for len(code) < Length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if int(b) >= limit {
+				continue
+			}
+			code = append(code, Alphabet[int(b)%len(Alphabet)])
+			if len(code) == Length {
+				break
+			}
+		}
+	}
+	return string(code), nil
+}
+
+// Normalize: chữ hoa, bỏ khoảng trắng và gạch nối (người dùng hay gõ "abcd-efgh")
+func Normalize(s string) string {
+	s = strings.ToUpper(s)
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Valid: đúng độ dài và chỉ gồm ký tự trong Alphabet
+func Valid(code string) bool {
+	if len(code) != Length {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(Alphabet, code[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
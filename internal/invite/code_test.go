@@ -0,0 +1,29 @@
+package invite_test
+
+import (
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/invite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCode(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		code, err := invite.NewCode()
+		assert.NoError(t, err)
+		assert.True(t, invite.Valid(code), code)
+		assert.NotContains(t, code, "0")
+		assert.NotContains(t, code, "O")
+		assert.NotContains(t, code, "I")
+		seen[code] = true
+	}
+	assert.Len(t, seen, 1000)
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "ABCD2345", invite.Normalize(" abcd-2345 "))
+	assert.True(t, invite.Valid(invite.Normalize("abcd-2345")))
+	assert.False(t, invite.Valid("a1b2c3")) // mã hex 6 ký tự kiểu cũ
+	assert.False(t, invite.Valid("ABCD234O"))
+}
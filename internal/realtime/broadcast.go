@@ -1,6 +1,11 @@
 package realtime
 
+import "github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+
+// Broadcaster đẩy presence / SOS nhận qua REST tới client WS, WSManager cài sẵn
 type Broadcaster interface {
-	BroadcastPresence(userID string, lat, lon float64, status string, displayUntil string)
-	BroadcastSOS(sosID string, lat, lon float64, radiusM int, expiresAt string, body string)
+	// BroadcastPresence gửi vị trí mới của user theo cài đặt chia sẻ vị trí của user
+	BroadcastPresence(userID string, loc *domain.Location)
+	// BroadcastSOS gửi "alert_broadcast" tới các user trong userIDs
+	BroadcastSOS(userIDs []string, alert *domain.Alert)
 }
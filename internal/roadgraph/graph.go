@@ -0,0 +1,150 @@
+package roadgraph
+
+import (
+	"container/heap"
+	"math"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+)
+
+// gridDeg — cạnh ô index tìm node gần nhất (~1km)
+const gridDeg = 0.01
+
+type Node struct {
+	Lat, Lon float64
+}
+
+type Edge struct {
+	To      int32
+	LengthM float64
+}
+
+// Graph là đồ thị đường có hướng, node đánh số 0..N-1
+type Graph struct {
+	nodes []Node
+	adj   [][]Edge
+	grid  map[[2]int32][]int32
+}
+
+func newGraph(nodes []Node, adj [][]Edge) *Graph {
+	g := &Graph{nodes: nodes, adj: adj, grid: map[[2]int32][]int32{}}
+	for i, n := range nodes {
+		k := gridKey(n.Lat, n.Lon)
+		g.grid[k] = append(g.grid[k], int32(i))
+	}
+	return g
+}
+
+func gridKey(lat, lon float64) [2]int32 {
+	return [2]int32{int32(math.Floor(lat / gridDeg)), int32(math.Floor(lon / gridDeg))}
+}
+
+func (g *Graph) Len() int { return len(g.nodes) }
+
+func (g *Graph) Node(i int) Node { return g.nodes[i] }
+
+func (g *Graph) Edges(i int) []Edge { return g.adj[i] }
+
+// Nearest trả node gần (lat, lon) nhất trong bán kính maxM
+func (g *Graph) Nearest(lat, lon, maxM float64) (int, float64, bool) {
+	k := gridKey(lat, lon)
+	dLat, dLon := geo.DegreesAround(lat, maxM)
+	rLat := int32(math.Ceil(dLat / gridDeg))
+	rLon := int32(math.Ceil(dLon / gridDeg))
+
+	best, bestD := -1, math.Inf(1)
+	for dy := -rLat; dy <= rLat; dy++ {
+		for dx := -rLon; dx <= rLon; dx++ {
+			for _, i := range g.grid[[2]int32{k[0] + dy, k[1] + dx}] {
+				n := g.nodes[i]
+				if d := geo.DistanceMeters(lat, lon, n.Lat, n.Lon); d < bestD {
+					best, bestD = int(i), d
+				}
+			}
+		}
+	}
+	if best < 0 || bestD > maxM {
+		return 0, 0, false
+	}
+	return best, bestD, true
+}
+
+// CostFunc trả chi phí đi qua cạnh from -> to, +Inf = không đi được.
+// Chi phí phải >= độ dài cạnh để heuristic A* còn đúng.
+type CostFunc func(from, to int, e Edge) float64
+
+// ShortestPath tìm đường chi phí nhỏ nhất bằng A* (heuristic = khoảng cách đường chim bay).
+// cost nil thì chỉ tính theo độ dài.
+func (g *Graph) ShortestPath(from, to int, cost CostFunc) ([]int, float64, bool) {
+	if from < 0 || to < 0 || from >= len(g.nodes) || to >= len(g.nodes) {
+		return nil, 0, false
+	}
+	target := g.nodes[to]
+	h := func(i int) float64 {
+		n := g.nodes[i]
+		return geo.DistanceMeters(n.Lat, n.Lon, target.Lat, target.Lon)
+	}
+
+	dist := map[int]float64{from: 0}
+	prev := map[int]int{}
+	closed := map[int]bool{}
+	open := &queue{{node: from, f: h(from)}}
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(item).node
+		if closed[cur] {
+			continue
+		}
+		if cur == to {
+			path := []int{to}
+			for path[len(path)-1] != from {
+				path = append(path, prev[path[len(path)-1]])
+			}
+			for l, r := 0, len(path)-1; l < r; l, r = l+1, r-1 {
+				path[l], path[r] = path[r], path[l]
+			}
+			return path, dist[to], true
+		}
+		closed[cur] = true
+
+		for _, e := range g.adj[cur] {
+			next := int(e.To)
+			if closed[next] {
+				continue
+			}
+			c := e.LengthM
+			if cost != nil {
+				c = cost(cur, next, e)
+			}
+			if math.IsInf(c, 1) {
+				continue
+			}
+			d := dist[cur] + c
+			if old, ok := dist[next]; ok && old <= d {
+				continue
+			}
+			dist[next] = d
+			prev[next] = cur
+			heap.Push(open, item{node: next, f: d + h(next)})
+		}
+	}
+	return nil, 0, false
+}
+
+type item struct {
+	node int
+	f    float64
+}
+
+type queue []item
+
+func (q queue) Len() int            { return len(q) }
+func (q queue) Less(i, j int) bool  { return q[i].f < q[j].f }
+func (q queue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(item)) }
+func (q *queue) Pop() interface{} {
+	old := *q
+	it := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return it
+}
@@ -0,0 +1,78 @@
+package roadgraph_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/roadgraph"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hình vuông 1-2-3-4: đi thẳng 1-2-3 hoặc vòng 1-4-3, đường 5-6 là đường đi bộ
+const squareOSM = `<?xml version="1.0"?>
+<osm version="0.6">
+  <node id="1" lat="10.000" lon="106.000"/>
+  <node id="2" lat="10.000" lon="106.010"/>
+  <node id="3" lat="10.010" lon="106.010"/>
+  <node id="4" lat="10.010" lon="106.000"/>
+  <node id="5" lat="10.005" lon="106.005"/>
+  <node id="6" lat="10.006" lon="106.005"/>
+  <way id="10"><nd ref="1"/><nd ref="2"/><nd ref="3"/><tag k="highway" v="primary"/></way>
+  <way id="11"><nd ref="1"/><nd ref="4"/><nd ref="3"/><tag k="highway" v="residential"/></way>
+  <way id="12"><nd ref="3"/><nd ref="1"/><tag k="highway" v="tertiary"/><tag k="oneway" v="yes"/></way>
+  <way id="13"><nd ref="5"/><nd ref="6"/><tag k="highway" v="footway"/></way>
+</osm>`
+
+func load(t *testing.T) *roadgraph.Graph {
+	g, err := roadgraph.ReadOSM(strings.NewReader(squareOSM))
+	require.NoError(t, err)
+	return g
+}
+
+func TestReadOSMSkipsNonDrivable(t *testing.T) {
+	g := load(t)
+	assert.Equal(t, 4, g.Len())
+
+	_, _, ok := g.Nearest(10.0055, 106.005, 100)
+	assert.False(t, ok)
+}
+
+func TestShortestPathOneway(t *testing.T) {
+	g := load(t)
+	n1, _, _ := g.Nearest(10, 106, 50)
+	n3, _, _ := g.Nearest(10.01, 106.01, 50)
+
+	// 3 -> 1 đi được đường chéo một chiều
+	path, _, ok := g.ShortestPath(n3, n1, nil)
+	require.True(t, ok)
+	assert.Equal(t, []int{n3, n1}, path)
+
+	// 1 -> 3 không được đi ngược chiều, phải vòng
+	path, _, ok = g.ShortestPath(n1, n3, nil)
+	require.True(t, ok)
+	assert.Len(t, path, 3)
+}
+
+func TestShortestPathAvoidsExpensiveEdges(t *testing.T) {
+	g := load(t)
+	n1, _, _ := g.Nearest(10, 106, 50)
+	n2, _, _ := g.Nearest(10, 106.01, 50)
+	n3, _, _ := g.Nearest(10.01, 106.01, 50)
+
+	path, _, ok := g.ShortestPath(n1, n3, func(from, to int, e roadgraph.Edge) float64 {
+		if to == n2 {
+			return math.Inf(1)
+		}
+		return e.LengthM
+	})
+	require.True(t, ok)
+	assert.NotContains(t, path, n2)
+	assert.Equal(t, n3, path[len(path)-1])
+}
+
+func TestLoadRejectsPBF(t *testing.T) {
+	_, err := roadgraph.Load("extract.osm.pbf")
+	assert.ErrorIs(t, err, roadgraph.ErrUnsupportedFormat)
+}
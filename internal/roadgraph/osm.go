@@ -0,0 +1,195 @@
+package roadgraph
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+)
+
+var (
+	ErrUnsupportedFormat = errors.New("road graph: only OSM XML (.osm, .osm.gz) is supported, convert .pbf with osmium first")
+	ErrEmptyGraph        = errors.New("road graph: no drivable roads in file")
+)
+
+// loại đường xe chạy được
+var drivable = map[string]bool{
+	"motorway": true, "motorway_link": true,
+	"trunk": true, "trunk_link": true,
+	"primary": true, "primary_link": true,
+	"secondary": true, "secondary_link": true,
+	"tertiary": true, "tertiary_link": true,
+	"unclassified": true, "residential": true, "living_street": true,
+	"service": true, "road": true,
+}
+
+// Load đọc file OSM XML (có thể nén gzip) đã cắt theo khu vực
+func Load(path string) (*Graph, error) {
+	if strings.HasSuffix(path, ".pbf") {
+		return nil, ErrUnsupportedFormat
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return ReadOSM(r)
+}
+
+type osmWay struct {
+	refs    []int64
+	highway string
+	oneway  string
+	access  string
+	round   bool
+}
+
+// ReadOSM dựng đồ thị từ các way có tag highway xe chạy được.
+// Way một chiều (oneway=yes/-1, vòng xuyến, cao tốc) chỉ có cạnh theo chiều cho phép.
+func ReadOSM(r io.Reader) (*Graph, error) {
+	dec := xml.NewDecoder(r)
+	coords := map[int64]Node{}
+	var ways []osmWay
+	var cur *osmWay
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("road graph: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "node":
+				var id int64
+				var n Node
+				for _, a := range t.Attr {
+					switch a.Name.Local {
+					case "id":
+						id, _ = strconv.ParseInt(a.Value, 10, 64)
+					case "lat":
+						n.Lat, _ = strconv.ParseFloat(a.Value, 64)
+					case "lon":
+						n.Lon, _ = strconv.ParseFloat(a.Value, 64)
+					}
+				}
+				coords[id] = n
+			case "way":
+				cur = &osmWay{}
+			case "nd":
+				if cur != nil {
+					for _, a := range t.Attr {
+						if a.Name.Local == "ref" {
+							ref, _ := strconv.ParseInt(a.Value, 10, 64)
+							cur.refs = append(cur.refs, ref)
+						}
+					}
+				}
+			case "tag":
+				if cur != nil {
+					var k, v string
+					for _, a := range t.Attr {
+						switch a.Name.Local {
+						case "k":
+							k = a.Value
+						case "v":
+							v = a.Value
+						}
+					}
+					switch k {
+					case "highway":
+						cur.highway = v
+					case "oneway":
+						cur.oneway = v
+					case "access", "motor_vehicle":
+						if cur.access == "" || v == "no" {
+							cur.access = v
+						}
+					case "junction":
+						cur.round = v == "roundabout" || v == "circular"
+					}
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "way" && cur != nil {
+				if drivable[cur.highway] && cur.access != "no" && cur.access != "private" && len(cur.refs) > 1 {
+					ways = append(ways, *cur)
+				}
+				cur = nil
+			}
+		}
+	}
+
+	index := map[int64]int32{}
+	var nodes []Node
+	var adj [][]Edge
+	nodeIdx := func(ref int64) (int32, bool) {
+		if i, ok := index[ref]; ok {
+			return i, true
+		}
+		n, ok := coords[ref]
+		if !ok {
+			return 0, false // way tham chiếu node nằm ngoài vùng cắt
+		}
+		i := int32(len(nodes))
+		index[ref] = i
+		nodes = append(nodes, n)
+		adj = append(adj, nil)
+		return i, true
+	}
+
+	for _, w := range ways {
+		forward, backward := true, true
+		switch w.oneway {
+		case "yes", "true", "1":
+			backward = false
+		case "-1", "reverse":
+			forward = false
+		case "no", "false", "0":
+		default:
+			if w.round || w.highway == "motorway" {
+				backward = false
+			}
+		}
+
+		for i := 1; i < len(w.refs); i++ {
+			a, okA := nodeIdx(w.refs[i-1])
+			b, okB := nodeIdx(w.refs[i])
+			if !okA || !okB || a == b {
+				continue
+			}
+			length := geo.DistanceMeters(nodes[a].Lat, nodes[a].Lon, nodes[b].Lat, nodes[b].Lon)
+			if forward {
+				adj[a] = append(adj[a], Edge{To: b, LengthM: length})
+			}
+			if backward {
+				adj[b] = append(adj[b], Edge{To: a, LengthM: length})
+			}
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil, ErrEmptyGraph
+	}
+	return newGraph(nodes, adj), nil
+}
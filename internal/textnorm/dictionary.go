@@ -0,0 +1,99 @@
+package textnorm
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+)
+
+// các loại hazard dùng chung cho những bộ phân loại local
+const (
+	HazardFlood     = "FLOOD"
+	HazardFire      = "FIRE"
+	HazardAccident  = "ACCIDENT"
+	HazardLandslide = "LANDSLIDE"
+	HazardStorm     = "STORM"
+)
+
+// Dictionary lưu keyword theo từng hazard, an toàn khi đọc đồng thời
+type Dictionary struct {
+	mu       sync.RWMutex
+	keywords map[string][][]Token
+}
+
+func NewDictionary() *Dictionary {
+	return &Dictionary{keywords: map[string][][]Token{}}
+}
+
+// Add thêm keyword / cụm từ cho hazard (không phân biệt hoa thường)
+func (d *Dictionary) Add(hazard string, phrases ...string) {
+	hazard = strings.ToUpper(strings.TrimSpace(hazard))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, p := range phrases {
+		if tokens := Tokenize(p); len(tokens) > 0 {
+			d.keywords[hazard] = append(d.keywords[hazard], tokens)
+		}
+	}
+}
+
+// Text là text đã tokenize sẵn, dùng khi cần kiểm tra nhiều hazard trên cùng một text
+type Text []Token
+
+func NewText(s string) Text {
+	return Tokenize(s)
+}
+
+// Has kiểm tra text có keyword nào của hazard không
+func (d *Dictionary) Has(text Text, hazard string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for _, phrase := range d.keywords[strings.ToUpper(hazard)] {
+		if containsTokens(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match trả về hazard đầu tiên (theo thứ tự truyền vào) có keyword xuất hiện trong text
+func (d *Dictionary) Match(text string, hazards ...string) (string, bool) {
+	t := NewText(text)
+	for _, h := range hazards {
+		if d.Has(t, h) {
+			return h, true
+		}
+	}
+	return "", false
+}
+
+// LoadFile đọc file JSON dạng {"FLOOD": ["nước dâng", ...], ...} và thêm vào dictionary
+func (d *Dictionary) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var extra map[string][]string
+	if err := json.Unmarshal(data, &extra); err != nil {
+		return err
+	}
+	for hazard, phrases := range extra {
+		d.Add(hazard, phrases...)
+	}
+	return nil
+}
+
+// Hazards là dictionary mặc định, có thể mở rộng qua HAZARD_KEYWORDS_FILE
+var Hazards = defaultDictionary()
+
+func defaultDictionary() *Dictionary {
+	d := NewDictionary()
+	d.Add(HazardFlood, "lũ", "lụt", "ngập", "lũ quét", "nước dâng", "flood", "flooding")
+	d.Add(HazardFire, "cháy", "hỏa hoạn", "hoả hoạn", "fire", "smoke", "khói")
+	d.Add(HazardAccident, "tai nạn", "va chạm", "accident", "crash")
+	d.Add(HazardLandslide, "sạt lở", "lở đất", "landslide")
+	d.Add(HazardStorm, "bão", "lốc", "lốc xoáy", "storm", "typhoon")
+	return d
+}
@@ -0,0 +1,94 @@
+// Package textnorm chuẩn hoá text tiếng Việt / tiếng Anh cho các bộ phân loại rule-based:
+// case folding Unicode, bỏ dấu tiếng Việt và tách token.
+package textnorm
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+var folder = cases.Fold()
+
+// Fold case-fold theo Unicode, giữ nguyên dấu ("LŨ" -> "lũ")
+func Fold(s string) string {
+	return norm.NFC.String(folder.String(s))
+}
+
+// StripDiacritics bỏ dấu tiếng Việt ("ngập lụt" -> "ngap lut", "đường" -> "duong")
+func StripDiacritics(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range norm.NFD.String(s) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'đ':
+			r = 'd'
+		case r == 'Đ':
+			r = 'D'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Normalize = Fold + StripDiacritics
+func Normalize(s string) string {
+	return StripDiacritics(Fold(s))
+}
+
+// Token là một từ đã fold, kèm bản bỏ dấu
+type Token struct {
+	Folded   string
+	Stripped string
+}
+
+// Tokenize fold text rồi tách theo ký tự không phải chữ / số
+func Tokenize(s string) []Token {
+	words := strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r)
+	})
+
+	tokens := make([]Token, len(words))
+	for i, w := range words {
+		tokens[i] = Token{Folded: w, Stripped: StripDiacritics(w)}
+	}
+	return tokens
+}
+
+// matches so sánh token trong text với token của keyword.
+// Text có dấu thì phải khớp đúng dấu ("chạy" không khớp "cháy"),
+// text gõ không dấu thì so với keyword đã bỏ dấu ("ngap" khớp "ngập").
+func (t Token) matches(kw Token) bool {
+	if t.Folded == kw.Folded {
+		return true
+	}
+	return t.Folded == t.Stripped && t.Stripped == kw.Stripped
+}
+
+// ContainsPhrase kiểm tra text có chứa cụm từ phrase (theo ranh giới token)
+func ContainsPhrase(text, phrase string) bool {
+	return containsTokens(Tokenize(text), Tokenize(phrase))
+}
+
+func containsTokens(text, phrase []Token) bool {
+	if len(phrase) == 0 || len(phrase) > len(text) {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(text); i++ {
+		ok := true
+		for j, kw := range phrase {
+			if !text[i+j].matches(kw) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package textnorm_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/textnorm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "lũ", textnorm.Fold("LŨ"))
+	assert.Equal(t, "ngap lut duong", textnorm.Normalize("Ngập LỤT Đường"))
+	// dạng tổ hợp (NFD) cũng ra cùng kết quả
+	assert.Equal(t, textnorm.Fold("ngập"), textnorm.Fold("ngập"))
+}
+
+func TestContainsPhrase(t *testing.T) {
+	assert.True(t, textnorm.ContainsPhrase("Nước NGẬP tới gối", "ngập"))
+	assert.True(t, textnorm.ContainsPhrase("nuoc ngap toi goi", "ngập"))
+	assert.True(t, textnorm.ContainsPhrase("có TAI NẠN, xe tải", "tai nạn"))
+	// có dấu thì phải đúng dấu
+	assert.False(t, textnorm.ContainsPhrase("mọi người chạy đi", "cháy"))
+	// không khớp một phần của từ
+	assert.False(t, textnorm.ContainsPhrase("firework", "fire"))
+}
+
+func TestDictionary(t *testing.T) {
+	d := textnorm.NewDictionary()
+	d.Add("flood", "ngập")
+	d.Add(textnorm.HazardFire, "cháy")
+
+	h, ok := d.Match("Cháy nhà, đường ngập", textnorm.HazardFlood, textnorm.HazardFire)
+	assert.True(t, ok)
+	assert.Equal(t, textnorm.HazardFlood, h)
+
+	_, ok = d.Match("trời đẹp", textnorm.HazardFlood, textnorm.HazardFire)
+	assert.False(t, ok)
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keywords.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"FLOOD": ["triều cường"]}`), 0o644))
+
+	d := textnorm.NewDictionary()
+	assert.NoError(t, d.LoadFile(path))
+	assert.True(t, d.Has(textnorm.NewText("Triều Cường dâng cao"), textnorm.HazardFlood))
+}
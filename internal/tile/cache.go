@@ -0,0 +1,119 @@
+package tile
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+)
+
+// Cache giữ tile đã dựng theo key layer/format/z/x/y.
+// Tile bị xóa khi hết TTL hoặc khi dữ liệu trong khung tile thay đổi (InvalidateTiles / NotifyZone).
+type Cache struct {
+	mu         sync.Mutex
+	entries    map[string]*cacheEntry
+	maxEntries int
+}
+
+type cacheEntry struct {
+	tile    domain.Tile
+	layer   string
+	bounds  domain.BoundsFilter // có thể vượt ±180 ở tile mép
+	expires time.Time
+	created time.Time
+}
+
+func NewCache(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = 5000
+	}
+	return &Cache{entries: map[string]*cacheEntry{}, maxEntries: maxEntries}
+}
+
+func (c *Cache) Get(key string) (*domain.Tile, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	t := e.tile
+	return &t, true
+}
+
+func (c *Cache) Put(key, layer string, b domain.BoundsFilter, t domain.Tile, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evictLocked()
+	}
+	now := time.Now()
+	c.entries[key] = &cacheEntry{tile: t, layer: layer, bounds: b, expires: now.Add(ttl), created: now}
+}
+
+// evictLocked bỏ các tile hết hạn, nếu vẫn đầy thì bỏ tile cũ nhất
+func (c *Cache) evictLocked() {
+	now := time.Now()
+	var oldestKey string
+	var oldest time.Time
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || e.created.Before(oldest) {
+			oldestKey, oldest = k, e.created
+		}
+	}
+	if len(c.entries) >= c.maxEntries && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
+
+// InvalidateTiles xóa tile của các layer có khung chạm vào b (minLon > maxLon = vắt qua 180)
+func (c *Cache) InvalidateTiles(layers []string, b domain.BoundsFilter) {
+	want := map[string]bool{}
+	for _, l := range layers {
+		want[l] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if want[e.layer] && boundsOverlap(e.bounds, b) {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// NotifyZone: zone đổi thì tile zone và tile lưới risk (có chiếu zone) quanh đó đều cũ
+func (c *Cache) NotifyZone(e domain.ZoneEvent) {
+	minLat, minLon, maxLat, maxLon := geo.BoundsAround(e.Zone.Center.Coordinates[1], e.Zone.Center.Coordinates[0], e.Zone.Radius)
+	c.InvalidateTiles([]string{domain.TileLayerZones, domain.TileLayerCells}, domain.BoundsFilter{
+		MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon,
+	})
+}
+
+func boundsOverlap(a, b domain.BoundsFilter) bool {
+	if a.MaxLat < b.MinLat || a.MinLat > b.MaxLat {
+		return false
+	}
+	aMin, aMax := a.MinLon, a.MaxLon
+	bMin, bMax := b.MinLon, b.MaxLon
+	if bMin > bMax {
+		bMax += 360
+	}
+	for _, shift := range [...]float64{0, 360, -360} {
+		if aMin+shift <= bMax && aMax+shift >= bMin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package tile
+
+import (
+	"math"
+	"sort"
+)
+
+// Point — một điểm đã chiếu vào tile, Weight dùng cho màu / heatmap
+type Point struct {
+	X, Y   float64
+	Weight float64
+	ID     string
+	Props  map[string]interface{}
+}
+
+// Cluster là nhóm điểm gần nhau; khi Count == 1 thì Point giữ nguyên điểm gốc
+type Cluster struct {
+	X, Y      float64 // trọng tâm
+	Count     int
+	MaxWeight float64
+	Point     Point
+}
+
+// ClusterPoints gom điểm theo ô vuông cạnh size (đơn vị tile), size <= 0 thì không gom.
+// Kết quả sắp theo vị trí ô để tile dựng lại giống hệt nhau.
+func ClusterPoints(points []Point, size float64) []Cluster {
+	if size <= 0 {
+		out := make([]Cluster, len(points))
+		for i, p := range points {
+			out[i] = Cluster{X: p.X, Y: p.Y, Count: 1, MaxWeight: p.Weight, Point: p}
+		}
+		return out
+	}
+
+	type acc struct {
+		sumX, sumY float64
+		c          Cluster
+	}
+	cells := map[[2]int64]*acc{}
+	for _, p := range points {
+		key := [2]int64{int64(math.Floor(p.X / size)), int64(math.Floor(p.Y / size))}
+		a, ok := cells[key]
+		if !ok {
+			a = &acc{c: Cluster{Point: p, MaxWeight: p.Weight}}
+			cells[key] = a
+		}
+		a.sumX += p.X
+		a.sumY += p.Y
+		a.c.Count++
+		a.c.MaxWeight = math.Max(a.c.MaxWeight, p.Weight)
+	}
+
+	keys := make([][2]int64, 0, len(cells))
+	for k := range cells {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][1] != keys[j][1] {
+			return keys[i][1] < keys[j][1]
+		}
+		return keys[i][0] < keys[j][0]
+	})
+
+	out := make([]Cluster, 0, len(keys))
+	for _, k := range keys {
+		a := cells[k]
+		a.c.X, a.c.Y = a.sumX/float64(a.c.Count), a.sumY/float64(a.c.Count)
+		out = append(out, a.c)
+	}
+	return out
+}
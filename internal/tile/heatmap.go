@@ -0,0 +1,81 @@
+package tile
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+)
+
+// PNGSize — cạnh ảnh heatmap (pixel)
+const PNGSize = 256
+
+// Spot là một vùng nóng trên heatmap, tọa độ và bán kính theo đơn vị tile (0..Extent)
+type Spot struct {
+	X, Y   float64
+	Radius float64
+	Weight float64 // 0..1
+}
+
+// minSpotPx: điểm quá nhỏ ở zoom xa vẫn hiện được
+const minSpotPx = 3.0
+
+// RenderHeatmap vẽ các spot thành PNG trong suốt, màu từ xanh (thấp) tới đỏ (cao)
+func RenderHeatmap(spots []Spot) ([]byte, error) {
+	scale := float64(PNGSize) / Extent
+	intensity := make([]float64, PNGSize*PNGSize)
+
+	for _, s := range spots {
+		cx, cy := s.X*scale, s.Y*scale
+		r := math.Max(s.Radius*scale, minSpotPx)
+		x0, x1 := clampPx(cx-r), clampPx(cx+r)
+		y0, y1 := clampPx(cy-r), clampPx(cy+r)
+		for py := y0; py <= y1; py++ {
+			for px := x0; px <= x1; px++ {
+				d := math.Hypot(float64(px)+0.5-cx, float64(py)+0.5-cy)
+				if d > r {
+					continue
+				}
+				// giảm dần ra mép, vùng chồng nhau cộng dồn
+				falloff := 1 - d/r
+				i := py*PNGSize + px
+				intensity[i] = math.Min(1, intensity[i]+s.Weight*(0.35+0.65*falloff*falloff))
+			}
+		}
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, PNGSize, PNGSize))
+	for i, v := range intensity {
+		if v <= 0 {
+			continue
+		}
+		img.SetNRGBA(i%PNGSize, i/PNGSize, ramp(v))
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func clampPx(v float64) int {
+	return int(math.Max(0, math.Min(PNGSize-1, math.Floor(v))))
+}
+
+// ramp: xanh lá -> vàng -> đỏ, độ đậm tăng theo cường độ
+func ramp(v float64) color.NRGBA {
+	var r, g float64
+	if v < 0.5 {
+		r, g = v*2, 1
+	} else {
+		r, g = 1, 1-(v-0.5)*2
+	}
+	return color.NRGBA{
+		R: uint8(r * 255),
+		G: uint8(g * 200),
+		B: 40,
+		A: uint8(80 + v*150),
+	}
+}
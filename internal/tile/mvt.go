@@ -0,0 +1,258 @@
+package tile
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+)
+
+// Loại hình học của feature MVT
+const (
+	GeomPoint   = 1
+	GeomPolygon = 3
+)
+
+// Feature: Geometry là danh sách điểm (GeomPoint) hoặc danh sách ring (GeomPolygon),
+// tọa độ đã chiếu vào tile (Projector). Props chỉ nhận string, số, bool.
+type Feature struct {
+	ID       uint64
+	Type     int
+	Geometry [][][2]float64
+	Props    map[string]interface{}
+}
+
+type Layer struct {
+	Name     string
+	Features []Feature
+}
+
+// EncodeMVT mã hóa các layer thành Mapbox Vector Tile (protobuf, spec v2)
+func EncodeMVT(layers ...Layer) []byte {
+	var out pbuf
+	for _, l := range layers {
+		out.bytes(3, encodeLayer(l))
+	}
+	return out
+}
+
+func encodeLayer(l Layer) []byte {
+	var (
+		b      pbuf
+		keys   []string
+		keyIdx = map[string]int{}
+		values [][]byte
+		valIdx = map[string]int{}
+	)
+
+	b.varintField(15, 2) // version
+	b.bytes(1, []byte(l.Name))
+
+	for _, f := range l.Features {
+		geom := encodeGeometry(f)
+		if len(geom) == 0 {
+			continue
+		}
+
+		// sắp xếp key để tile giống hệt nhau giữa các lần dựng (ETag ổn định)
+		names := make([]string, 0, len(f.Props))
+		for k := range f.Props {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		var tags []uint64
+		for _, k := range names {
+			v, ok := encodeValue(f.Props[k])
+			if !ok {
+				continue
+			}
+			ki, seen := keyIdx[k]
+			if !seen {
+				ki = len(keys)
+				keyIdx[k] = ki
+				keys = append(keys, k)
+			}
+			vi, seen := valIdx[string(v)]
+			if !seen {
+				vi = len(values)
+				valIdx[string(v)] = vi
+				values = append(values, v)
+			}
+			tags = append(tags, uint64(ki), uint64(vi))
+		}
+
+		var fb pbuf
+		if f.ID != 0 {
+			fb.varintField(1, f.ID)
+		}
+		if len(tags) > 0 {
+			fb.packed(2, tags)
+		}
+		fb.varintField(3, uint64(f.Type))
+		fb.packed(4, geom)
+		b.bytes(2, fb)
+	}
+
+	for _, k := range keys {
+		b.bytes(3, []byte(k))
+	}
+	for _, v := range values {
+		b.bytes(4, v)
+	}
+	b.varintField(5, Extent)
+	return b
+}
+
+func encodeValue(v interface{}) ([]byte, bool) {
+	var b pbuf
+	switch x := v.(type) {
+	case string:
+		b.bytes(1, []byte(x))
+	case float64:
+		b.double(3, x)
+	case float32:
+		b.double(3, float64(x))
+	case int:
+		b.varintField(6, zigzag(int64(x)))
+	case int64:
+		b.varintField(6, zigzag(x))
+	case bool:
+		if x {
+			b.varintField(7, 1)
+		} else {
+			b.varintField(7, 0)
+		}
+	default:
+		return nil, false
+	}
+	return b, true
+}
+
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+func command(id, count int) uint64 {
+	return uint64(id&0x7 | count<<3)
+}
+
+// encodeGeometry chuyển tọa độ sang lệnh MoveTo / LineTo / ClosePath với delta zigzag
+func encodeGeometry(f Feature) []uint64 {
+	var (
+		out    []uint64
+		cx, cy int64
+	)
+	delta := func(p [2]int64) {
+		out = append(out, zigzag(p[0]-cx), zigzag(p[1]-cy))
+		cx, cy = p[0], p[1]
+	}
+
+	switch f.Type {
+	case GeomPoint:
+		var pts [][2]int64
+		for _, group := range f.Geometry {
+			for _, p := range group {
+				pts = append(pts, round(p))
+			}
+		}
+		if len(pts) == 0 {
+			return nil
+		}
+		out = append(out, command(cmdMoveTo, len(pts)))
+		for _, p := range pts {
+			delta(p)
+		}
+
+	case GeomPolygon:
+		for i, ring := range f.Geometry {
+			pts := cleanRing(ring)
+			if len(pts) < 3 {
+				if i == 0 {
+					return nil // ring ngoài suy biến (zone quá nhỏ ở zoom này)
+				}
+				continue
+			}
+			// ring ngoài theo chiều kim đồng hồ trong hệ tọa độ tile, ring trong ngược lại
+			if (ringArea(pts) < 0) == (i == 0) {
+				for l, r := 0, len(pts)-1; l < r; l, r = l+1, r-1 {
+					pts[l], pts[r] = pts[r], pts[l]
+				}
+			}
+			out = append(out, command(cmdMoveTo, 1))
+			delta(pts[0])
+			out = append(out, command(cmdLineTo, len(pts)-1))
+			for _, p := range pts[1:] {
+				delta(p)
+			}
+			out = append(out, command(cmdClosePath, 1))
+		}
+	}
+	return out
+}
+
+// cleanRing làm tròn tọa độ, bỏ điểm trùng liên tiếp và điểm đóng ring
+func cleanRing(ring [][2]float64) [][2]int64 {
+	pts := make([][2]int64, 0, len(ring))
+	for _, p := range ring {
+		q := round(p)
+		if len(pts) > 0 && pts[len(pts)-1] == q {
+			continue
+		}
+		pts = append(pts, q)
+	}
+	if len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+	return pts
+}
+
+// ringArea > 0 khi ring theo chiều kim đồng hồ với trục y hướng xuống
+func ringArea(pts [][2]int64) int64 {
+	var sum int64
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		sum += pts[i][0]*pts[j][1] - pts[j][0]*pts[i][1]
+	}
+	return sum
+}
+
+func round(p [2]float64) [2]int64 {
+	return [2]int64{int64(math.Round(p[0])), int64(math.Round(p[1]))}
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+// pbuf — ghi protobuf tối thiểu đủ cho MVT
+type pbuf []byte
+
+func (b *pbuf) varint(v uint64) {
+	*b = binary.AppendUvarint(*b, v)
+}
+
+func (b *pbuf) varintField(field int, v uint64) {
+	b.varint(uint64(field<<3 | 0))
+	b.varint(v)
+}
+
+func (b *pbuf) double(field int, v float64) {
+	b.varint(uint64(field<<3 | 1))
+	*b = binary.LittleEndian.AppendUint64(*b, math.Float64bits(v))
+}
+
+func (b *pbuf) bytes(field int, data []byte) {
+	b.varint(uint64(field<<3 | 2))
+	b.varint(uint64(len(data)))
+	*b = append(*b, data...)
+}
+
+func (b *pbuf) packed(field int, vals []uint64) {
+	var inner pbuf
+	for _, v := range vals {
+		inner.varint(v)
+	}
+	b.bytes(field, inner)
+}
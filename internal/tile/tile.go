@@ -0,0 +1,67 @@
+// Package tile dựng tile bản đồ (XYZ, Web Mercator) dạng Mapbox Vector Tile hoặc PNG heatmap
+package tile
+
+import "math"
+
+const (
+	Extent  = 4096 // kích thước tile trong hệ tọa độ MVT
+	MaxZoom = 22
+
+	maxMercatorLat = 85.05112878
+)
+
+// Valid kiểm tra z/x/y nằm trong lưới tile
+func Valid(z, x, y int) bool {
+	if z < 0 || z > MaxZoom {
+		return false
+	}
+	n := 1 << z
+	return x >= 0 && x < n && y >= 0 && y < n
+}
+
+// Bounds trả về khung lat/lon của tile, nới thêm buffer (tỉ lệ theo cạnh tile) mỗi phía.
+// Kinh độ có thể vượt ±180 ở tile mép, caller chuẩn hóa nếu cần.
+func Bounds(z, x, y int, buffer float64) (minLat, minLon, maxLat, maxLon float64) {
+	n := float64(int(1) << z)
+	minLon = (float64(x)-buffer)/n*360 - 180
+	maxLon = (float64(x)+1+buffer)/n*360 - 180
+	maxLat = tileLat(float64(y)-buffer, n)
+	minLat = tileLat(float64(y)+1+buffer, n)
+	return
+}
+
+func tileLat(y, n float64) float64 {
+	lat := math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180 / math.Pi
+	return math.Max(-maxMercatorLat, math.Min(maxMercatorLat, lat))
+}
+
+// Projector chuyển lat/lon sang tọa độ trong một tile (0..Extent, trục y hướng xuống)
+type Projector struct {
+	z, x, y int
+	n       float64
+}
+
+func NewProjector(z, x, y int) Projector {
+	return Projector{z: z, x: x, y: y, n: float64(int(1) << z)}
+}
+
+func (p Projector) Point(lat, lon float64) (float64, float64) {
+	lat = math.Max(-maxMercatorLat, math.Min(maxMercatorLat, lat))
+	worldX := (lon + 180) / 360 * p.n
+	latR := lat * math.Pi / 180
+	worldY := (1 - math.Log(math.Tan(latR)+1/math.Cos(latR))/math.Pi) / 2 * p.n
+
+	// điểm bên kia kinh tuyến 180 được đưa về phía gần tile nhất
+	dx := worldX - float64(p.x)
+	if dx > p.n/2 {
+		dx -= p.n
+	} else if dx < -p.n/2 {
+		dx += p.n
+	}
+	return dx * Extent, (worldY - float64(p.y)) * Extent
+}
+
+// MetersPerUnit: số mét ứng với một đơn vị tọa độ tile ở vĩ độ lat
+func (p Projector) MetersPerUnit(lat float64) float64 {
+	return 2 * math.Pi * 6378137 * math.Cos(lat*math.Pi/180) / (p.n * Extent)
+}
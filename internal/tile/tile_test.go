@@ -0,0 +1,76 @@
+package tile_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/tile"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeMVTPoint(t *testing.T) {
+	data := tile.EncodeMVT(tile.Layer{Name: "reports", Features: []tile.Feature{{
+		Type:     tile.GeomPoint,
+		Geometry: [][][2]float64{{{25, 17}}},
+		Props:    map[string]interface{}{"count": 1},
+	}}})
+
+	// ví dụ trong spec MVT: MoveTo(1) tại (25,17) -> [9 50 34]
+	assert.Contains(t, string(data), "reports")
+	assert.Contains(t, string(data), string([]byte{0x22, 3, 9, 50, 34}))
+}
+
+func TestEncodeMVTSkipsDegeneratePolygon(t *testing.T) {
+	a := tile.EncodeMVT(tile.Layer{Name: "zones"})
+	b := tile.EncodeMVT(tile.Layer{Name: "zones", Features: []tile.Feature{{
+		Type:     tile.GeomPolygon,
+		Geometry: [][][2]float64{{{10, 10}, {10.2, 10.1}, {10, 10}}},
+	}}})
+	assert.Equal(t, a, b)
+}
+
+func TestClusterPoints(t *testing.T) {
+	points := []tile.Point{
+		{X: 10, Y: 10, Weight: 0.2},
+		{X: 20, Y: 30, Weight: 0.9},
+		{X: 300, Y: 10, Weight: 0.5, ID: "lone"},
+	}
+
+	clusters := tile.ClusterPoints(points, 256)
+	assert.Len(t, clusters, 2)
+	assert.Equal(t, 2, clusters[0].Count)
+	assert.Equal(t, 0.9, clusters[0].MaxWeight)
+	assert.InDelta(t, 15, clusters[0].X, 1e-9)
+	assert.Equal(t, "lone", clusters[1].Point.ID)
+
+	assert.Len(t, tile.ClusterPoints(points, 0), 3)
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	c := tile.NewCache(10)
+	near := domain.BoundsFilter{MinLat: 10, MinLon: 106, MaxLat: 11, MaxLon: 107}
+	far := domain.BoundsFilter{MinLat: 20, MinLon: 106, MaxLat: 21, MaxLon: 107}
+	c.Put("cells/near", domain.TileLayerCells, near, domain.Tile{ETag: "a"}, time.Minute)
+	c.Put("cells/far", domain.TileLayerCells, far, domain.Tile{ETag: "b"}, time.Minute)
+	c.Put("reports/near", domain.TileLayerReports, near, domain.Tile{ETag: "c"}, time.Minute)
+
+	c.InvalidateTiles([]string{domain.TileLayerCells}, domain.BoundsFilter{MinLat: 10.5, MinLon: 106.5, MaxLat: 10.6, MaxLon: 106.6})
+
+	_, ok := c.Get("cells/near")
+	assert.False(t, ok)
+	_, ok = c.Get("cells/far")
+	assert.True(t, ok)
+	_, ok = c.Get("reports/near")
+	assert.True(t, ok)
+}
+
+func TestCacheInvalidateAcrossAntimeridian(t *testing.T) {
+	c := tile.NewCache(10)
+	c.Put("zones/east", domain.TileLayerZones, domain.BoundsFilter{MinLat: 0, MinLon: 179, MaxLat: 1, MaxLon: 180.5}, domain.Tile{}, time.Minute)
+
+	c.InvalidateTiles([]string{domain.TileLayerZones}, domain.BoundsFilter{MinLat: 0, MinLon: -179.9, MaxLat: 1, MaxLon: -179.8})
+
+	_, ok := c.Get("zones/east")
+	assert.False(t, ok)
+}
@@ -1,10 +1,13 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
+	"log"
 	"sync"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/realtime"
 	"github.com/gorilla/websocket"
 )
 
@@ -12,14 +15,28 @@ type Client struct {
 	Conn          *websocket.Conn
 	UserID        string
 	Subscriptions map[string]bool
+	Viewport      *domain.Viewport // vùng bản đồ đang xem, nil = không nhận zone_event
+
+	writeMu sync.Mutex // gorilla/websocket không cho ghi đồng thời trên một connection
+}
+
+// Write gửi một frame; mọi chỗ ghi vào Conn phải đi qua đây
+func (c *Client) Write(frame string) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.Conn.WriteMessage(websocket.TextMessage, []byte(frame))
 }
 
 type WSManager struct {
 	mu    sync.RWMutex
 	users map[string][]*Client
 	temp  []*Client
+
+	sharing domain.LocationSharing // nil = không gửi location cho ai
 }
 
+var _ realtime.Broadcaster = (*WSManager)(nil)
+
 func NewWSManager() *WSManager {
 	return &WSManager{
 		users: make(map[string][]*Client),
@@ -86,26 +103,142 @@ func (m *WSManager) Unsubscribe(c *Client, targetUserID string) {
 	}
 }
 
+// SetViewport đăng ký nhận thay đổi zone trong vùng bản đồ client đang xem
+func (m *WSManager) SetViewport(c *Client, v domain.Viewport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c.Viewport = &v
+}
+
+func (m *WSManager) ClearViewport(c *Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c.Viewport = nil
+}
+
+// NotifyZone gửi "zone_event" tới các client có viewport chạm vào zone,
+// và vẫn broadcast "zone_label" / "zone_replaced" cho client chưa gửi viewport
+func (m *WSManager) NotifyZone(e domain.ZoneEvent) {
+	m.SendToViewport("zone_event", e.Zone.Center.Coordinates[1], e.Zone.Center.Coordinates[0], e.Zone.Radius, e)
+
+	switch {
+	case e.Type == domain.ZoneEventMerged:
+		m.BroadcastAll("zone_replaced", map[string]interface{}{
+			"replaced": e.Replaced,
+			"zone":     e.Zone,
+		})
+	case e.Cause == domain.ZoneCauseDecay && (e.Type == domain.ZoneEventDeleted || e.Zone.Label != e.PreviousLabel):
+		m.BroadcastAll("zone_label", map[string]interface{}{
+			"zoneId":        e.Zone.ID.Hex(),
+			"label":         e.Zone.Label,
+			"previousLabel": e.PreviousLabel,
+			"riskScore":     e.Zone.RiskScore,
+			"removed":       e.Type == domain.ZoneEventDeleted,
+		})
+	}
+}
+
+// NotifyShelter gửi "shelter_event" (đổi số chỗ, mở / đóng...) tới các client có viewport chứa shelter.
+// Client chưa gửi viewport (đang đi tới shelter, không mở bản đồ) cũng nhận để không bị dẫn tới shelter đã đầy.
+func (m *WSManager) NotifyShelter(e domain.ShelterEvent) {
+	lat, lon := e.Shelter.Location.Coordinates[1], e.Shelter.Location.Coordinates[0]
+
+	m.mu.RLock()
+	clients := []*Client{}
+	for _, list := range m.users {
+		for _, c := range list {
+			if c.Viewport == nil || c.Viewport.IntersectsCircle(lat, lon, 0) {
+				clients = append(clients, c)
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, c := range clients {
+		_ = m.SendToClient(c, "shelter_event", e)
+	}
+}
+
+// SendToViewport gửi payload tới các client có viewport chạm vòng tròn (lat, lon, radiusM)
+func (m *WSManager) SendToViewport(destination string, lat, lon, radiusM float64, payload interface{}) {
+	m.SendToUserAndViewport("", destination, lat, lon, radiusM, payload)
+}
+
+// SendToUserAndViewport như SendToViewport, thêm mọi kết nối của userID (mỗi kết nối nhận một lần)
+func (m *WSManager) SendToUserAndViewport(userID, destination string, lat, lon, radiusM float64, payload interface{}) {
+	m.mu.RLock()
+	clients := []*Client{}
+	for uid, list := range m.users {
+		for _, c := range list {
+			if (userID != "" && uid == userID) || (c.Viewport != nil && c.Viewport.IntersectsCircle(lat, lon, radiusM)) {
+				clients = append(clients, c)
+			}
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, c := range clients {
+		_ = m.SendToClient(c, destination, payload)
+	}
+}
+
+// SetLocationSharing gắn chính sách chia sẻ vị trí, gọi một lần lúc khởi tạo
+func (m *WSManager) SetLocationSharing(s domain.LocationSharing) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sharing = s
+}
+
 // ----------------------------
 // Broadcast Location object
 // ----------------------------
+// chỉ client đã SUBSCRIBE và được user chia sẻ vị trí mới nhận, với độ chính xác user đã chọn
 func (m *WSManager) BroadcastLocation(userID string, loc *domain.Location) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	sharing := m.sharing
+	m.mu.RUnlock()
+	if sharing == nil {
+		return
+	}
 
-	data, err := json.Marshal(loc)
+	// Audience tự đặt timeout theo cấu hình
+	audience, err := sharing.Audience(context.Background(), userID, loc)
+	if err != nil {
+		log.Println("ws: location audience failed:", err)
+		return
+	}
+	if len(audience.Viewers) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(audience.Location)
 	if err != nil {
 		return // nếu marshal lỗi thì bỏ
 	}
 
-	for _, clients := range m.users {
+	m.mu.RLock()
+	targets := []*Client{}
+	for viewerID, clients := range m.users {
+		if !audience.Viewers[viewerID] {
+			continue
+		}
 		for _, c := range clients {
 			if c.Subscriptions != nil && c.Subscriptions[userID] {
-				msg := "MESSAGE\ncontent-type:application/json\n\n" + string(data) + "\x00"
-				c.Conn.WriteMessage(websocket.TextMessage, []byte(msg))
+				targets = append(targets, c)
 			}
 		}
 	}
+	m.mu.RUnlock()
+
+	msg := "MESSAGE\ncontent-type:application/json\n\n" + string(data) + "\x00"
+	for _, c := range targets {
+		_ = c.Write(msg)
+	}
+}
+
+// BroadcastPresence: presence qua REST đi như location qua WS
+func (m *WSManager) BroadcastPresence(userID string, loc *domain.Location) {
+	m.BroadcastLocation(userID, loc)
 }
 
 func (w *WSManager) GetConnByUser(userID string) *websocket.Conn {
@@ -128,30 +261,48 @@ func (w *WSManager) SendToClient(c *Client, destination string, payload interfac
 		"content-type:application/json\n\n" +
 		string(data) + "\x00"
 
-	return c.Conn.WriteMessage(websocket.TextMessage, []byte(frame))
+	return c.Write(frame)
 }
 
-func (m *WSManager) BroadcastSOS(userIDs []string, alert *domain.Alert) {
+// SendToUser gửi payload tới mọi kết nối đang mở của user (dùng khi không còn giữ *Client, vd job chạy lại sau restart)
+func (m *WSManager) SendToUser(userID string, destination string, payload interface{}) {
 	m.mu.RLock()
-	defer m.mu.RUnlock()
+	clients := append([]*Client(nil), m.users[userID]...)
+	m.mu.RUnlock()
 
-	data, err := json.Marshal(alert)
-	if err != nil {
-		return
+	for _, c := range clients {
+		_ = m.SendToClient(c, destination, payload)
+	}
+}
+
+// BroadcastAll gửi payload tới mọi client đã CONNECT
+func (m *WSManager) BroadcastAll(destination string, payload interface{}) {
+	m.mu.RLock()
+	clients := []*Client{}
+	for _, list := range m.users {
+		clients = append(clients, list...)
 	}
+	m.mu.RUnlock()
+
+	for _, c := range clients {
+		_ = m.SendToClient(c, destination, payload)
+	}
+}
 
+func (m *WSManager) BroadcastSOS(userIDs []string, alert *domain.Alert) {
+	m.SendToUsers(userIDs, "alert_broadcast", alert)
+}
+
+// SendToUsers gửi payload tới mọi kết nối đang mở của từng user trong userIDs
+func (m *WSManager) SendToUsers(userIDs []string, destination string, payload interface{}) {
+	m.mu.RLock()
+	clients := []*Client{}
 	for _, uid := range userIDs {
-		clients, ok := m.users[uid]
-		if !ok {
-			continue
-		}
-		for _, c := range clients {
-			frame := "SEND\n" +
-				"destination:/user/" + c.UserID + "/alert_broadcast\n" +
-				"content-type:application/json\n\n" +
-				string(data) + "\x00"
+		clients = append(clients, m.users[uid]...)
+	}
+	m.mu.RUnlock()
 
-			c.Conn.WriteMessage(websocket.TextMessage, []byte(frame))
-		}
+	for _, c := range clients {
+		_ = m.SendToClient(c, destination, payload)
 	}
 }
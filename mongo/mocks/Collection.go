@@ -48,6 +48,73 @@ func (_m *Collection) Aggregate(_a0 context.Context, _a1 interface{}) (mongo.Cur
 	return r0, r1
 }
 
+// BulkWrite provides a mock function with given fields: _a0, _a1, _a2
+func (_m *Collection) BulkWrite(_a0 context.Context, _a1 []mongo_drivermongo.WriteModel, _a2 ...*options.BulkWriteOptions) (*mongo_drivermongo.BulkWriteResult, error) {
+	_va := make([]interface{}, len(_a2))
+	for _i := range _a2 {
+		_va[_i] = _a2[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _a0, _a1)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BulkWrite")
+	}
+
+	var r0 *mongo_drivermongo.BulkWriteResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []mongo_drivermongo.WriteModel, ...*options.BulkWriteOptions) (*mongo_drivermongo.BulkWriteResult, error)); ok {
+		return rf(_a0, _a1, _a2...)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []mongo_drivermongo.WriteModel, ...*options.BulkWriteOptions) *mongo_drivermongo.BulkWriteResult); ok {
+		r0 = rf(_a0, _a1, _a2...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*mongo_drivermongo.BulkWriteResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []mongo_drivermongo.WriteModel, ...*options.BulkWriteOptions) error); ok {
+		r1 = rf(_a0, _a1, _a2...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateIndexes provides a mock function with given fields: _a0, _a1
+func (_m *Collection) CreateIndexes(_a0 context.Context, _a1 []mongo_drivermongo.IndexModel) ([]string, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIndexes")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []mongo_drivermongo.IndexModel) ([]string, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []mongo_drivermongo.IndexModel) []string); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []mongo_drivermongo.IndexModel) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CountDocuments provides a mock function with given fields: _a0, _a1, _a2
 func (_m *Collection) CountDocuments(_a0 context.Context, _a1 interface{}, _a2 ...*options.CountOptions) (int64, error) {
 	_va := make([]interface{}, len(_a2))
@@ -83,6 +150,34 @@ func (_m *Collection) CountDocuments(_a0 context.Context, _a1 interface{}, _a2 .
 	return r0, r1
 }
 
+// DeleteMany provides a mock function with given fields: _a0, _a1
+func (_m *Collection) DeleteMany(_a0 context.Context, _a1 interface{}) (int64, error) {
+	ret := _m.Called(_a0, _a1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteMany")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}) (int64, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, interface{}) int64); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, interface{}) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteOne provides a mock function with given fields: _a0, _a1
 func (_m *Collection) DeleteOne(_a0 context.Context, _a1 interface{}) (int64, error) {
 	ret := _m.Called(_a0, _a1)
@@ -8,6 +8,7 @@ import (
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type alertRepository struct {
@@ -97,6 +98,80 @@ func (r *alertRepository) FetchByRadius(ctx context.Context, lat, lng, km float6
 	}, nil
 }
 
+// FetchActiveInBounds lấy alert còn hiệu lực (alert không có TTL thì không hết hạn)
+func (r *alertRepository) FetchActiveInBounds(ctx context.Context, b domain.BoundsFilter) ([]*domain.Alert, error) {
+	collection := r.database.Collection(r.collection)
+
+	filter := bson.M{
+		"$and": []bson.M{
+			geoBoxFilter("location", "$geoWithin", b),
+			{"status": bson.M{"$ne": "RESOLVED"}},
+			{"$or": []bson.M{
+				{"expires_at": bson.M{"$gt": time.Now()}},
+				{"ttl_min": bson.M{"$lte": 0}},
+			}},
+		},
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var alerts []*domain.Alert
+	if err := cursor.All(ctx, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+func (r *alertRepository) HasActiveByUser(ctx context.Context, userID string) (bool, error) {
+	n, err := r.database.Collection(r.collection).CountDocuments(ctx, bson.M{
+		"userID": userID,
+		"status": bson.M{"$ne": "RESOLVED"},
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$gt": time.Now()}},
+			{"ttl_min": bson.M{"$lte": 0}},
+		},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *alertRepository) ActiveUserIDs(ctx context.Context, userIDs []string) ([]string, error) {
+	ids := []string{}
+	if len(userIDs) == 0 {
+		return ids, nil
+	}
+	opts := options.Find().SetProjection(bson.M{"userID": 1})
+	cursor, err := r.database.Collection(r.collection).Find(ctx, bson.M{
+		"userID": bson.M{"$in": userIDs},
+		"status": bson.M{"$ne": "RESOLVED"},
+		"$or": []bson.M{
+			{"expires_at": bson.M{"$gt": time.Now()}},
+			{"ttl_min": bson.M{"$lte": 0}},
+		},
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		UserID string `bson:"userID"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, d := range docs {
+		if !seen[d.UserID] {
+			seen[d.UserID] = true
+			ids = append(ids, d.UserID)
+		}
+	}
+	return ids, nil
+}
+
 // ---------- repository/alert_repository.go ----------
 func (r *alertRepository) GetNearbyAlerts(ctx context.Context, lat, lon, km float64) ([]*domain.Alert, error) {
 	collection := r.database.Collection(r.collection)
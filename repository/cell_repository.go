@@ -2,10 +2,15 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -21,13 +26,12 @@ func NewCellRepository(db mongo.Database, collection string) domain.CellReposito
 	}
 }
 
-func (r *cellRepository) GetByLatLon(ctx context.Context, lat, lon float64) (*domain.Cell, error) {
+func (r *cellRepository) GetByHash(ctx context.Context, hash string) (*domain.Cell, error) {
 	col := r.db.Collection(r.collection)
-	filter := bson.M{"center": [2]float64{lon, lat}} // snapGrid đã được áp dụng
 	var cell domain.Cell
-	err := col.FindOne(ctx, filter).Decode(&cell)
+	err := col.FindOne(ctx, bson.M{"hash": hash}).Decode(&cell)
 	if err != nil {
-		if err.Error() == "mongo: no documents in result" {
+		if errors.Is(err, mongodriver.ErrNoDocuments) {
 			return nil, nil
 		}
 		return nil, err
@@ -43,7 +47,7 @@ func (r *cellRepository) GetByRadius(ctx context.Context, lat, lon, radius float
 			"$geoWithin": bson.M{
 				"$centerSphere": []interface{}{
 					[]float64{lon, lat},
-					radius / 6371000.0, // convert meters to radians
+					radius / geo.EarthRadiusM, // convert meters to radians
 				},
 			},
 		},
@@ -62,11 +66,38 @@ func (r *cellRepository) GetByRadius(ctx context.Context, lat, lon, radius float
 	return cells, nil
 }
 
+// AggregateInBounds gộp cell theo prefix hash, level = CellLevel thì mỗi cell là một nhóm
+func (r *cellRepository) AggregateInBounds(ctx context.Context, b domain.BoundsFilter, level int) ([]domain.CellAggregate, error) {
+	col := r.db.Collection(r.collection)
+
+	pipeline := mongodriver.Pipeline{
+		{{Key: "$match", Value: geoBoxFilter("center", "$geoWithin", b)}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       bson.M{"$substrBytes": bson.A{"$hash", 0, level}},
+			"cellRisk":  bson.M{"$max": "$riskScore"},
+			"avgRisk":   bson.M{"$avg": "$riskScore"},
+			"count":     bson.M{"$sum": 1},
+			"updatedAt": bson.M{"$max": "$updatedAt"},
+		}}},
+	}
+
+	cur, err := col.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.CellAggregate
+	if err := cur.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (r *cellRepository) Upsert(ctx context.Context, cell *domain.Cell) error {
 	col := r.db.Collection(r.collection)
-	filter := bson.M{"center": cell.Center}
+	filter := bson.M{"hash": cell.Hash}
 	update := bson.M{
 		"$set": bson.M{
+			"center":    cell.Center,
 			"riskScore": cell.RiskScore,
 			"label":     cell.Label,
 			"updatedAt": cell.UpdatedAt,
@@ -77,11 +108,112 @@ func (r *cellRepository) Upsert(ctx context.Context, cell *domain.Cell) error {
 	return err
 }
 
-func (r *cellRepository) UpsertMany(ctx context.Context, cells []*domain.Cell) error {
+func (r *cellRepository) UpsertMany(ctx context.Context, cells []*domain.Cell) (int, int, error) {
+	if len(cells) == 0 {
+		return 0, 0, nil
+	}
+
+	models := make([]mongodriver.WriteModel, 0, len(cells))
 	for _, cell := range cells {
-		if err := r.Upsert(ctx, cell); err != nil {
-			return err
+		models = append(models, mongodriver.NewUpdateOneModel().
+			SetFilter(bson.M{"hash": cell.Hash}).
+			SetUpdate(bson.M{"$set": bson.M{
+				"center":    cell.Center,
+				"riskScore": cell.RiskScore,
+				"label":     cell.Label,
+				"updatedAt": cell.UpdatedAt,
+			}}).
+			SetUpsert(true))
+	}
+	return r.bulk(ctx, models)
+}
+
+// IncrementMany: update pipeline cộng và chặn risk ngay trong DB (như $inc nhưng có min / max),
+// hai request cùng cộng vào một cell không ghi đè lên nhau
+func (r *cellRepository) IncrementMany(ctx context.Context, hashes []string, inc float64, label string, at time.Time) (int, int, error) {
+	if len(hashes) == 0 {
+		return 0, 0, nil
+	}
+
+	risk := bson.M{"$min": bson.A{1.0, bson.M{"$max": bson.A{0.0, bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$riskScore", 0.0}}, inc}}}}}}
+	var labelExpr interface{} = label
+	if label == "" {
+		labelExpr = riskLabelExpr("$riskScore")
+	}
+
+	models := make([]mongodriver.WriteModel, 0, len(hashes))
+	for _, hash := range hashes {
+		models = append(models, mongodriver.NewUpdateOneModel().
+			SetFilter(bson.M{"hash": hash}).
+			SetUpdate(bson.A{
+				bson.M{"$set": bson.M{"center": geo.CellCenter(hash), "riskScore": risk, "updatedAt": at}},
+				// stage sau thấy riskScore mới
+				bson.M{"$set": bson.M{"label": labelExpr}},
+			}).
+			SetUpsert(true))
+	}
+	return r.bulk(ctx, models)
+}
+
+// riskLabelExpr — domain.RiskLabel viết bằng biểu thức aggregation
+func riskLabelExpr(field string) bson.M {
+	return bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{"case": bson.M{"$lt": bson.A{field, 0.3}}, "then": "LOW"},
+			bson.M{"case": bson.M{"$lt": bson.A{field, 0.6}}, "then": "MEDIUM"},
+		},
+		"default": "HIGH",
+	}}
+}
+
+// bulk chạy BulkWrite không theo thứ tự, trả về số cell đã có (updated) và số cell tạo mới (inserted)
+func (r *cellRepository) bulk(ctx context.Context, models []mongodriver.WriteModel) (int, int, error) {
+	col := r.db.Collection(r.collection)
+	res, err := col.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(res.MatchedCount), int(res.UpsertedCount), nil
+}
+
+// BackfillCellHashes chuyển cell của lưới 0.001° cũ sang geohash, chạy lúc khởi động trước khi
+// tạo unique index trên hash. Nhiều cell cũ rơi vào cùng một cell mới thì giữ risk cao nhất.
+func BackfillCellHashes(ctx context.Context, db mongo.Database) (int, error) {
+	col := db.Collection(domain.CollectionCell)
+
+	cur, err := col.Find(ctx, bson.M{"hash": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, err
+	}
+	var cells []domain.Cell
+	if err := cur.All(ctx, &cells); err != nil {
+		return 0, err
+	}
+
+	best := map[string]domain.Cell{}
+	var drop []primitive.ObjectID
+	for _, c := range cells {
+		c.Hash = geo.Encode(c.Center[1], c.Center[0], domain.CellLevel)
+		if prev, ok := best[c.Hash]; ok {
+			if prev.RiskScore >= c.RiskScore {
+				drop = append(drop, c.ID)
+				continue
+			}
+			drop = append(drop, prev.ID)
+		}
+		best[c.Hash] = c
+	}
+
+	for hash, c := range best {
+		update := bson.M{"$set": bson.M{"hash": hash, "center": geo.CellCenter(hash)}}
+		if _, err := col.UpdateOne(ctx, bson.M{"_id": c.ID}, update); err != nil {
+			return 0, err
+		}
+	}
+	if len(drop) > 0 {
+		if _, err := col.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": drop}}); err != nil {
+			return 0, err
 		}
 	}
-	return nil
+	return len(cells), nil
 }
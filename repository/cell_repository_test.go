@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo/mocks"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCellIncrementManyClamps(t *testing.T) {
+	databaseHelper := &mocks.Database{}
+	collectionHelper := &mocks.Collection{}
+	databaseHelper.On("Collection", domain.CollectionCell).Return(collectionHelper)
+
+	var models []mongodriver.WriteModel
+	collectionHelper.On("BulkWrite", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { models = args.Get(1).([]mongodriver.WriteModel) }).
+		Return(&mongodriver.BulkWriteResult{MatchedCount: 1, UpsertedCount: 1}, nil).Once()
+
+	cr := repository.NewCellRepository(databaseHelper, domain.CollectionCell)
+	updated, inserted, err := cr.IncrementMany(context.Background(), []string{"w3gv2m8", "w3gv2m9"}, 0.4, "", time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, 1, inserted)
+	require.Len(t, models, 2)
+
+	// update dạng pipeline: risk = min(1, max(0, risk + inc)), rồi label tính từ risk mới
+	m := models[0].(*mongodriver.UpdateOneModel)
+	assert.True(t, *m.Upsert)
+	assert.Equal(t, bson.M{"hash": "w3gv2m8"}, m.Filter)
+	stages := m.Update.(bson.A)
+	require.Len(t, stages, 2)
+	risk := stages[0].(bson.M)["$set"].(bson.M)["riskScore"].(bson.M)
+	clamp := risk["$min"].(bson.A)
+	assert.Equal(t, 1.0, clamp[0])
+	assert.Equal(t, 0.0, clamp[1].(bson.M)["$max"].(bson.A)[0])
+	assert.Contains(t, stages[1].(bson.M)["$set"].(bson.M), "label")
+}
+
+func TestCellUpsertManySets(t *testing.T) {
+	databaseHelper := &mocks.Database{}
+	collectionHelper := &mocks.Collection{}
+	databaseHelper.On("Collection", domain.CollectionCell).Return(collectionHelper)
+
+	var models []mongodriver.WriteModel
+	collectionHelper.On("BulkWrite", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { models = args.Get(1).([]mongodriver.WriteModel) }).
+		Return(&mongodriver.BulkWriteResult{UpsertedCount: 1}, nil).Once()
+
+	cr := repository.NewCellRepository(databaseHelper, domain.CollectionCell)
+	_, _, err := cr.UpsertMany(context.Background(), []*domain.Cell{{Hash: "w3gv2m8", RiskScore: 0.5, Label: "MEDIUM"}})
+	require.NoError(t, err)
+	require.Len(t, models, 1)
+
+	// set: ghi đè giá trị, không cộng dồn
+	set := models[0].(*mongodriver.UpdateOneModel).Update.(bson.M)["$set"].(bson.M)
+	assert.Equal(t, 0.5, set["riskScore"])
+	assert.Equal(t, "MEDIUM", set["label"])
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type enrichmentJobRepository struct {
+	database   mongo.Database
+	collection string
+}
+
+func NewEnrichmentJobRepo(db mongo.Database, collection string) domain.EnrichmentJobRepository {
+	return &enrichmentJobRepository{
+		database:   db,
+		collection: collection,
+	}
+}
+
+// Enqueue upsert theo _id = reportID, chỉ set khi insert
+func (r *enrichmentJobRepository) Enqueue(ctx context.Context, reportID primitive.ObjectID, runAt time.Time, claim primitive.ObjectID) error {
+	coll := r.database.Collection(r.collection)
+	now := time.Now()
+
+	_, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": reportID},
+		bson.M{"$setOnInsert": bson.M{
+			"status":      domain.JobStatusQueued,
+			"attempts":    0,
+			"next_run_at": runAt,
+			"claim":       claim,
+			"created_at":  now,
+			"updated_at":  now,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Restart reset job về QUEUED, tạo mới nếu chưa có
+func (r *enrichmentJobRepository) Restart(ctx context.Context, reportID primitive.ObjectID, runAt time.Time) error {
+	coll := r.database.Collection(r.collection)
+	now := time.Now()
+
+	_, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": reportID},
+		bson.M{
+			"$set": bson.M{
+				"status":      domain.JobStatusQueued,
+				"attempts":    0,
+				"next_run_at": runAt,
+				"last_error":  "",
+				"updated_at":  now,
+			},
+			"$unset":       bson.M{"claim": ""},
+			"$setOnInsert": bson.M{"created_at": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// ClaimDue lấy job QUEUED đã đến hạn, đẩy next_run_at ra sau lease.
+// Chỉ job nào update thành công (next_run_at chưa bị đổi) mới được trả về.
+func (r *enrichmentJobRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.EnrichmentJob, error) {
+	coll := r.database.Collection(r.collection)
+
+	filter := bson.M{
+		"status":      domain.JobStatusQueued,
+		"next_run_at": bson.M{"$lte": now},
+	}
+	opts := options.Find().SetSort(bson.M{"next_run_at": 1}).SetLimit(int64(limit))
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var due []domain.EnrichmentJob
+	if err := cursor.All(ctx, &due); err != nil {
+		return nil, err
+	}
+
+	claimed := make([]domain.EnrichmentJob, 0, len(due))
+	for _, job := range due {
+		job.Claim = primitive.NewObjectID()
+		res, err := coll.UpdateOne(
+			ctx,
+			bson.M{"_id": job.ID, "status": domain.JobStatusQueued, "next_run_at": job.NextRunAt},
+			bson.M{"$set": bson.M{"next_run_at": now.Add(lease), "claim": job.Claim, "updated_at": now}},
+		)
+		if err != nil {
+			return claimed, err
+		}
+		if res.ModifiedCount == 1 {
+			claimed = append(claimed, job)
+		}
+	}
+	return claimed, nil
+}
+
+// Renew đẩy next_run_at ra sau until nếu job vẫn QUEUED và còn giữ claim
+func (r *enrichmentJobRepository) Renew(ctx context.Context, job domain.EnrichmentJob, until time.Time) (bool, error) {
+	coll := r.database.Collection(r.collection)
+	res, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": job.ID, "status": domain.JobStatusQueued, "claim": job.Claim},
+		bson.M{"$set": bson.M{"next_run_at": until, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// Reschedule lưu số lần thử và thời điểm retry tiếp theo
+func (r *enrichmentJobRepository) Reschedule(ctx context.Context, job domain.EnrichmentJob, attempts int, nextRunAt time.Time, lastErr string) error {
+	coll := r.database.Collection(r.collection)
+	_, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": job.ID, "claim": job.Claim},
+		bson.M{"$set": bson.M{
+			"status":      domain.JobStatusQueued,
+			"attempts":    attempts,
+			"next_run_at": nextRunAt,
+			"last_error":  lastErr,
+			"updated_at":  time.Now(),
+		}},
+	)
+	return err
+}
+
+// Finish đóng job với trạng thái DONE hoặc FAILED
+func (r *enrichmentJobRepository) Finish(ctx context.Context, job domain.EnrichmentJob, status string, attempts int, lastErr string) error {
+	coll := r.database.Collection(r.collection)
+	_, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": job.ID, "claim": job.Claim},
+		bson.M{"$set": bson.M{
+			"status":     status,
+			"attempts":   attempts,
+			"last_error": lastErr,
+			"updated_at": time.Now(),
+		}},
+	)
+	return err
+}
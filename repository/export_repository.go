@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type exportRepository struct {
+	db mongo.Database
+}
+
+// NewExportRepo đọc trực tiếp các collection report / alert / zone / cell
+func NewExportRepo(db mongo.Database) domain.ExportRepository {
+	return &exportRepository{db: db}
+}
+
+func (r *exportRepository) StreamReports(ctx context.Context, f domain.ExportFilter, fn func(*domain.Report) error) error {
+	// giống GetNearbyReports: bỏ report đã rút lại
+	filter := bson.M{"status": bson.M{"$ne": domain.ReportStatusRetracted}}
+	if f.Near != nil {
+		filter["location"] = withinCircle(f.Near)
+	}
+	return stream(ctx, r.db.Collection(domain.CollectionReport), filter, fn)
+}
+
+func (r *exportRepository) StreamAlerts(ctx context.Context, f domain.ExportFilter, fn func(*domain.Alert) error) error {
+	filter := bson.M{}
+	if f.Near != nil {
+		filter["location"] = withinCircle(f.Near)
+	}
+	return stream(ctx, r.db.Collection(domain.CollectionAlert), filter, fn)
+}
+
+func (r *exportRepository) StreamZones(ctx context.Context, f domain.ExportFilter, fn func(*domain.Zone) error) error {
+	filter := bson.M{}
+	if f.Bounds != nil {
+		// giống zoneRepository.FetchInBounds
+		filter["center.coordinates.0"] = bson.M{"$gte": f.Bounds.MinLon, "$lte": f.Bounds.MaxLon}
+		filter["center.coordinates.1"] = bson.M{"$gte": f.Bounds.MinLat, "$lte": f.Bounds.MaxLat}
+	}
+	return stream(ctx, r.db.Collection(domain.CollectionZone), filter, fn)
+}
+
+func (r *exportRepository) StreamCells(ctx context.Context, f domain.ExportFilter, fn func(*domain.Cell) error) error {
+	filter := bson.M{}
+	if f.Near != nil {
+		filter["center"] = withinCircle(f.Near)
+	}
+	return stream(ctx, r.db.Collection(domain.CollectionCell), filter, fn)
+}
+
+// $geoWithin không sắp xếp theo khoảng cách như $near nên hợp với export số lượng lớn
+func withinCircle(n *domain.NearFilter) bson.M {
+	return bson.M{
+		"$geoWithin": bson.M{
+			"$centerSphere": []interface{}{
+				[]float64{n.Lon, n.Lat},
+				n.RadiusM / geo.EarthRadiusM,
+			},
+		},
+	}
+}
+
+func stream[T any](ctx context.Context, coll mongo.Collection, filter bson.M, fn func(*T) error) error {
+	cursor, err := coll.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc T
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err := fn(&doc); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
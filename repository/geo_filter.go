@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// geoBoxFilter lọc field địa lý theo bounding box bằng index 2dsphere.
+// op: "$geoWithin" cho điểm, "$geoIntersects" cho polygon (zone.area).
+func geoBoxFilter(field, op string, b domain.BoundsFilter) bson.M {
+	rings := geo.BoxRings(b.MinLat, b.MinLon, b.MaxLat, b.MaxLon)
+	or := make([]bson.M, len(rings))
+	for i, ring := range rings {
+		or[i] = bson.M{field: bson.M{op: bson.M{
+			"$geometry": bson.M{"type": "Polygon", "coordinates": [][][2]float64{ring}},
+		}}}
+	}
+	return bson.M{"$or": or}
+}
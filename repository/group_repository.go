@@ -2,11 +2,15 @@ package repository
 
 import (
 	"context"
+	"strconv"
+	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/invite"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 )
 
 type groupRepository struct {
@@ -25,6 +29,9 @@ func NewGroupRepository(db mongo.Database, collection string) domain.GroupReposi
 func (r *groupRepository) Create(ctx context.Context, g *domain.Group) error {
 	col := r.database.Collection(r.collection)
 	_, err := col.InsertOne(ctx, g)
+	if mongodriver.IsDuplicateKeyError(err) {
+		return domain.ErrInviteCodeTaken
+	}
 	return err
 }
 
@@ -51,6 +58,21 @@ func (r *groupRepository) GetByID(ctx context.Context, id primitive.ObjectID) (d
 	return group, err
 }
 
+// Lấy nhiều group bằng ID
+func (r *groupRepository) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Group, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	col := r.database.Collection(r.collection)
+	cursor, err := col.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	var groups []domain.Group
+	err = cursor.All(ctx, &groups)
+	return groups, err
+}
+
 func (r *groupRepository) AddMember(ctx context.Context, groupID primitive.ObjectID, userID primitive.ObjectID) error {
 	collection := r.database.Collection(r.collection)
 	filter := bson.M{"_id": groupID}
@@ -59,3 +81,83 @@ func (r *groupRepository) AddMember(ctx context.Context, groupID primitive.Objec
 	_, err := collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+func (r *groupRepository) RemoveMember(ctx context.Context, groupID primitive.ObjectID, userID primitive.ObjectID) error {
+	collection := r.database.Collection(r.collection)
+	filter := bson.M{"_id": groupID}
+	update := bson.M{"$pull": bson.M{"memberIDs": userID}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// Mã mới thay mã cũ, đếm lại số lần dùng từ 0
+func (r *groupRepository) SetInvite(ctx context.Context, groupID primitive.ObjectID, code string, expiresAt time.Time, maxUses int) error {
+	collection := r.database.Collection(r.collection)
+	update := bson.M{"$set": bson.M{
+		"inviteCode":    code,
+		"expiresAt":     expiresAt,
+		"inviteMaxUses": maxUses,
+		"inviteUses":    0,
+	}}
+
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": groupID}, update)
+	if mongodriver.IsDuplicateKeyError(err) {
+		return domain.ErrInviteCodeTaken
+	}
+	return err
+}
+
+// Bỏ field inviteCode (không set rỗng vì unique index chỉ bỏ qua doc thiếu field)
+func (r *groupRepository) RevokeInvite(ctx context.Context, groupID primitive.ObjectID) error {
+	collection := r.database.Collection(r.collection)
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": groupID}, bson.M{"$unset": bson.M{"inviteCode": ""}})
+	return err
+}
+
+// Điều kiện nằm trong filter nên hai người join cùng lúc không vượt quá maxUses
+func (r *groupRepository) UseInvite(ctx context.Context, groupID primitive.ObjectID, code string, now time.Time) (bool, error) {
+	collection := r.database.Collection(r.collection)
+	filter := bson.M{
+		"_id":        groupID,
+		"inviteCode": code,
+		"expiresAt":  bson.M{"$gt": now},
+		"$or": bson.A{
+			bson.M{"inviteMaxUses": bson.M{"$not": bson.M{"$gt": 0}}}, // 0 hoặc chưa có field
+			bson.M{"$expr": bson.M{"$lt": bson.A{"$inviteUses", "$inviteMaxUses"}}},
+		},
+	}
+
+	res, err := collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"inviteUses": 1}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// BackfillInviteCodes thay mã mời kiểu cũ (6 ký tự hex, đoán được) bằng mã ngẫu nhiên, giữ nguyên hạn.
+// Chạy trước EnsureIndexes vì mã cũ có thể trùng nhau, làm hỏng unique index.
+func BackfillInviteCodes(ctx context.Context, db mongo.Database) (int, error) {
+	col := db.Collection(domain.CollectionGroup)
+
+	pattern := "^[" + invite.Alphabet + "]{" + strconv.Itoa(invite.Length) + "}$"
+	cur, err := col.Find(ctx, bson.M{"inviteCode": bson.M{"$exists": true, "$not": bson.M{"$regex": pattern}}})
+	if err != nil {
+		return 0, err
+	}
+	var groups []domain.Group
+	if err := cur.All(ctx, &groups); err != nil {
+		return 0, err
+	}
+
+	for _, g := range groups {
+		code, err := invite.NewCode()
+		if err != nil {
+			return 0, err
+		}
+		if _, err := col.UpdateOne(ctx, bson.M{"_id": g.ID}, bson.M{"$set": bson.M{"inviteCode": code}}); err != nil {
+			return 0, err
+		}
+	}
+	return len(groups), nil
+}
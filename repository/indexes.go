@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// geoIndexes: mọi field GeoJSON / [lon, lat] được query bằng $near, $geoWithin, $geoIntersects
+var geoIndexes = []struct {
+	collection string
+	fields     []string
+}{
+	{domain.CollectionAlert, []string{"location"}},
+	{domain.CollectionLocation, []string{"location"}},
+	{domain.CollectionReport, []string{"location"}},
+	{domain.CollectionCell, []string{"center"}},
+	{domain.CollectionZone, []string{"center", "area"}},
+	{domain.CollectionZoneHistory, []string{"center"}},
+	{domain.CollectionShelter, []string{"location"}},
+}
+
+// các index khác cần có để dữ liệu đúng (khóa duy nhất)
+var extraIndexes = map[string][]mongodriver.IndexModel{
+	domain.CollectionCell: {
+		{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+	},
+	domain.CollectionReport: {
+		// sparse: report tạo trực tiếp (không gửi bù) không có client_key
+		{Keys: bson.D{{Key: "client_key", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+	},
+	domain.CollectionLocation: {
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "updated_at", Value: 1}}},
+	},
+	domain.CollectionGroup: {
+		// sparse: nhóm đã thu hồi mã (không có field) không bị tính là trùng
+		{Keys: bson.D{{Key: "inviteCode", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+	},
+	domain.CollectionMember: {
+		{Keys: bson.D{{Key: "groupId", Value: 1}, {Key: "userId", Value: 1}}},
+	},
+	domain.CollectionLocationHistory: {
+		// mỗi fix một điểm: fix gửi bù (cũ hơn cuối trail) vẫn được chèn mà không trùng
+		{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "timestamp", Value: 1}}, Options: options.Index().SetUnique(true)},
+		// mỗi điểm tự mang hạn xóa nên đổi thời gian lưu trữ không cần tạo lại index
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	},
+}
+
+// EnsureIndexes tạo index 2dsphere cho các collection địa lý, gọi lúc khởi động.
+// CreateIndexes không làm gì nếu index đã tồn tại với cùng key.
+func EnsureIndexes(ctx context.Context, db mongo.Database) error {
+	for _, g := range geoIndexes {
+		models := make([]mongodriver.IndexModel, len(g.fields))
+		for i, f := range g.fields {
+			models[i] = mongodriver.IndexModel{Keys: bson.D{{Key: f, Value: "2dsphere"}}}
+		}
+		if _, err := db.Collection(g.collection).CreateIndexes(ctx, models); err != nil {
+			return fmt.Errorf("ensure indexes on %s: %w", g.collection, err)
+		}
+	}
+	for collection, models := range extraIndexes {
+		if _, err := db.Collection(collection).CreateIndexes(ctx, models); err != nil {
+			return fmt.Errorf("ensure indexes on %s: %w", collection, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type locationHistoryRepository struct {
+	db         mongo.Database
+	collection string
+}
+
+func NewLocationHistoryRepo(db mongo.Database, collection string) domain.LocationHistoryRepository {
+	return &locationHistoryRepository{db: db, collection: collection}
+}
+
+// Append upsert theo (userId, timestamp) nên gửi lại cùng fix không tạo điểm trùng
+func (r *locationHistoryRepository) Append(ctx context.Context, p *domain.TrackPoint) error {
+	if p.ID.IsZero() {
+		p.ID = primitive.NewObjectID()
+	}
+	_, err := r.db.Collection(r.collection).UpdateOne(ctx,
+		bson.M{"userId": p.UserID, "timestamp": p.Timestamp},
+		bson.M{"$setOnInsert": p},
+		options.Update().SetUpsert(true),
+	)
+	if mongodriver.IsDuplicateKeyError(err) {
+		// hai request cùng fix chạy song song
+		return nil
+	}
+	return err
+}
+
+func (r *locationHistoryRepository) Touch(ctx context.Context, id primitive.ObjectID, seenAt int64, expiresAt time.Time) error {
+	_, err := r.db.Collection(r.collection).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$max": bson.M{"lastSeenAt": seenAt, "expiresAt": expiresAt}},
+	)
+	return err
+}
+
+func (r *locationHistoryRepository) Last(ctx context.Context, userID string) (*domain.TrackPoint, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}).SetLimit(1)
+	cursor, err := r.db.Collection(r.collection).Find(ctx, bson.M{"userId": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		return nil, nil
+	}
+	var p domain.TrackPoint
+	if err := cursor.Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *locationHistoryRepository) Covers(ctx context.Context, userID string, at int64) (bool, error) {
+	n, err := r.db.Collection(r.collection).CountDocuments(ctx, bson.M{
+		"userId":     userID,
+		"timestamp":  bson.M{"$lte": at},
+		"lastSeenAt": bson.M{"$gte": at},
+	})
+	return n > 0, err
+}
+
+func (r *locationHistoryRepository) Range(ctx context.Context, userID string, from, to int64, limit int) ([]domain.TrackPoint, bool, error) {
+	filter := bson.M{
+		"userId":     userID,
+		"timestamp":  bson.M{"$lte": to},
+		"lastSeenAt": bson.M{"$gte": from},
+	}
+	// lấy mới nhất trước để khi cắt thì giữ đoạn cuối của trail
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}}).SetLimit(int64(limit + 1))
+
+	cursor, err := r.db.Collection(r.collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	defer cursor.Close(ctx)
+
+	points := []domain.TrackPoint{}
+	if err := cursor.All(ctx, &points); err != nil {
+		return nil, false, err
+	}
+
+	truncated := len(points) > limit
+	if truncated {
+		points = points[:limit]
+	}
+	for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+		points[i], points[j] = points[j], points[i]
+	}
+	return points, truncated, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -37,6 +39,7 @@ func (r *locationRepository) Upsert(ctx context.Context, loc *domain.Location) e
 					"coordinates": loc.Location.Coordinates, // [lon, lat]
 				},
 			},
+			"$unset": bson.M{"stale_since": "", "last_status": ""},
 		},
 		options.Update().SetUpsert(true),
 	)
@@ -44,6 +47,36 @@ func (r *locationRepository) Upsert(ctx context.Context, loc *domain.Location) e
 	return err
 }
 
+func (r *locationRepository) UpsertIfNewer(ctx context.Context, loc *domain.Location) (bool, error) {
+	coll := r.database.Collection(r.collection)
+
+	// bản đang lưu mới hơn thì filter không khớp, upsert sẽ đụng _id -> duplicate key
+	res, err := coll.UpdateOne(
+		ctx,
+		bson.M{"_id": loc.ID, "updated_at": bson.M{"$lt": loc.UpdatedAt}},
+		bson.M{
+			"$set": bson.M{
+				"accuracy_m": loc.AccuracyM,
+				"status":     loc.Status,
+				"updated_at": loc.UpdatedAt,
+				"location": bson.M{
+					"type":        loc.Location.Type,
+					"coordinates": loc.Location.Coordinates,
+				},
+			},
+			"$unset": bson.M{"stale_since": "", "last_status": ""},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		if mongodriver.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return res.ModifiedCount+res.UpsertedCount > 0, nil
+}
+
 func (r *locationRepository) GetByUserID(ctx context.Context, userID string) (*domain.Location, error) {
 	coll := r.database.Collection(r.collection)
 
@@ -53,6 +86,20 @@ func (r *locationRepository) GetByUserID(ctx context.Context, userID string) (*d
 	return &loc, err
 }
 
+func (r *locationRepository) GetByUserIDs(ctx context.Context, userIDs []string) ([]domain.Location, error) {
+	locs := []domain.Location{}
+	if len(userIDs) == 0 {
+		return locs, nil
+	}
+	cursor, err := r.database.Collection(r.collection).Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	err = cursor.All(ctx, &locs)
+	return locs, err
+}
+
 func (r *locationRepository) GetNearbyUserIDs(ctx context.Context, lat, lon, km float64) ([]string, error) {
 	collection := r.database.Collection(domain.CollectionLocation)
 
@@ -88,3 +135,46 @@ func (r *locationRepository) GetNearbyUserIDs(ctx context.Context, lat, lon, km
 	}
 	return ids, nil
 }
+
+func (r *locationRepository) FetchSilent(ctx context.Context, q domain.SilentQuery, limit int) ([]domain.Location, error) {
+	status := bson.M{"$nin": append([]string{domain.LocationUnknown}, q.ExcludeStatuses...)}
+	if len(q.Statuses) > 0 {
+		status["$in"] = q.Statuses
+	}
+	filter := bson.M{
+		"status":     status,
+		"updated_at": bson.M{"$lt": q.Before},
+	}
+	if q.RadiusM > 0 {
+		filter["location"] = bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{bson.A{q.Lon, q.Lat}, q.RadiusM / geo.EarthRadiusM},
+		}}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := r.database.Collection(r.collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	locs := []domain.Location{}
+	err = cursor.All(ctx, &locs)
+	return locs, err
+}
+
+func (r *locationRepository) MarkStale(ctx context.Context, userID string, updatedAt, staleSince int64) (bool, error) {
+	// status cũ chép sang last_status bằng update pipeline
+	res, err := r.database.Collection(r.collection).UpdateOne(ctx,
+		bson.M{"_id": userID, "updated_at": updatedAt, "status": bson.M{"$ne": domain.LocationUnknown}},
+		bson.A{bson.M{"$set": bson.M{
+			"last_status": "$status",
+			"status":      domain.LocationUnknown,
+			"stale_since": staleSince,
+		}}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type memberRepository struct {
@@ -30,26 +31,6 @@ func (r *memberRepository) Add(c context.Context, m *domain.Member) error {
 	return err
 }
 
-// Cập nhật vị trí của member (lat, lon, updatedAt)
-func (r *memberRepository) UpdateLocation(
-	c context.Context,
-	userID primitive.ObjectID,
-	lat, lon float64,
-	updatedAt time.Time,
-) error {
-	col := r.database.Collection(r.collection)
-	filter := bson.M{"userId": userID}
-	update := bson.M{
-		"$set": bson.M{
-			"lat":       lat,
-			"lon":       lon,
-			"updatedAt": updatedAt,
-		},
-	}
-	_, err := col.UpdateOne(c, filter, update)
-	return err
-}
-
 // Lấy danh sách member theo group
 func (r *memberRepository) ListByGroup(c context.Context, groupID primitive.ObjectID) ([]domain.Member, error) {
 	col := r.database.Collection(r.collection)
@@ -67,3 +48,63 @@ func (r *memberRepository) ListByGroup(c context.Context, groupID primitive.Obje
 
 	return members, err
 }
+
+func (r *memberRepository) SetRole(c context.Context, groupID, userID primitive.ObjectID, role domain.GroupRole) error {
+	col := r.database.Collection(r.collection)
+	_, err := col.UpdateOne(c,
+		bson.M{"groupId": groupID, "userId": userID},
+		bson.M{"$set": bson.M{"role": role, "updatedAt": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// Bỏ member khỏi group (xóa cả bản ghi trùng nếu có)
+func (r *memberRepository) Remove(c context.Context, groupID, userID primitive.ObjectID) error {
+	col := r.database.Collection(r.collection)
+	_, err := col.DeleteMany(c, bson.M{"groupId": groupID, "userId": userID})
+	return err
+}
+
+func (r *memberRepository) DeleteByGroup(c context.Context, groupID primitive.ObjectID) error {
+	col := r.database.Collection(r.collection)
+	_, err := col.DeleteMany(c, bson.M{"groupId": groupID})
+	return err
+}
+
+// BackfillGroupOwners ghi owner cho nhóm tạo trước khi có role (CreateGroup cũ không thêm bản ghi member
+// cho người tạo): người vào đầu tiên trong memberIDs thành owner để nhóm vẫn xóa / quản lý được.
+func BackfillGroupOwners(ctx context.Context, db mongo.Database) (int, error) {
+	cur, err := db.Collection(domain.CollectionGroup).Aggregate(ctx, bson.A{
+		bson.M{"$match": bson.M{"memberIDs.0": bson.M{"$exists": true}}},
+		bson.M{"$lookup": bson.M{
+			"from": domain.CollectionMember,
+			"let":  bson.M{"gid": "$_id"},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$expr": bson.M{"$eq": bson.A{"$groupId", "$$gid"}}, "role": domain.GroupRoleOwner}},
+				bson.M{"$limit": 1},
+			},
+			"as": "owners",
+		}},
+		bson.M{"$match": bson.M{"owners": bson.M{"$size": 0}}},
+		bson.M{"$project": bson.M{"memberIDs": 1}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var groups []struct {
+		ID        primitive.ObjectID   `bson:"_id"`
+		MemberIDs []primitive.ObjectID `bson:"memberIDs"`
+	}
+	if err := cur.All(ctx, &groups); err != nil {
+		return 0, err
+	}
+
+	members := NewMemberRepository(db, domain.CollectionMember)
+	for i, g := range groups {
+		if err := members.SetRole(ctx, g.ID, g.MemberIDs[0], domain.GroupRoleOwner); err != nil {
+			return i, err
+		}
+	}
+	return len(groups), nil
+}
@@ -2,12 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -23,6 +25,13 @@ func NewReportRepo(db mongo.Database, col string) domain.ReportRepository {
 
 // Create lưu report vào MongoDB nếu chưa tồn tại (upsert)
 func (r *reportRepository) Create(ctx context.Context, rep *domain.Report) error {
+	_, err := r.CreateOnce(ctx, rep)
+	return err
+}
+
+// CreateOnce trả về true nếu report được insert, false nếu đã có.
+// Report gửi bù (có ClientKey) được nhận diện theo client_key, khi trùng thì rep.ID là _id của bản đã lưu.
+func (r *reportRepository) CreateOnce(ctx context.Context, rep *domain.Report) (bool, error) {
 	if rep.Timestamp == 0 {
 		rep.Timestamp = time.Now().Unix()
 	}
@@ -33,32 +42,196 @@ func (r *reportRepository) Create(ctx context.Context, rep *domain.Report) error
 
 	coll := r.db.Collection(r.collection)
 
-	// Upsert: nếu _id đã tồn tại thì bỏ qua, chưa có thì insert
+	// Upsert: nếu đã tồn tại thì bỏ qua, chưa có thì insert
 	filter := bson.M{"_id": rep.ID}
+	if rep.ClientKey != "" {
+		filter = bson.M{"client_key": rep.ClientKey}
+	}
 	update := bson.M{"$setOnInsert": rep} // chỉ insert nếu chưa tồn tại
 	opts := options.Update().SetUpsert(true)
 
-	_, err := coll.UpdateOne(ctx, filter, update, opts)
-	return err
+	res, err := coll.UpdateOne(ctx, filter, update, opts)
+	if err != nil && !mongodriver.IsDuplicateKeyError(err) {
+		return false, err
+	}
+	if err == nil && res.UpsertedCount == 1 {
+		return true, nil
+	}
+	if rep.ClientKey == "" {
+		return false, nil
+	}
+
+	// hai request cùng key chạy song song: bên thua nhận lỗi trùng, lấy _id của bản đã lưu
+	var existing struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := coll.FindOne(ctx, filter).Decode(&existing); err != nil {
+		return false, err
+	}
+	rep.ID = existing.ID
+	return false, nil
+}
+
+// GetByID lấy report theo _id
+func (r *reportRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Report, error) {
+	coll := r.db.Collection(r.collection)
+
+	var rep domain.Report
+	if err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&rep); err != nil {
+		if errors.Is(err, mongodriver.ErrNoDocuments) {
+			return nil, domain.ErrReportNotFound
+		}
+		return nil, err
+	}
+	return &rep, nil
 }
 
-// UpdateAI cập nhật enrichment, nếu report chưa tồn tại sẽ tạo mới (upsert)
-func (r *reportRepository) UpdateAI(ctx context.Context, reportID string, enrichment *domain.ReportEnrichment) error {
-	objID, err := primitive.ObjectIDFromHex(reportID)
+// Update ghi đè nội dung + status của report với optimistic locking theo revision
+func (r *reportRepository) Update(ctx context.Context, rep *domain.Report, expectedRevision int) error {
+	coll := r.db.Collection(r.collection)
+
+	filter := bson.M{"_id": rep.ID, "revision": expectedRevision}
+	if expectedRevision == 0 {
+		// report tạo trước khi có revision không có field này
+		filter = bson.M{"_id": rep.ID, "$or": []bson.M{
+			{"revision": 0},
+			{"revision": bson.M{"$exists": false}},
+		}}
+	}
+
+	update := bson.M{"$set": bson.M{
+		"type":             rep.Type,
+		"detail":           rep.Detail,
+		"description":      rep.Description,
+		"image":            rep.Image,
+		"location":         rep.Location,
+		"status":           rep.Status,
+		"enrichment_state": rep.EnrichmentState,
+		"revision":         rep.Revision,
+		"updated_at":       rep.UpdatedAt,
+	}}
+
+	res, err := coll.UpdateOne(ctx, filter, update)
 	if err != nil {
 		return err
 	}
+	if res.MatchedCount == 0 {
+		return domain.ErrRevisionConflict
+	}
+	return nil
+}
+
+// AddRevision lưu bản chụp trước khi sửa vào collection report_revisions
+func (r *reportRepository) AddRevision(ctx context.Context, rev *domain.ReportRevision) error {
+	if rev.ID.IsZero() {
+		rev.ID = primitive.NewObjectID()
+	}
+	coll := r.db.Collection(domain.CollectionReportRevision)
+	_, err := coll.InsertOne(ctx, rev)
+	return err
+}
+
+// ListRevisions lấy lịch sử sửa của report, cũ nhất trước
+func (r *reportRepository) ListRevisions(ctx context.Context, reportID primitive.ObjectID) ([]domain.ReportRevision, error) {
+	coll := r.db.Collection(domain.CollectionReportRevision)
+
+	opts := options.Find().SetSort(bson.M{"revision": 1})
+	cursor, err := coll.Find(ctx, bson.M{"report_id": reportID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revisions := []domain.ReportRevision{}
+	if err := cursor.All(ctx, &revisions); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
 
+// UpdateEnrichment cập nhật kết quả AI và trạng thái enrichment.
+// Không upsert: report phải được Create trước đó.
+func (r *reportRepository) UpdateEnrichment(ctx context.Context, id primitive.ObjectID, enrichment *domain.ReportEnrichment, state string) error {
 	coll := r.db.Collection(r.collection)
 
-	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": bson.M{"enrichment": enrichment}}
-	opts := options.Update().SetUpsert(true) // nếu chưa có document thì tạo mới
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{
+		"enrichment":       enrichment,
+		"enrichment_state": state,
+	}}
 
-	_, err = coll.UpdateOne(ctx, filter, update, opts)
+	_, err := coll.UpdateOne(ctx, filter, update)
 	return err
 }
 
+// SetRiskApplied so sánh rồi ghi nên hai lần chạy enrichment cùng lúc không cộng risk hai lần
+func (r *reportRepository) SetRiskApplied(ctx context.Context, id primitive.ObjectID, prev, risk float64) (bool, error) {
+	coll := r.db.Collection(r.collection)
+
+	filter := bson.M{"_id": id, "risk_applied": prev}
+	if prev == 0 {
+		filter = bson.M{"_id": id, "$or": []bson.M{
+			{"risk_applied": 0},
+			{"risk_applied": bson.M{"$exists": false}},
+		}}
+	}
+
+	res, err := coll.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"risk_applied": risk}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount == 1, nil
+}
+
+// FetchUnenrichedIDs trả về ID các report đang PENDING/FALLBACK hoặc chưa từng được enrich
+func (r *reportRepository) FetchUnenrichedIDs(ctx context.Context) ([]primitive.ObjectID, error) {
+	coll := r.db.Collection(r.collection)
+
+	filter := bson.M{
+		"$or": []bson.M{
+			{"enrichment_state": bson.M{"$in": []string{domain.EnrichmentPending, domain.EnrichmentFallback}}},
+			{"enrichment_state": bson.M{"$exists": false}, "enrichment": bson.M{"$exists": false}},
+		},
+	}
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ids []primitive.ObjectID
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		ids = append(ids, doc.ID)
+	}
+	return ids, nil
+}
+
+// FetchActiveInBounds lấy report OPEN trong bounding box, bỏ field ảnh cho nhẹ
+func (r *reportRepository) FetchActiveInBounds(ctx context.Context, b domain.BoundsFilter) ([]*domain.Report, error) {
+	collection := r.db.Collection(r.collection)
+
+	filter := geoBoxFilter("location", "$geoWithin", b)
+	filter["status"] = bson.M{"$nin": []string{domain.ReportStatusCleared, domain.ReportStatusRetracted}}
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"image": 0}))
+	if err != nil {
+		return nil, err
+	}
+	var reports []*domain.Report
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
 // ---------- repository/report_repository.go ----------
 func (r *reportRepository) GetNearbyReports(ctx context.Context, lat, lon, km float64) ([]*domain.Report, error) {
 	collection := r.db.Collection(r.collection)
@@ -73,6 +246,8 @@ func (r *reportRepository) GetNearbyReports(ctx context.Context, lat, lon, km fl
 				"$maxDistance": km * 1000, // km -> meters
 			},
 		},
+		// report đã rút lại không hiện trên bản đồ
+		"status": bson.M{"$ne": domain.ReportStatusRetracted},
 	}
 
 	cursor, err := collection.Find(ctx, filter)
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type sharingRepository struct {
+	db         mongo.Database
+	collection string
+}
+
+func NewSharingRepo(db mongo.Database, collection string) domain.SharingRepository {
+	return &sharingRepository{db: db, collection: collection}
+}
+
+func (r *sharingRepository) Get(ctx context.Context, userID string) (*domain.SharingSettings, error) {
+	var s domain.SharingSettings
+	err := r.db.Collection(r.collection).FindOne(ctx, bson.M{"_id": userID}).Decode(&s)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sharingRepository) GetMany(ctx context.Context, userIDs []string) ([]domain.SharingSettings, error) {
+	out := []domain.SharingSettings{}
+	if len(userIDs) == 0 {
+		return out, nil
+	}
+	cursor, err := r.db.Collection(r.collection).Find(ctx, bson.M{"_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(ctx, &out)
+	return out, err
+}
+
+func (r *sharingRepository) Upsert(ctx context.Context, s *domain.SharingSettings) error {
+	_, err := r.db.Collection(r.collection).UpdateOne(ctx,
+		bson.M{"_id": s.UserID},
+		bson.M{"$set": bson.M{
+			"groupIds":    s.GroupIDs,
+			"precision":   s.Precision,
+			"pausedUntil": s.PausedUntil,
+			"updatedAt":   s.UpdatedAt,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
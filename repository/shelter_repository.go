@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type shelterRepository struct {
+	db         mongo.Database
+	collection string
+}
+
+func NewShelterRepository(db mongo.Database, collection string) domain.ShelterRepository {
+	return &shelterRepository{
+		db:         db,
+		collection: collection,
+	}
+}
+
+func (sr *shelterRepository) Create(ctx context.Context, s *domain.Shelter) error {
+	if s.ID.IsZero() {
+		s.ID = primitive.NewObjectID()
+	}
+	_, err := sr.db.Collection(sr.collection).InsertOne(ctx, s)
+	return err
+}
+
+func (sr *shelterRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Shelter, error) {
+	var s domain.Shelter
+	if err := sr.db.Collection(sr.collection).FindOne(ctx, bson.M{"_id": id}).Decode(&s); err != nil {
+		if errors.Is(err, mongodriver.ErrNoDocuments) {
+			return nil, domain.ErrShelterNotFound
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Update ghi thông tin shelter, occupancy đi qua AdjustOccupancy / SetOccupancy
+func (sr *shelterRepository) Update(ctx context.Context, s *domain.Shelter) error {
+	res, err := sr.db.Collection(sr.collection).UpdateOne(ctx,
+		bson.M{"_id": s.ID},
+		bson.M{"$set": bson.M{
+			"name":          s.Name,
+			"address":       s.Address,
+			"phone":         s.Phone,
+			"location":      s.Location,
+			"capacity":      s.Capacity,
+			"amenities":     s.Amenities,
+			"accessibility": s.Accessibility,
+			"status":        s.Status,
+			"updated_by":    s.UpdatedBy,
+			"updated_at":    s.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrShelterNotFound
+	}
+	return nil
+}
+
+func (sr *shelterRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	count, err := sr.db.Collection(sr.collection).DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrShelterNotFound
+	}
+	return nil
+}
+
+// AdjustOccupancy dùng update pipeline để cộng và chặn dưới 0 trong một lệnh,
+// nhiều nhân viên cùng check-in không ghi đè lên nhau
+func (sr *shelterRepository) AdjustOccupancy(ctx context.Context, id primitive.ObjectID, delta int, updatedBy string, updatedAt int64) (*domain.Shelter, error) {
+	return sr.updateOccupancy(ctx, id, bson.M{"$max": bson.A{0, bson.M{"$add": bson.A{"$occupancy", delta}}}}, updatedBy, updatedAt)
+}
+
+func (sr *shelterRepository) SetOccupancy(ctx context.Context, id primitive.ObjectID, occupancy int, updatedBy string, updatedAt int64) (*domain.Shelter, error) {
+	return sr.updateOccupancy(ctx, id, occupancy, updatedBy, updatedAt)
+}
+
+func (sr *shelterRepository) updateOccupancy(ctx context.Context, id primitive.ObjectID, value interface{}, updatedBy string, updatedAt int64) (*domain.Shelter, error) {
+	res, err := sr.db.Collection(sr.collection).UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.A{bson.M{"$set": bson.M{
+			"occupancy":  value,
+			"updated_by": updatedBy,
+			"updated_at": updatedAt,
+		}}},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res.MatchedCount == 0 {
+		return nil, domain.ErrShelterNotFound
+	}
+	return sr.GetByID(ctx, id)
+}
+
+func (sr *shelterRepository) FetchNearby(ctx context.Context, lat, lon, maxM float64, limit int) ([]domain.Shelter, error) {
+	filter := bson.M{
+		"status": domain.ShelterOpen,
+		"location": bson.M{
+			"$near": bson.M{
+				"$geometry":    bson.M{"type": "Point", "coordinates": []float64{lon, lat}},
+				"$maxDistance": maxM,
+			},
+		},
+	}
+
+	cursor, err := sr.db.Collection(sr.collection).Find(ctx, filter, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	var shelters []domain.Shelter
+	err = cursor.All(ctx, &shelters)
+	return shelters, err
+}
+
+func (sr *shelterRepository) FetchInBounds(ctx context.Context, b domain.BoundsFilter) ([]domain.Shelter, error) {
+	cursor, err := sr.db.Collection(sr.collection).Find(ctx, geoBoxFilter("location", "$geoWithin", b))
+	if err != nil {
+		return nil, err
+	}
+	var shelters []domain.Shelter
+	err = cursor.All(ctx, &shelters)
+	return shelters, err
+}
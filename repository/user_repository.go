@@ -71,6 +71,20 @@ func (ur *userRepository) GetByID(c context.Context, id string) (domain.User, er
 	return user, err
 }
 
+func (ur *userRepository) GetByIDs(c context.Context, ids []primitive.ObjectID) ([]domain.User, error) {
+	users := []domain.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+	opts := options.Find().SetProjection(bson.D{{Key: "password", Value: 0}})
+	cursor, err := ur.database.Collection(ur.collection).Find(c, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	err = cursor.All(c, &users)
+	return users, err
+}
+
 func (ur *userRepository) AddGroup(ctx context.Context, userID primitive.ObjectID, groupID primitive.ObjectID) error {
 	collection := ur.database.Collection(ur.collection)
 	filter := bson.M{"_id": userID}
@@ -79,3 +93,21 @@ func (ur *userRepository) AddGroup(ctx context.Context, userID primitive.ObjectI
 	_, err := collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+func (ur *userRepository) RemoveGroup(ctx context.Context, userID primitive.ObjectID, groupID primitive.ObjectID) error {
+	collection := ur.database.Collection(ur.collection)
+	filter := bson.M{"_id": userID}
+	update := bson.M{"$pull": bson.M{"groupIDs": groupID}}
+
+	_, err := collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (ur *userRepository) RemoveGroupFromAll(ctx context.Context, groupID primitive.ObjectID) error {
+	collection := ur.database.Collection(ur.collection)
+	filter := bson.M{"groupIDs": groupID}
+	update := bson.M{"$pull": bson.M{"groupIDs": groupID}}
+
+	_, err := collection.UpdateMany(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type zoneHistoryRepository struct {
+	db         mongo.Database
+	collection string
+}
+
+func NewZoneHistoryRepo(db mongo.Database, collection string) domain.ZoneHistoryRepository {
+	return &zoneHistoryRepository{db: db, collection: collection}
+}
+
+func (r *zoneHistoryRepository) Add(ctx context.Context, points ...domain.ZoneRiskPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(points))
+	for i := range points {
+		if points[i].ID.IsZero() {
+			points[i].ID = primitive.NewObjectID()
+		}
+		docs[i] = points[i]
+	}
+	_, err := r.db.Collection(r.collection).InsertMany(ctx, docs)
+	return err
+}
+
+func (r *zoneHistoryRepository) ListByZone(ctx context.Context, zoneID primitive.ObjectID, from, to time.Time) ([]domain.ZoneRiskPoint, error) {
+	filter := bson.M{
+		"zoneId": zoneID,
+		"time":   bson.M{"$gte": from, "$lte": to},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "time", Value: 1}})
+
+	cursor, err := r.db.Collection(r.collection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	points := []domain.ZoneRiskPoint{}
+	err = cursor.All(ctx, &points)
+	return points, err
+}
+
+// AggregateInBounds gom các điểm trong bounding box theo từng khoảng bucket
+func (r *zoneHistoryRepository) AggregateInBounds(ctx context.Context, b domain.BoundsFilter, from, to time.Time, bucket time.Duration) ([]domain.ZoneRiskBucket, error) {
+	bucketMs := bucket.Milliseconds()
+	timeMs := bson.M{"$toLong": "$time"}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{
+			"time":                 bson.M{"$gte": from, "$lte": to},
+			"center.coordinates.0": bson.M{"$gte": b.MinLon, "$lte": b.MaxLon},
+			"center.coordinates.1": bson.M{"$gte": b.MinLat, "$lte": b.MaxLat},
+		}},
+		{"$group": bson.M{
+			"_id":     bson.M{"$subtract": bson.A{timeMs, bson.M{"$mod": bson.A{timeMs, bucketMs}}}},
+			"maxRisk": bson.M{"$max": "$riskScore"},
+			"avgRisk": bson.M{"$avg": "$riskScore"},
+			"zones":   bson.M{"$addToSet": "$zoneId"},
+			"highZones": bson.M{"$addToSet": bson.M{
+				"$cond": bson.A{bson.M{"$eq": bson.A{"$label", "HIGH"}}, "$zoneId", nil},
+			}},
+			"changes": bson.M{"$sum": 1},
+		}},
+		{"$project": bson.M{
+			"_id":       0,
+			"start":     bson.M{"$toDate": "$_id"},
+			"maxRisk":   1,
+			"avgRisk":   1,
+			"zones":     bson.M{"$size": "$zones"},
+			"highZones": bson.M{"$size": bson.M{"$setDifference": bson.A{"$highZones", bson.A{nil}}}},
+			"changes":   1,
+		}},
+		{"$sort": bson.M{"start": 1}},
+	}
+
+	cursor, err := r.db.Collection(r.collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	buckets := []domain.ZoneRiskBucket{}
+	err = cursor.All(ctx, &buckets)
+	return buckets, err
+}
+
+func (r *zoneHistoryRepository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	return r.db.Collection(r.collection).DeleteMany(ctx, bson.M{"time": bson.M{"$lt": before}})
+}
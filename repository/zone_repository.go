@@ -2,11 +2,15 @@ package repository
 
 import (
 	"context"
-	"math"
+	"errors"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/mongo"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type zoneRepository struct {
@@ -21,13 +25,40 @@ func NewZoneRepository(db mongo.Database, collection string) domain.ZoneReposito
 	}
 }
 
+// số cạnh polygon lưu cho mỗi zone
+const zoneAreaSegments = 32
+
+// zoneArea tính polygon bao ngoài vòng tròn zone để query bằng index 2dsphere
+func zoneArea(z *domain.Zone) *domain.GeoPolygon {
+	ring := geo.CoverRing(z.Center.Coordinates[0], z.Center.Coordinates[1], z.Radius, zoneAreaSegments)
+	return &domain.GeoPolygon{Type: "Polygon", Coordinates: [][][2]float64{ring}}
+}
+
 // Create zone mới
 func (zr *zoneRepository) Create(ctx context.Context, z *domain.Zone) error {
+	if z.ID.IsZero() {
+		z.ID = primitive.NewObjectID()
+	}
+	z.Area = zoneArea(z)
 	coll := zr.db.Collection(zr.collection)
 	_, err := coll.InsertOne(ctx, z)
 	return err
 }
 
+// GetByID lấy zone theo _id
+func (zr *zoneRepository) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Zone, error) {
+	coll := zr.db.Collection(zr.collection)
+
+	var z domain.Zone
+	if err := coll.FindOne(ctx, bson.M{"_id": id}).Decode(&z); err != nil {
+		if errors.Is(err, mongodriver.ErrNoDocuments) {
+			return nil, domain.ErrZoneNotFound
+		}
+		return nil, err
+	}
+	return &z, nil
+}
+
 // FetchAll lấy tất cả zone
 func (zr *zoneRepository) FetchAll(ctx context.Context) ([]domain.Zone, error) {
 	coll := zr.db.Collection(zr.collection)
@@ -40,13 +71,13 @@ func (zr *zoneRepository) FetchAll(ctx context.Context) ([]domain.Zone, error) {
 	return zones, err
 }
 
-// FetchInBounds lấy zone trong bounding box
+// FetchInBounds lấy zone có vùng chạm vào bounding box (minLon > maxLon = box vắt qua kinh tuyến 180)
 func (zr *zoneRepository) FetchInBounds(ctx context.Context, minLat, minLon, maxLat, maxLon float64) ([]domain.Zone, error) {
 	coll := zr.db.Collection(zr.collection)
-	filter := bson.M{
-		"center.coordinates.0": bson.M{"$gte": minLon, "$lte": maxLon},
-		"center.coordinates.1": bson.M{"$gte": minLat, "$lte": maxLat},
-	}
+
+	filter := geoBoxFilter("area", "$geoIntersects", domain.BoundsFilter{
+		MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon,
+	})
 	cursor, err := coll.Find(ctx, filter)
 	if err != nil {
 		return nil, err
@@ -56,18 +87,15 @@ func (zr *zoneRepository) FetchInBounds(ctx context.Context, minLat, minLon, max
 	return zones, err
 }
 
-// FetchByLatLon kiểm tra point nằm trong zone nào
+// FetchAllByLatLon tìm các zone chứa điểm
 func (zr *zoneRepository) FetchAllByLatLon(ctx context.Context, lat, lon float64) ([]domain.Zone, error) {
 	coll := zr.db.Collection(zr.collection)
 
-	// MongoDB distance query
+	// index 2dsphere trên area lọc thô, polygon bao ngoài nên không bỏ sót
 	filter := bson.M{
-		"center": bson.M{
-			"$geoWithin": bson.M{
-				"$centerSphere": []interface{}{
-					[]float64{lon, lat},
-					0, // ta không giới hạn radius ở đây (zone tự chứa radius)
-				},
+		"area": bson.M{
+			"$geoIntersects": bson.M{
+				"$geometry": bson.M{"type": "Point", "coordinates": []float64{geo.NormalizeLon(lon), lat}},
 			},
 		},
 	}
@@ -82,11 +110,10 @@ func (zr *zoneRepository) FetchAllByLatLon(ctx context.Context, lat, lon float64
 		return nil, err
 	}
 
-	// Vì $centerSphere không biết radius của từng zone,
-	// nên vẫn phải filter lại bằng công thức distance < zone.Radius.
+	// polygon rộng hơn vòng tròn một chút nên lọc lại theo khoảng cách thật
 	var result []domain.Zone
 	for _, z := range zones {
-		d := distanceMeters(lat, lon, z.Center.Coordinates[1], z.Center.Coordinates[0])
+		d := geo.DistanceMeters(lat, lon, z.Center.Coordinates[1], z.Center.Coordinates[0])
 		if d <= z.Radius {
 			result = append(result, z)
 		}
@@ -95,20 +122,26 @@ func (zr *zoneRepository) FetchAllByLatLon(ctx context.Context, lat, lon float64
 	return result, nil
 }
 
-// Hàm tính khoảng cách giữa 2 điểm (lat/lon) bằng mét
-func distanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
-	const R = 6371000 // bán kính Trái Đất
-	latRad1 := lat1 * math.Pi / 180
-	latRad2 := lat2 * math.Pi / 180
-	dLat := (lat2 - lat1) * math.Pi / 180
-	dLon := (lon2 - lon1) * math.Pi / 180
+// BackfillZoneAreas tính area cho các zone tạo trước khi có field này, chạy lúc khởi động
+func BackfillZoneAreas(ctx context.Context, db mongo.Database) (int, error) {
+	coll := db.Collection(domain.CollectionZone)
 
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(latRad1)*math.Cos(latRad2)*
-			math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+	cursor, err := coll.Find(ctx, bson.M{"area": bson.M{"$exists": false}})
+	if err != nil {
+		return 0, err
+	}
+	var zones []domain.Zone
+	if err := cursor.All(ctx, &zones); err != nil {
+		return 0, err
+	}
 
-	return R * c
+	for i := range zones {
+		z := &zones[i]
+		if _, err := coll.UpdateOne(ctx, bson.M{"_id": z.ID}, bson.M{"$set": bson.M{"area": zoneArea(z)}}); err != nil {
+			return i, err
+		}
+	}
+	return len(zones), nil
 }
 
 // Update cập nhật một zone
@@ -119,11 +152,104 @@ func (zr *zoneRepository) Update(ctx context.Context, z *domain.Zone) error {
 		bson.M{"_id": z.ID},
 		bson.M{
 			"$set": bson.M{
+				"center":    z.Center,
+				"radius":    z.Radius,
+				"area":      zoneArea(z),
 				"riskScore": z.RiskScore,
+				"baseRisk":  z.RiskScore, // mốc mới cho decay
 				"label":     z.Label,
 				"updatedAt": z.UpdatedAt,
+				"hazard":    z.Hazard,
+				"declared":  z.Declared,
+				"override":  z.Override,
+				"locked":    z.Locked,
 			},
 		},
 	)
 	return err
 }
+
+// BulkUpdateRisk ghi risk đã decay cho nhiều zone trong một lần BulkWrite
+func (zr *zoneRepository) BulkUpdateRisk(ctx context.Context, updates []domain.ZoneRiskUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	models := make([]mongodriver.WriteModel, 0, len(updates))
+	for _, u := range updates {
+		models = append(models, mongodriver.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": u.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"riskScore": u.RiskScore, "label": u.Label}}))
+	}
+
+	coll := zr.db.Collection(zr.collection)
+	_, err := coll.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// Delete xóa một zone
+func (zr *zoneRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	coll := zr.db.Collection(zr.collection)
+	count, err := coll.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrZoneNotFound
+	}
+	return nil
+}
+
+// DeleteMany xóa các zone theo danh sách ID
+func (zr *zoneRepository) DeleteMany(ctx context.Context, ids []primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	coll := zr.db.Collection(zr.collection)
+	_, err := coll.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// AddLineage lưu lịch sử gộp zone
+func (zr *zoneRepository) AddLineage(ctx context.Context, l *domain.ZoneLineage) error {
+	if l.ID.IsZero() {
+		l.ID = primitive.NewObjectID()
+	}
+	coll := zr.db.Collection(domain.CollectionZoneLineage)
+	_, err := coll.InsertOne(ctx, l)
+	return err
+}
+
+// ApplyLineage thực hiện lần gộp đã lưu trong lineage
+func (zr *zoneRepository) ApplyLineage(ctx context.Context, l *domain.ZoneLineage) error {
+	if err := zr.DeleteMany(ctx, l.SourceIDs); err != nil {
+		return err
+	}
+
+	z := l.Zone
+	z.Area = zoneArea(&z)
+	coll := zr.db.Collection(zr.collection)
+	// upsert theo _id: chạy lại sau khi đã tạo thì không sinh zone trùng
+	if _, err := coll.UpdateOne(ctx, bson.M{"_id": z.ID}, bson.M{"$setOnInsert": z}, options.Update().SetUpsert(true)); err != nil {
+		return err
+	}
+
+	lc := zr.db.Collection(domain.CollectionZoneLineage)
+	_, err := lc.UpdateOne(ctx, bson.M{"_id": l.ID}, bson.M{"$set": bson.M{"applied": true}})
+	return err
+}
+
+// FetchPendingLineages lấy lineage chưa applied (lineage cũ không có field được coi là đã xong)
+func (zr *zoneRepository) FetchPendingLineages(ctx context.Context) ([]domain.ZoneLineage, error) {
+	coll := zr.db.Collection(domain.CollectionZoneLineage)
+	cursor, err := coll.Find(ctx, bson.M{"applied": false})
+	if err != nil {
+		return nil, err
+	}
+
+	var lineages []domain.ZoneLineage
+	if err := cursor.All(ctx, &lineages); err != nil {
+		return nil, err
+	}
+	return lineages, nil
+}
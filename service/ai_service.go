@@ -1,9 +1,8 @@
 package service
 
 import (
-	"strings"
-
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/textnorm"
 )
 
 type AISystem interface {
@@ -19,24 +18,24 @@ func NewHardcodeAI() *HardcodeAI {
 func (ai *HardcodeAI) ProcessReport(r *domain.Report) (*domain.ReportEnrichment, error) {
 
 	// --- RULE BASED AI FAKE ---
-	desc := strings.ToLower(r.Description + " " + r.Detail)
+	desc := textnorm.NewText(r.Description + " " + r.Detail)
 
 	out := &domain.ReportEnrichment{
 		Confidence: 90,
 	}
 
 	switch {
-	case strings.Contains(desc, "flood") || strings.Contains(desc, "ngập"):
+	case textnorm.Hazards.Has(desc, textnorm.HazardFlood):
 		out.Category = "FLOOD"
 		out.Urgency = "HIGH"
 		out.Summary = "Detected flooding situation based on user report."
 
-	case strings.Contains(desc, "fire") || strings.Contains(desc, "cháy"):
+	case textnorm.Hazards.Has(desc, textnorm.HazardFire):
 		out.Category = "FIRE"
 		out.Urgency = "HIGH"
 		out.Summary = "Detected fire incident requiring urgent attention."
 
-	case strings.Contains(desc, "accident") || strings.Contains(desc, "tai nạn"):
+	case textnorm.Hazards.Has(desc, textnorm.HazardAccident):
 		out.Category = "ACCIDENT"
 		out.Urgency = "MEDIUM"
 		out.Summary = "Traffic accident reported by user."
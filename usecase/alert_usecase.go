@@ -10,19 +10,25 @@ import (
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/worker"
 )
 
+// alertZoneRisk: risk cộng vào zone quanh mỗi SOS
+const alertZoneRisk = 0.2
+
 type AlertUseCase struct {
 	Repo       domain.AlertRepository
 	WSManager  *ws.WSManager
 	LocationUC *LocationUseCase
+	ZoneUC     domain.ZoneUsecase // nil = không cộng risk cho zone
 	Queue      *worker.PriorityQueue
 	Timeout    time.Duration
 }
 
-func NewAlertUC(queue *worker.PriorityQueue, wsManager *ws.WSManager, repo domain.AlertRepository, locUC *LocationUseCase, timeout time.Duration) *AlertUseCase {
+func NewAlertUC(queue *worker.PriorityQueue, wsManager *ws.WSManager, repo domain.AlertRepository, locUC *LocationUseCase,
+	zoneUC domain.ZoneUsecase, timeout time.Duration) *AlertUseCase {
 	return &AlertUseCase{
 		Repo:       repo,
 		WSManager:  wsManager,
 		LocationUC: locUC,
+		ZoneUC:     zoneUC,
 		Queue:      queue,
 		Timeout:    timeout,
 	}
@@ -51,24 +57,57 @@ func (uc *AlertUseCase) Handle(c *ws.Client, alert *domain.Alert) error {
 			}
 			uc.WSManager.SendToClient(c, "alert_response", response)
 
-			// 3️⃣ Lấy userID gần đó từ LocationUseCase
-			userIDs, err := uc.LocationUC.GetNearbyUserIDs(ctx,
-				alert.Location.Coordinates[1], // lat
-				alert.Location.Coordinates[0], // lon
-				alert.RadiusM/1000,            // km
-			)
-			if err != nil {
-				println("Failed to get nearby users:", err.Error())
-				return
-			}
-
-			// 4️⃣ Gọi WSManager để broadcast
-			uc.WSManager.BroadcastSOS(userIDs, alert)
+			uc.broadcast(ctx, alert)
 		},
 	})
 	return nil
 }
 
+// Raise lưu SOS của userID gửi qua REST (SMS bridge, app đối tác) và cộng risk zone như SOS qua WS.
+// Trả về user gần đó để người gọi broadcast qua realtime.Broadcaster
+func (uc *AlertUseCase) Raise(ctx context.Context, userID string, alert *domain.Alert) ([]string, error) {
+	alert.UserID = userID
+	ctx, cancel := context.WithTimeout(ctx, uc.Timeout)
+	defer cancel()
+
+	if err := uc.Repo.Create(ctx, alert); err != nil {
+		return nil, err
+	}
+	// SOS đã lưu: lỗi tìm user gần đó chỉ log, không báo lỗi cho người gửi
+	userIDs, err := uc.nearby(ctx, alert)
+	if err != nil {
+		println("Failed to get nearby users:", err.Error())
+	}
+	return userIDs, nil
+}
+
+// broadcast gửi "alert_broadcast" (kèm người gửi) tới user gần đó
+func (uc *AlertUseCase) broadcast(ctx context.Context, alert *domain.Alert) {
+	userIDs, err := uc.nearby(ctx, alert)
+	if err != nil {
+		println("Failed to get nearby users:", err.Error())
+		return
+	}
+
+	// Gọi WSManager để broadcast
+	uc.WSManager.BroadcastSOS(userIDs, alert)
+}
+
+// nearby cộng risk cho zone quanh SOS rồi trả về user trong bán kính SOS
+func (uc *AlertUseCase) nearby(ctx context.Context, alert *domain.Alert) ([]string, error) {
+	lat, lon := alert.Location.Coordinates[1], alert.Location.Coordinates[0]
+
+	if uc.ZoneUC != nil {
+		zoneCtx := domain.WithZoneCause(ctx, domain.ZoneCause{Type: domain.ZoneCauseAlert, RefID: alert.ID.Hex()})
+		if err := uc.ZoneUC.AddRiskOrCreate(zoneCtx, lat, lon, alertZoneRisk, alert.RadiusM); err != nil {
+			println("Failed to add zone risk:", err.Error())
+		}
+	}
+
+	// Lấy userID gần đó từ LocationUseCase
+	return uc.LocationUC.GetNearbyUserIDs(ctx, lat, lon, alert.RadiusM/1000)
+}
+
 func (uc *AlertUseCase) Resolve(client *ws.Client, alertID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), uc.Timeout)
 	defer cancel()
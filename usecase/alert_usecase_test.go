@@ -0,0 +1,57 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type savedAlertRepo struct {
+	domain.AlertRepository
+	saved []domain.Alert
+}
+
+func (f *savedAlertRepo) Create(ctx context.Context, alert *domain.Alert) error {
+	alert.ID = primitive.NewObjectID()
+	f.saved = append(f.saved, *alert)
+	return nil
+}
+
+type alertZoneUsecase struct {
+	domain.ZoneUsecase
+	causes []domain.ZoneCause
+}
+
+func (f *alertZoneUsecase) AddRiskOrCreate(ctx context.Context, lat, lon, riskIncrement, defaultRadius float64) error {
+	f.causes = append(f.causes, domain.ZoneCauseFrom(ctx))
+	return nil
+}
+
+func TestAlertRaiseSavesWithCaller(t *testing.T) {
+	repo := &savedAlertRepo{}
+	zones := &alertZoneUsecase{}
+	locUC := usecase.NewLocationUC(nil, nil, &fakeLocationRepo{stored: map[string]domain.Location{}}, nil, nil, nil, time.Second)
+	uc := usecase.NewAlertUC(nil, ws.NewWSManager(), repo, locUC, zones, time.Second)
+
+	alert := &domain.Alert{
+		UserID:   "someone-else", // người gửi lấy từ token, không lấy từ body
+		Body:     "kẹt trên mái nhà",
+		Location: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106.7, 10.77}},
+		RadiusM:  500,
+		TTLMin:   30,
+	}
+	_, err := uc.Raise(context.Background(), "u1", alert)
+	require.NoError(t, err)
+
+	require.Len(t, repo.saved, 1)
+	assert.Equal(t, "u1", repo.saved[0].UserID)
+	require.Len(t, zones.causes, 1)
+	assert.Equal(t, domain.ZoneCause{Type: domain.ZoneCauseAlert, RefID: alert.ID.Hex()}, zones.causes[0])
+}
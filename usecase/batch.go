@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"errors"
+)
+
+const maxBatchKeyLen = 128
+
+var (
+	errInvalidBatchKey = errors.New("missing or too long key")
+	errInvalidLatLon   = errors.New("invalid lat/lon")
+)
+
+// clientReportKey ghép userID + key làm khóa duy nhất để gửi lại không tạo report mới
+func clientReportKey(userID, key string) string {
+	return userID + ":" + key
+}
+
+func validateBatchItem(key string, lat, lon float64) error {
+	if key == "" || len(key) > maxBatchKeyLen {
+		return errInvalidBatchKey
+	}
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return errInvalidLatLon
+	}
+	return nil
+}
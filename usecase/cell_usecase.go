@@ -6,39 +6,39 @@ import (
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
 )
 
 type CellUsecaseImpl struct {
-	repo    domain.CellRepository
-	timeout time.Duration
+	repo     domain.CellRepository
+	zoneRepo domain.ZoneRepository  // nil = FetchGrid không chiếu zone
+	tiles    domain.TileInvalidator // nil = không có cache tile
+	timeout  time.Duration
 }
 
-func NewCellUsecase(repo domain.CellRepository, timeout time.Duration) domain.CellUsecase {
+func NewCellUsecase(repo domain.CellRepository, zoneRepo domain.ZoneRepository, tiles domain.TileInvalidator, timeout time.Duration) domain.CellUsecase {
 	return &CellUsecaseImpl{
-		repo:    repo,
-		timeout: timeout,
+		repo:     repo,
+		zoneRepo: zoneRepo,
+		tiles:    tiles,
+		timeout:  timeout,
 	}
 }
 
-// snapGrid chuẩn hóa lat/lon thành cell ~100x100m
-func snapGrid(lat, lon float64) (float64, float64) {
-	cellSize := 0.001 // ~111m
-	return float64(int(lat/cellSize)) * cellSize, float64(int(lon/cellSize)) * cellSize
+// invalidate báo cache tile lưới risk trong vùng đã đổi
+func (uc *CellUsecaseImpl) invalidate(b domain.BoundsFilter) {
+	if uc.tiles != nil {
+		uc.tiles.InvalidateTiles([]string{domain.TileLayerCells}, b)
+	}
 }
 
-// distanceMeters tính khoảng cách Haversine
-func distanceMetersCell(lat1, lon1, lat2, lon2 float64) float64 {
-	const R = 6371000 // bán kính Trái Đất (m)
-	latRad1 := lat1 * math.Pi / 180
-	latRad2 := lat2 * math.Pi / 180
-	dLat := (lat2 - lat1) * math.Pi / 180
-	dLon := (lon2 - lon1) * math.Pi / 180
+// giới hạn số cell của một lần tính lưới / cập nhật theo bán kính
+const maxGridCells = 20000
 
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(latRad1)*math.Cos(latRad2)*
-			math.Sin(dLon/2)*math.Sin(dLon/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return R * c
+// cellAt trả về hash + tâm của cell chứa điểm
+func cellAt(lat, lon float64) (string, [2]float64) {
+	hash := geo.Encode(lat, lon, domain.CellLevel)
+	return hash, geo.CellCenter(hash)
 }
 
 // ----------------------
@@ -46,14 +46,15 @@ func distanceMetersCell(lat1, lon1, lat2, lon2 float64) float64 {
 // ----------------------
 
 func (uc *CellUsecaseImpl) FetchByLatLon(ctx context.Context, lat, lon float64) (*domain.Cell, error) {
-	latSnap, lonSnap := snapGrid(lat, lon)
-	cell, err := uc.repo.GetByLatLon(ctx, latSnap, lonSnap)
+	hash, center := cellAt(lat, lon)
+	cell, err := uc.repo.GetByHash(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
 	if cell == nil {
 		cell = &domain.Cell{
-			Center:    [2]float64{lonSnap, latSnap},
+			Hash:      hash,
+			Center:    center,
 			RiskScore: 0,
 			Label:     "SAFE",
 			UpdatedAt: time.Now(),
@@ -63,8 +64,8 @@ func (uc *CellUsecaseImpl) FetchByLatLon(ctx context.Context, lat, lon float64)
 }
 
 func (uc *CellUsecaseImpl) GetCellByLatLon(ctx context.Context, lat, lon float64) (*domain.Cell, error) {
-	latSnap, lonSnap := snapGrid(lat, lon)
-	return uc.repo.GetByLatLon(ctx, latSnap, lonSnap)
+	hash, _ := cellAt(lat, lon)
+	return uc.repo.GetByHash(ctx, hash)
 }
 
 func (uc *CellUsecaseImpl) GetCellsByRadius(ctx context.Context, lat, lon, radius float64) ([]domain.Cell, error) {
@@ -72,65 +73,221 @@ func (uc *CellUsecaseImpl) GetCellsByRadius(ctx context.Context, lat, lon, radiu
 }
 
 func (uc *CellUsecaseImpl) UpdateCell(ctx context.Context, lat, lon, risk float64, label string) (*domain.Cell, error) {
-	latSnap, lonSnap := snapGrid(lat, lon)
+	hash, center := cellAt(lat, lon)
+	risk = clampRisk(risk)
+	if label == "" {
+		label = domain.RiskLabel(risk)
+	}
 	cell := &domain.Cell{
-		Center:    [2]float64{lonSnap, latSnap},
+		Hash:      hash,
+		Center:    center,
 		RiskScore: risk,
 		Label:     label,
 		UpdatedAt: time.Now(),
 	}
-	return cell, uc.repo.Upsert(ctx, cell)
+	if err := uc.repo.Upsert(ctx, cell); err != nil {
+		return nil, err
+	}
+	uc.invalidate(cellBounds(hash))
+	return cell, nil
 }
 
 func (uc *CellUsecaseImpl) Upsert(ctx context.Context, cell *domain.Cell) error {
-	return uc.repo.Upsert(ctx, cell)
+	if err := uc.repo.Upsert(ctx, cell); err != nil {
+		return err
+	}
+	uc.invalidate(cellBounds(cell.Hash))
+	return nil
+}
+
+func cellBounds(hash string) domain.BoundsFilter {
+	minLat, minLon, maxLat, maxLon, _ := geo.Bounds(hash)
+	return domain.BoundsFilter{MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon}
 }
 
+// UpdateCellsByRadius cộng riskInc cho các cell chạm vòng tròn; cộng + chặn [0, 1] chạy trong DB
+// nên nhiều request cùng lúc không làm mất lần cập nhật nào
 func (uc *CellUsecaseImpl) UpdateCellsByRadius(ctx context.Context, lat, lon, radius, riskInc float64, label string) (updated int, inserted int, err error) {
-	const cellSize = 0.001 // ~111m
-	// 1️⃣ Lấy các cell hiện có
-	cells, err := uc.repo.GetByRadius(ctx, lat, lon, radius)
+	hashes, err := radiusCells(lat, lon, radius)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	updated, inserted, err = uc.repo.IncrementMany(ctx, hashes, riskInc, label, time.Now())
+	if err != nil {
+		return 0, 0, err
+	}
+	uc.invalidate(radiusBounds(lat, lon, radius))
+	return updated, inserted, nil
+}
+
+// SetCellsByRadius đặt cùng một risk cho các cell chạm vòng tròn (một lần BulkWrite)
+func (uc *CellUsecaseImpl) SetCellsByRadius(ctx context.Context, lat, lon, radius, risk float64, label string) (updated int, inserted int, err error) {
+	hashes, err := radiusCells(lat, lon, radius)
+	if err != nil {
+		return 0, 0, err
+	}
+	risk = clampRisk(risk)
+	if label == "" {
+		label = domain.RiskLabel(risk)
+	}
+
+	now := time.Now()
+	cells := make([]*domain.Cell, len(hashes))
+	for i, hash := range hashes {
+		cells[i] = &domain.Cell{Hash: hash, Center: geo.CellCenter(hash), RiskScore: risk, Label: label, UpdatedAt: now}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	updated, inserted, err = uc.repo.UpsertMany(ctx, cells)
 	if err != nil {
 		return 0, 0, err
 	}
-	existing := make(map[[2]float64]*domain.Cell)
+	uc.invalidate(radiusBounds(lat, lon, radius))
+	return updated, inserted, nil
+}
+
+// radiusCells: các cell của lưới chạm vào vòng tròn (bán kính tính bằng mét)
+func radiusCells(lat, lon, radius float64) ([]string, error) {
+	if radius <= 0 {
+		return nil, domain.ErrInvalidRadius
+	}
+	// ước lượng theo khung bao trước, CoverCircle với bán kính rất lớn tốn nhiều bộ nhớ
+	b := radiusBounds(lat, lon, radius)
+	if geo.CountBoxCells(b.MinLat, b.MinLon, b.MaxLat, b.MaxLon, domain.CellLevel) > maxGridCells {
+		return nil, domain.ErrGridTooLarge
+	}
+	hashes := geo.CoverCircle(lat, lon, radius, domain.CellLevel)
+	if len(hashes) > maxGridCells {
+		return nil, domain.ErrGridTooLarge
+	}
+	return hashes, nil
+}
+
+func radiusBounds(lat, lon, radius float64) domain.BoundsFilter {
+	minLat, minLon, maxLat, maxLon := geo.BoundsAround(lat, lon, radius)
+	return domain.BoundsFilter{MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon}
+}
+
+func clampRisk(risk float64) float64 {
+	return math.Min(1, math.Max(0, risk))
+}
+
+// FetchGrid trả về lưới risk trong bounding box ở level, gộp cell lưu trữ với zone chiếu lên lưới
+func (uc *CellUsecaseImpl) FetchGrid(ctx context.Context, b domain.BoundsFilter, level int, withZones bool) ([]domain.CellAggregate, error) {
+	if level < domain.MinCellLevel || level > domain.CellLevel {
+		level = domain.CellLevel
+	}
+	if geo.CountBoxCells(b.MinLat, b.MinLon, b.MaxLat, b.MaxLon, level) > maxGridCells {
+		return nil, domain.ErrGridTooLarge
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	cells, err := uc.repo.AggregateInBounds(ctx, b, level)
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []domain.Zone
+	if withZones && uc.zoneRepo != nil {
+		zones, err = uc.zoneRepo.FetchInBounds(ctx, b.MinLat, b.MinLon, b.MaxLat, b.MaxLon)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ProjectZones(cells, zones, b, level), nil
+}
+
+// ProjectZones chiếu zone lên lưới level (mỗi cell chạm zone nhận risk của zone)
+// rồi gộp với cell đã có; chỉ giữ cell có tâm trong bounding box.
+func ProjectZones(cells []domain.CellAggregate, zones []domain.Zone, b domain.BoundsFilter, level int) []domain.CellAggregate {
+	byHash := make(map[string]*domain.CellAggregate, len(cells))
+	out := make([]*domain.CellAggregate, 0, len(cells))
 	for i := range cells {
 		c := &cells[i]
-		existing[c.Center] = c
+		byHash[c.Hash] = c
+		out = append(out, c)
 	}
 
-	// 2️⃣ Tính số bước lặp theo cellSize
-	steps := int(math.Ceil(radius / cellSize))
-
-	for dx := -steps; dx <= steps; dx++ {
-		for dy := -steps; dy <= steps; dy++ {
-			cLat := lat + float64(dx)*cellSize
-			cLon := lon + float64(dy)*cellSize
-			if distanceMetersCell(lat, lon, cLat, cLon) > radius {
-				continue
+	for _, z := range zones {
+		for _, hash := range zoneCells(z, b, level) {
+			c, ok := byHash[hash]
+			if !ok {
+				center := geo.CellCenter(hash)
+				if !inBounds(b, center[1], center[0]) {
+					continue
+				}
+				c = &domain.CellAggregate{Hash: hash}
+				byHash[hash] = c
+				out = append(out, c)
 			}
+			c.ZoneRisk = math.Max(c.ZoneRisk, z.RiskScore)
+		}
+	}
 
-			latNorm, lonNorm := snapGrid(cLat, cLon)
-			center := [2]float64{lonNorm, latNorm}
+	result := make([]domain.CellAggregate, len(out))
+	for i, c := range out {
+		c.Level = level
+		c.Center = geo.CellCenter(c.Hash)
+		c.RiskScore = math.Max(c.CellRisk, c.ZoneRisk)
+		c.Label = domain.RiskLabel(c.RiskScore)
+		result[i] = *c
+	}
+	return result
+}
 
-			if cell, ok := existing[center]; ok {
-				cell.RiskScore += riskInc
-				if label != "" {
-					cell.Label = label
-				}
-				_ = uc.repo.Upsert(ctx, cell)
-				updated++
-			} else {
-				newCell := &domain.Cell{
-					Center:    center,
-					RiskScore: riskInc,
-					Label:     label,
-					UpdatedAt: time.Now(),
-				}
-				_ = uc.repo.Upsert(ctx, newCell)
-				inserted++
+// zoneCells: các cell của zone ở level. Zone phủ quá nhiều cell (bán kính lớn so với level)
+// thì chỉ quét phần giao với bounding box, FetchGrid đã chặn box ở maxGridCells.
+func zoneCells(z domain.Zone, b domain.BoundsFilter, level int) []string {
+	lat, lon := z.Center.Coordinates[1], z.Center.Coordinates[0]
+	if z.Radius <= 0 {
+		return nil
+	}
+	zb := radiusBounds(lat, lon, z.Radius)
+	if geo.CountBoxCells(zb.MinLat, zb.MinLon, zb.MaxLat, zb.MaxLon, level) <= maxGridCells {
+		return geo.CoverCircle(lat, lon, z.Radius, level)
+	}
+
+	latDeg, lonDeg := geo.CellSize(level)
+	if geo.CountBoxCells(b.MinLat, b.MinLon, b.MaxLat, b.MaxLon, level) > maxGridCells {
+		return nil
+	}
+	width := b.MaxLon - b.MinLon
+	if width < 0 {
+		width += 360
+	}
+	var out []string
+	seen := map[string]bool{}
+	for y := b.MinLat; y <= b.MaxLat+latDeg; y += latDeg {
+		for x := b.MinLon; x <= b.MinLon+width+lonDeg; x += lonDeg {
+			h := geo.Encode(math.Min(y, b.MaxLat), geo.NormalizeLon(math.Min(x, b.MinLon+width)), level)
+			if seen[h] {
+				continue
+			}
+			seen[h] = true
+			center := geo.CellCenter(h)
+			if geo.DistanceMeters(lat, lon, center[1], center[0]) <= z.Radius {
+				out = append(out, h)
 			}
 		}
 	}
-	return updated, inserted, nil
+	return out
+}
+
+// inBounds kiểm tra điểm trong bounding box, minLon > maxLon = box vắt qua kinh tuyến 180
+func inBounds(b domain.BoundsFilter, lat, lon float64) bool {
+	if lat < b.MinLat || lat > b.MaxLat {
+		return false
+	}
+	if b.MinLon <= b.MaxLon {
+		return lon >= b.MinLon && lon <= b.MaxLon
+	}
+	return lon >= b.MinLon || lon <= b.MaxLon
 }
@@ -0,0 +1,110 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ghi lại lần gọi IncrementMany / UpsertMany gần nhất
+type fakeCellRepo struct {
+	domain.CellRepository
+	incHashes []string
+	inc       float64
+	set       []*domain.Cell
+}
+
+func (f *fakeCellRepo) IncrementMany(ctx context.Context, hashes []string, inc float64, label string, at time.Time) (int, int, error) {
+	f.incHashes, f.inc = hashes, inc
+	return 0, len(hashes), nil
+}
+
+func (f *fakeCellRepo) UpsertMany(ctx context.Context, cells []*domain.Cell) (int, int, error) {
+	f.set = cells
+	return 0, len(cells), nil
+}
+
+func TestProjectZones(t *testing.T) {
+	bounds := domain.BoundsFilter{MinLat: 10.7, MinLon: 106.6, MaxLat: 10.9, MaxLon: 106.8}
+	hash := geo.Encode(10.78, 106.70, 5)
+	center := geo.CellCenter(hash) // zone nhỏ ở giữa cell ~5km
+
+	cells := []domain.CellAggregate{{Hash: hash, CellRisk: 0.2, AvgRisk: 0.1, Count: 3}}
+	zones := []domain.Zone{zoneAt(center[1], center[0], 200, 0.8)}
+
+	out := usecase.ProjectZones(cells, zones, bounds, 5)
+	assert.Len(t, out, 1)
+	c := out[0]
+	assert.Equal(t, hash, c.Hash)
+	assert.Equal(t, 5, c.Level)
+	assert.Equal(t, 0.8, c.RiskScore)
+	assert.Equal(t, 0.2, c.CellRisk)
+	assert.Equal(t, "HIGH", c.Label)
+	assert.Equal(t, 3, c.Count)
+}
+
+func TestProjectZonesOutsideBounds(t *testing.T) {
+	bounds := domain.BoundsFilter{MinLat: 10.7, MinLon: 106.6, MaxLat: 10.9, MaxLon: 106.8}
+	zones := []domain.Zone{zoneAt(21.0, 105.8, 200, 0.8)}
+
+	assert.Empty(t, usecase.ProjectZones(nil, zones, bounds, 7))
+}
+
+func TestCellsByRadiusLimits(t *testing.T) {
+	repo := &fakeCellRepo{}
+	uc := usecase.NewCellUsecase(repo, nil, nil, time.Second)
+	ctx := context.Background()
+
+	_, _, err := uc.UpdateCellsByRadius(ctx, 10.78, 106.7, 0, 0.1, "")
+	assert.ErrorIs(t, err, domain.ErrInvalidRadius)
+
+	// bán kính cả nghìn km bị chặn trước khi liệt kê cell, repo không được gọi
+	_, _, err = uc.UpdateCellsByRadius(ctx, 10.78, 106.7, 5e6, 0.1, "")
+	assert.ErrorIs(t, err, domain.ErrGridTooLarge)
+	_, _, err = uc.SetCellsByRadius(ctx, 10.78, 106.7, 5e6, 0.5, "")
+	assert.ErrorIs(t, err, domain.ErrGridTooLarge)
+	assert.Nil(t, repo.incHashes)
+	assert.Nil(t, repo.set)
+}
+
+func TestCellsByRadiusIncrementVsSet(t *testing.T) {
+	repo := &fakeCellRepo{}
+	uc := usecase.NewCellUsecase(repo, nil, nil, time.Second)
+	ctx := context.Background()
+	center := geo.Encode(10.78, 106.7, domain.CellLevel)
+
+	// increment: chuyển delta xuống repo, việc cộng + chặn [0, 1] làm trong DB
+	_, inserted, err := uc.UpdateCellsByRadius(ctx, 10.78, 106.7, 300, 0.3, "")
+	require.NoError(t, err)
+	assert.Contains(t, repo.incHashes, center)
+	assert.Equal(t, len(repo.incHashes), inserted)
+	assert.Equal(t, 0.3, repo.inc)
+
+	// set: cùng một giá trị đã chặn cho mọi cell, label tính từ risk
+	_, _, err = uc.SetCellsByRadius(ctx, 10.78, 106.7, 300, 1.7, "")
+	require.NoError(t, err)
+	require.Len(t, repo.set, len(repo.incHashes))
+	for _, c := range repo.set {
+		assert.Equal(t, 1.0, c.RiskScore)
+		assert.Equal(t, "HIGH", c.Label)
+	}
+}
+
+func TestProjectZonesLargeZone(t *testing.T) {
+	// zone bán kính 300km ở level 7 (~150m) chỉ được chiếu lên phần trong box
+	bounds := domain.BoundsFilter{MinLat: 10.77, MinLon: 106.69, MaxLat: 10.79, MaxLon: 106.71}
+	zones := []domain.Zone{zoneAt(10.78, 106.7, 3e5, 0.7)}
+
+	out := usecase.ProjectZones(nil, zones, bounds, 7)
+	require.NotEmpty(t, out)
+	for _, c := range out {
+		assert.Equal(t, 0.7, c.ZoneRisk)
+		assert.True(t, c.Center[1] >= bounds.MinLat && c.Center[1] <= bounds.MaxLat)
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/export"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+)
+
+// số cạnh polygon xấp xỉ vòng tròn của zone / alert
+const exportCircleSegments = 48
+
+var (
+	reportExportColumns = []string{
+		"user_id", "type", "detail", "description", "status", "timestamp", "revision",
+		"enrichment_state", "category", "urgency", "summary", "confidence", "extracted_at",
+	}
+	alertExportColumns = []string{"user_id", "body", "status", "radius_m", "ttl_min", "expires_at", "visibility"}
+	zoneExportColumns  = []string{"label", "risk_score", "radius_m", "updated_at"}
+	cellExportColumns  = []string{"label", "risk_score", "updated_at"}
+)
+
+type exportUsecase struct {
+	repo    domain.ExportRepository
+	timeout time.Duration
+}
+
+// timeout ở đây là cho cả lần export, nên đặt dài hơn CONTEXT_TIMEOUT
+func NewExportUsecase(repo domain.ExportRepository, timeout time.Duration) domain.ExportUsecase {
+	return &exportUsecase{repo: repo, timeout: timeout}
+}
+
+func (u *exportUsecase) Export(ctx context.Context, layer, format string, f domain.ExportFilter, w io.Writer) error {
+	if u.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, u.timeout)
+		defer cancel()
+	}
+
+	ew, err := export.NewWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	switch layer {
+	case domain.ExportLayerReports:
+		err = writeLayer(ew, layer, reportExportColumns, func(emit func(export.Feature) error) error {
+			return u.repo.StreamReports(ctx, f, func(r *domain.Report) error { return emit(reportFeature(r)) })
+		})
+	case domain.ExportLayerAlerts:
+		err = writeLayer(ew, layer, alertExportColumns, func(emit func(export.Feature) error) error {
+			return u.repo.StreamAlerts(ctx, f, func(a *domain.Alert) error { return emit(alertFeature(a)) })
+		})
+	case domain.ExportLayerZones:
+		err = writeLayer(ew, layer, zoneExportColumns, func(emit func(export.Feature) error) error {
+			return u.repo.StreamZones(ctx, f, func(z *domain.Zone) error { return emit(zoneFeature(z)) })
+		})
+	case domain.ExportLayerCells:
+		err = writeLayer(ew, layer, cellExportColumns, func(emit func(export.Feature) error) error {
+			return u.repo.StreamCells(ctx, f, func(c *domain.Cell) error { return emit(cellFeature(c)) })
+		})
+	default:
+		return domain.ErrUnknownExportLayer
+	}
+	return err
+}
+
+func writeLayer(ew export.Writer, layer string, columns []string, run func(emit func(export.Feature) error) error) error {
+	if err := ew.Begin(layer, columns); err != nil {
+		return err
+	}
+	if err := run(ew.Write); err != nil {
+		return err
+	}
+	return ew.End()
+}
+
+func reportFeature(r *domain.Report) export.Feature {
+	// enrichment được trải phẳng thành các cột riêng
+	var category, urgency, summary, confidence, extractedAt interface{}
+	if e := r.Enrichment; e != nil {
+		category, urgency, summary = e.Category, e.Urgency, e.Summary
+		confidence = e.Confidence
+		if e.ExtractedAt > 0 {
+			extractedAt = formatUnix(e.ExtractedAt)
+		}
+	}
+
+	return export.Feature{
+		ID:     r.ID.Hex(),
+		Name:   r.Type,
+		Center: r.Location.Coordinates,
+		Values: []interface{}{
+			r.UserID, r.Type, r.Detail, r.Description, r.Status, formatUnix(r.Timestamp), r.Revision,
+			r.EnrichmentState, category, urgency, summary, confidence, extractedAt,
+		},
+	}
+}
+
+func alertFeature(a *domain.Alert) export.Feature {
+	f := export.Feature{
+		ID:     a.ID.Hex(),
+		Name:   a.Body,
+		Center: a.Location.Coordinates,
+		Values: []interface{}{
+			a.UserID, a.Body, a.Status, a.RadiusM, a.TTLMin, a.ExpiresAt.UTC().Format(time.RFC3339), a.Visibility,
+		},
+	}
+	if a.RadiusM > 0 {
+		f.Ring = geo.CircleRing(f.Center[0], f.Center[1], a.RadiusM, exportCircleSegments)
+	}
+	return f
+}
+
+func zoneFeature(z *domain.Zone) export.Feature {
+	f := export.Feature{
+		ID:     z.ID.Hex(),
+		Name:   z.Label,
+		Center: z.Center.Coordinates,
+		Values: []interface{}{z.Label, z.RiskScore, z.Radius, formatUnix(z.UpdatedAt)},
+	}
+	if z.Radius > 0 {
+		f.Ring = geo.CircleRing(f.Center[0], f.Center[1], z.Radius, exportCircleSegments)
+	}
+	return f
+}
+
+func cellFeature(c *domain.Cell) export.Feature {
+	return export.Feature{
+		ID:     c.ID.Hex(),
+		Name:   c.Label,
+		Center: c.Center,
+		Values: []interface{}{c.Label, c.RiskScore, c.UpdatedAt.UTC().Format(time.RFC3339)},
+	}
+}
+
+// timestamp trong DB có chỗ lưu giây, có chỗ lưu ms (report từ client)
+func formatUnix(ts int64) interface{} {
+	if ts <= 0 {
+		return nil
+	}
+	if ts > 1e12 {
+		return time.UnixMilli(ts).UTC().Format(time.RFC3339)
+	}
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
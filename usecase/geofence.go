@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
+)
+
+const (
+	geofenceAlertSearchM  = 5000.0 // alert có tâm xa hơn chừng này thì coi như không phủ tới user
+	geofenceMaxAccuracyM  = 500.0  // fix kém hơn thì không dùng để xét vào / ra
+	geofenceAccuracyCapM  = 100.0  // độ sai GPS cộng vào lề ra tối đa chừng này
+	geofenceDefaultMargin = 50.0
+	geofenceIdleAfter     = time.Hour // user không gửi fix lâu hơn chừng này thì bỏ trạng thái
+	geofenceWorkers       = 4
+	geofenceQueueSize     = 1024 // mỗi worker
+)
+
+type GeofenceConfig struct {
+	ExitMarginM  float64       // phải ra quá mép vùng chừng này mới tính là đã ra
+	Cooldown     time.Duration // ra rồi vào lại trong khoảng này thì không báo lại
+	NotifyGroups bool          // báo cả các nhóm của user
+}
+
+// GeofenceTracker giữ trạng thái trong / ngoài vùng của từng user (trong bộ nhớ).
+// Vào vùng khi cách tâm <= bán kính, chỉ ra khi cách tâm > bán kính + lề để GPS dao động ở mép không báo liên tục.
+type GeofenceTracker struct {
+	cfg    GeofenceConfig
+	mu     sync.Mutex
+	states map[string]*userFence // userID -> trạng thái các vùng
+	swept  time.Time
+}
+
+type userFence struct {
+	areas  map[string]*fenceState // area key -> state
+	lastAt time.Time              // fix gần nhất
+}
+
+type fenceState struct {
+	area      domain.GeofenceArea
+	inside    bool
+	announced bool // user đã được báo là đang ở trong vùng
+	exitedAt  time.Time
+}
+
+func NewGeofenceTracker(cfg GeofenceConfig) *GeofenceTracker {
+	if cfg.ExitMarginM <= 0 {
+		cfg.ExitMarginM = geofenceDefaultMargin
+	}
+	return &GeofenceTracker{cfg: cfg, states: map[string]*userFence{}}
+}
+
+// sweepLocked xóa trạng thái của user không còn gửi location (đã tắt app, mất mạng lâu)
+func (t *GeofenceTracker) sweepLocked(idleSince time.Time) {
+	for id, uf := range t.states {
+		if uf.lastAt.Before(idleSince) {
+			delete(t.states, id)
+		}
+	}
+}
+
+// Len: số user đang có trạng thái
+func (t *GeofenceTracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.states)
+}
+
+// margin: lề ra khỏi vùng, fix càng kém chính xác thì lề càng rộng
+func (t *GeofenceTracker) margin(accuracyM float64) float64 {
+	return math.Max(t.cfg.ExitMarginM, math.Min(accuracyM, geofenceAccuracyCapM))
+}
+
+var labelRank = map[string]int{"LOW": 0, "MEDIUM": 1, "HIGH": 2}
+
+// Update so fix mới với các vùng quanh đó (areas phải gồm mọi vùng cách fix <= bán kính + lề)
+// và trả về các sự kiện cần báo
+func (t *GeofenceTracker) Update(userID string, lat, lon, accuracyM float64, areas []domain.GeofenceArea, now time.Time) []domain.GeofenceEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.swept) > geofenceIdleAfter {
+		t.sweepLocked(now.Add(-geofenceIdleAfter))
+		t.swept = now
+	}
+
+	uf := t.states[userID]
+	if uf == nil {
+		uf = &userFence{areas: map[string]*fenceState{}}
+		t.states[userID] = uf
+	}
+	uf.lastAt = now
+	states := uf.areas
+
+	var events []domain.GeofenceEvent
+	emit := func(typ string, a domain.GeofenceArea) {
+		events = append(events, domain.GeofenceEvent{Type: typ, UserID: userID, Area: a, Lat: lat, Lon: lon, At: now.Unix()})
+	}
+	leave := func(st *fenceState) {
+		if st.announced {
+			emit(domain.GeofenceExit, st.area)
+		}
+		st.inside, st.announced, st.exitedAt = false, false, now
+	}
+
+	seen := map[string]bool{}
+	for _, a := range areas {
+		key := a.Key()
+		seen[key] = true
+		d := geo.DistanceMeters(lat, lon, a.Lat, a.Lon)
+		st := states[key]
+
+		switch {
+		case st != nil && st.inside:
+			if d > a.RadiusM+t.margin(accuracyM) {
+				leave(st)
+				break
+			}
+			if labelRank[a.Label] > labelRank[st.area.Label] {
+				emit(domain.GeofenceEscalate, a)
+				st.announced = true
+			}
+			st.area = a
+
+		case d <= a.RadiusM:
+			if st == nil {
+				st = &fenceState{}
+				states[key] = st
+			}
+			// vào lại ngay sau khi ra cùng mức nguy hiểm: không báo lại
+			recent := !st.exitedAt.IsZero() && now.Sub(st.exitedAt) < t.cfg.Cooldown && st.area.Label == a.Label
+			st.area, st.inside = a, true
+			if !recent {
+				emit(domain.GeofenceEnter, a)
+				st.announced = true
+			}
+		}
+	}
+
+	// vùng không còn quanh fix (đã đi xa, zone hạ mức / bị xóa, alert đã đóng)
+	for key, st := range states {
+		if st.inside && !seen[key] {
+			leave(st)
+		}
+		if !st.inside && now.Sub(st.exitedAt) >= t.cfg.Cooldown {
+			delete(states, key)
+		}
+	}
+	if len(states) == 0 {
+		delete(t.states, userID)
+	}
+	return events
+}
+
+// Geofence xét mỗi fix location với zone MEDIUM / HIGH và alert đang mở,
+// báo cho user qua "geofence_event" và (tùy cấu hình) cho nhóm qua "member_geofence"
+type Geofence struct {
+	tracker   *GeofenceTracker
+	zoneRepo  domain.ZoneRepository
+	alertRepo domain.AlertRepository
+	userRepo  domain.UserRepository
+	sharing   domain.SharingUsecase
+	ws        *ws.WSManager
+	timeout   time.Duration
+
+	// fix chờ xét, mỗi user chỉ giữ fix mới nhất; user luôn vào cùng một worker nên giữ đúng thứ tự
+	mu      sync.Mutex
+	pending map[string]*domain.Location
+	ready   []chan string
+}
+
+func NewGeofence(zoneRepo domain.ZoneRepository, alertRepo domain.AlertRepository, userRepo domain.UserRepository,
+	sharing domain.SharingUsecase, wsm *ws.WSManager, cfg GeofenceConfig, timeout time.Duration) *Geofence {
+	return &Geofence{
+		tracker:   NewGeofenceTracker(cfg),
+		zoneRepo:  zoneRepo,
+		alertRepo: alertRepo,
+		userRepo:  userRepo,
+		sharing:   sharing,
+		ws:        wsm,
+		timeout:   timeout,
+		pending:   map[string]*domain.Location{},
+	}
+}
+
+// Start chạy các worker xét geofence, gọi một lần lúc khởi tạo
+func (g *Geofence) Start() {
+	g.ready = make([]chan string, geofenceWorkers)
+	for i := range g.ready {
+		ch := make(chan string, geofenceQueueSize)
+		g.ready[i] = ch
+		go func() {
+			for userID := range ch {
+				g.mu.Lock()
+				loc := g.pending[userID]
+				delete(g.pending, userID)
+				g.mu.Unlock()
+				if loc != nil {
+					g.Check(userID, loc)
+				}
+			}
+		}()
+	}
+}
+
+// Enqueue đưa fix vào hàng chờ để không chặn luồng lưu location.
+// Fix cũ chưa kịp xét bị thay bằng fix mới; hàng đầy thì bỏ fix (log).
+func (g *Geofence) Enqueue(userID string, loc *domain.Location) {
+	if len(g.ready) == 0 {
+		g.Check(userID, loc)
+		return
+	}
+
+	g.mu.Lock()
+	_, queued := g.pending[userID]
+	g.pending[userID] = loc
+	g.mu.Unlock()
+	if queued {
+		return
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(userID))
+	select {
+	case g.ready[h.Sum32()%uint32(len(g.ready))] <- userID:
+	default:
+		g.mu.Lock()
+		delete(g.pending, userID)
+		g.mu.Unlock()
+		log.Println("geofence: queue full, dropping fix of", userID)
+	}
+}
+
+// Check được gọi sau khi location đã lưu; lỗi chỉ log, không làm hỏng việc cập nhật vị trí
+func (g *Geofence) Check(userID string, loc *domain.Location) {
+	if loc.AccuracyM > geofenceMaxAccuracyM {
+		return
+	}
+	lat, lon := loc.Location.Coordinates[1], loc.Location.Coordinates[0]
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+
+	areas, err := g.areasAround(ctx, lat, lon, g.tracker.margin(loc.AccuracyM))
+	if err != nil {
+		log.Println("geofence: load areas failed:", err)
+		return
+	}
+
+	events := g.tracker.Update(userID, lat, lon, loc.AccuracyM, areas, time.Now())
+	if len(events) == 0 {
+		return
+	}
+	for _, e := range events {
+		g.ws.SendToUser(userID, "geofence_event", e)
+	}
+	if g.tracker.cfg.NotifyGroups {
+		g.notifyGroups(ctx, userID, loc, events)
+	}
+}
+
+// areasAround lấy các vùng có thể chứa điểm (kể cả lề ra)
+func (g *Geofence) areasAround(ctx context.Context, lat, lon, marginM float64) ([]domain.GeofenceArea, error) {
+	minLat, minLon, maxLat, maxLon := geo.BoundsAround(lat, lon, marginM)
+	zones, err := g.zoneRepo.FetchInBounds(ctx, minLat, minLon, maxLat, maxLon)
+	if err != nil {
+		return nil, err
+	}
+
+	var areas []domain.GeofenceArea
+	for _, z := range zones {
+		if z.Label != "MEDIUM" && z.Label != "HIGH" {
+			continue
+		}
+		zLat, zLon := z.Center.Coordinates[1], z.Center.Coordinates[0]
+		if geo.DistanceMeters(lat, lon, zLat, zLon) > z.Radius+marginM {
+			continue
+		}
+		areas = append(areas, domain.GeofenceArea{
+			Kind: domain.GeofenceZone, ID: z.ID.Hex(), Label: z.Label, RiskScore: z.RiskScore, Hazard: z.Hazard,
+			Lat: zLat, Lon: zLon, RadiusM: z.Radius,
+		})
+	}
+
+	minLat, minLon, maxLat, maxLon = geo.BoundsAround(lat, lon, geofenceAlertSearchM)
+	alerts, err := g.alertRepo.FetchActiveInBounds(ctx, domain.BoundsFilter{MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon})
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range alerts {
+		aLat, aLon := a.Location.Coordinates[1], a.Location.Coordinates[0]
+		if a.RadiusM <= 0 || geo.DistanceMeters(lat, lon, aLat, aLon) > a.RadiusM+marginM {
+			continue
+		}
+		areas = append(areas, domain.GeofenceArea{
+			Kind: domain.GeofenceAlert, ID: a.ID.Hex(), Label: "HIGH", RiskScore: alertRouteRisk,
+			Lat: aLat, Lon: aLon, RadiusM: a.RadiusM,
+		})
+	}
+	return areas, nil
+}
+
+// notifyGroups gửi sự kiện cho thành viên các nhóm theo cài đặt chia sẻ vị trí của user:
+// tạm dừng thì không gửi (trừ khi đang SOS), tọa độ làm mờ như location
+func (g *Geofence) notifyGroups(ctx context.Context, userID string, loc *domain.Location, events []domain.GeofenceEvent) {
+	audience, err := g.sharing.Audience(ctx, userID, loc)
+	if err != nil {
+		log.Println("geofence: load audience failed:", err)
+		return
+	}
+	shared := MemberGeofenceEvents(audience, events)
+	if len(shared) == 0 {
+		return
+	}
+
+	user, err := g.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Println("geofence: load user failed:", err)
+		return
+	}
+	for viewerID := range audience.Viewers {
+		for _, e := range shared {
+			g.ws.SendToUser(viewerID, "member_geofence", map[string]interface{}{
+				"groupId":  audience.Groups[viewerID].Hex(),
+				"userName": user.Name,
+				"event":    e,
+			})
+		}
+	}
+}
+
+// MemberGeofenceEvents: bản sự kiện gửi cho nhóm, tọa độ thay bằng vị trí đã làm mờ của audience
+func MemberGeofenceEvents(audience *domain.LocationAudience, events []domain.GeofenceEvent) []domain.GeofenceEvent {
+	if len(audience.Viewers) == 0 || audience.Location == nil {
+		return nil
+	}
+	lon, lat := audience.Location.Location.Coordinates[0], audience.Location.Location.Coordinates[1]
+	out := make([]domain.GeofenceEvent, len(events))
+	for i, e := range events {
+		e.Lat, e.Lon = lat, lon
+		out[i] = e
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func types(events []domain.GeofenceEvent) []string {
+	out := []string{}
+	for _, e := range events {
+		out = append(out, e.Type)
+	}
+	return out
+}
+
+func TestGeofenceTracker(t *testing.T) {
+	zone := domain.GeofenceArea{Kind: domain.GeofenceZone, ID: "z1", Label: "MEDIUM", Lat: 10, Lon: 106, RadiusM: 200}
+	areas := []domain.GeofenceArea{zone}
+	now := time.Unix(1700000000, 0)
+
+	// lệch 0.001 độ vĩ ~ 111m
+	tr := usecase.NewGeofenceTracker(usecase.GeofenceConfig{ExitMarginM: 50, Cooldown: 10 * time.Minute})
+
+	// ngoài vùng
+	assert.Empty(t, tr.Update("u1", 10.003, 106, 10, areas, now))
+
+	// vào vùng
+	assert.Equal(t, []string{domain.GeofenceEnter}, types(tr.Update("u1", 10.001, 106, 10, areas, now)))
+
+	// dao động quanh mép (220m < 200 + 50) không tính là ra
+	assert.Empty(t, tr.Update("u1", 10.00198, 106, 10, areas, now.Add(time.Minute)))
+	assert.Empty(t, tr.Update("u1", 10.0017, 106, 10, areas, now.Add(2*time.Minute)))
+
+	// zone lên HIGH khi đang ở trong
+	high := zone
+	high.Label = "HIGH"
+	assert.Equal(t, []string{domain.GeofenceEscalate}, types(tr.Update("u1", 10.001, 106, 10, []domain.GeofenceArea{high}, now.Add(3*time.Minute))))
+
+	// ra quá lề
+	events := tr.Update("u1", 10.0025, 106, 10, []domain.GeofenceArea{high}, now.Add(4*time.Minute))
+	assert.Equal(t, []string{domain.GeofenceExit}, types(events))
+	assert.Equal(t, "HIGH", events[0].Area.Label)
+
+	// vào lại ngay: không báo, ra lại cũng không báo
+	assert.Empty(t, tr.Update("u1", 10.001, 106, 10, []domain.GeofenceArea{high}, now.Add(5*time.Minute)))
+	assert.Empty(t, tr.Update("u1", 10.0025, 106, 10, []domain.GeofenceArea{high}, now.Add(6*time.Minute)))
+
+	// hết cooldown thì báo lại
+	assert.Equal(t, []string{domain.GeofenceEnter}, types(tr.Update("u1", 10.001, 106, 10, []domain.GeofenceArea{high}, now.Add(30*time.Minute))))
+
+	// zone biến mất (bị xóa / hạ xuống LOW) khi đang ở trong
+	assert.Equal(t, []string{domain.GeofenceExit}, types(tr.Update("u1", 10.001, 106, 10, nil, now.Add(31*time.Minute))))
+}
+
+func TestGeofenceTrackerPerUser(t *testing.T) {
+	alert := domain.GeofenceArea{Kind: domain.GeofenceAlert, ID: "a1", Label: "HIGH", Lat: 10, Lon: 106, RadiusM: 500}
+	tr := usecase.NewGeofenceTracker(usecase.GeofenceConfig{Cooldown: time.Minute})
+	now := time.Now()
+
+	assert.Len(t, tr.Update("u1", 10, 106, 5, []domain.GeofenceArea{alert}, now), 1)
+	assert.Len(t, tr.Update("u2", 10, 106, 5, []domain.GeofenceArea{alert}, now), 1)
+	assert.Empty(t, tr.Update("u1", 10, 106, 5, []domain.GeofenceArea{alert}, now))
+}
+
+func TestGeofenceTrackerSweepsIdleUsers(t *testing.T) {
+	alert := domain.GeofenceArea{Kind: domain.GeofenceAlert, ID: "a1", Label: "HIGH", Lat: 10, Lon: 106, RadiusM: 500}
+	tr := usecase.NewGeofenceTracker(usecase.GeofenceConfig{Cooldown: time.Minute})
+	now := time.Now()
+
+	tr.Update("u1", 10, 106, 5, []domain.GeofenceArea{alert}, now)
+	tr.Update("u2", 10, 106, 5, []domain.GeofenceArea{alert}, now.Add(30*time.Minute))
+	assert.Equal(t, 2, tr.Len())
+
+	// u1 im lặng quá lâu (dù vẫn đang ở trong vùng) thì bị bỏ
+	tr.Update("u2", 10, 106, 5, []domain.GeofenceArea{alert}, now.Add(2*time.Hour))
+	assert.Equal(t, 1, tr.Len())
+}
+
+func TestMemberGeofenceEventsFollowSharing(t *testing.T) {
+	f := newSharingFixture()
+	uc := f.usecase()
+	ctx := context.Background()
+	user := f.alice.ID.Hex()
+	loc := &domain.Location{Location: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106.70123, 10.77654}}}
+	events := []domain.GeofenceEvent{{Type: domain.GeofenceEnter, UserID: user, Lat: 10.77654, Lon: 106.70123}}
+
+	// AREA: nhóm nhận sự kiện nhưng tọa độ đã làm mờ
+	_, err := uc.Update(ctx, user, nil, domain.SharingArea)
+	require.NoError(t, err)
+	a, err := uc.Audience(ctx, user, loc)
+	require.NoError(t, err)
+	out := usecase.MemberGeofenceEvents(a, events)
+	require.Len(t, out, 1)
+	assert.NotEqual(t, 10.77654, out[0].Lat)
+	assert.Equal(t, a.Location.Location.Coordinates, [2]float64{out[0].Lon, out[0].Lat})
+	assert.Equal(t, f.family, a.Groups[f.bob.ID.Hex()])
+
+	// tạm dừng: không gửi gì
+	_, err = uc.Pause(ctx, user, time.Hour)
+	require.NoError(t, err)
+	a, err = uc.Audience(ctx, user, loc)
+	require.NoError(t, err)
+	assert.Empty(t, usecase.MemberGeofenceEvents(a, events))
+
+	// đang SOS: gửi tọa độ chính xác dù đang tạm dừng
+	f.sos.active[user] = true
+	a, err = uc.Audience(ctx, user, loc)
+	require.NoError(t, err)
+	out = usecase.MemberGeofenceEvents(a, events)
+	require.Len(t, out, 1)
+	assert.Equal(t, 10.77654, out[0].Lat)
+	assert.Equal(t, 106.70123, out[0].Lon)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/invite"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -15,14 +18,26 @@ type groupUsecase struct {
 	memberRepository   domain.MemberRepository
 	userRepository     domain.UserRepository
 	locationRepository domain.LocationRepository
+	sharing            domain.SharingUsecase
+	invite             InviteConfig
 	contextTimeout     time.Duration
 }
 
+type InviteConfig struct {
+	TTL      time.Duration // hạn mặc định của mã mời
+	LinkBase string        // vd "stormwatch://join" -> link "stormwatch://join/ABCD2345"
+}
+
+// số lần sinh lại mã khi trùng với nhóm khác
+const maxInviteCodeTries = 5
+
 func NewGroupUsecase(
 	groupRepo domain.GroupRepository,
 	memberRepo domain.MemberRepository,
 	userRepo domain.UserRepository,
 	locationRepo domain.LocationRepository,
+	sharing domain.SharingUsecase,
+	invite InviteConfig,
 	timeout time.Duration,
 ) domain.GroupUsecase {
 	return &groupUsecase{
@@ -30,83 +45,352 @@ func NewGroupUsecase(
 		memberRepository:   memberRepo,
 		userRepository:     userRepo,
 		locationRepository: locationRepo,
+		sharing:            sharing,
+		invite:             invite,
 		contextTimeout:     timeout,
 	}
 }
 
-// 📍 Tạo group mới
-func (gu *groupUsecase) CreateGroup(c context.Context, name string) (*domain.Group, error) {
+// 📍 Tạo group mới, người tạo là owner
+func (gu *groupUsecase) CreateGroup(c context.Context, userID, name string) (*domain.Group, error) {
 	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
 	defer cancel()
 
-	// userObjID, err := primitive.ObjectIDFromHex(userID)
-	// if err != nil {
-	// 	return nil, errors.New("invalid user id")
-	// }
+	userObjID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("invalid user id")
+	}
 
 	group := &domain.Group{
-		ID:         primitive.NewObjectID(),
-		Name:       name,
-		InviteCode: primitive.NewObjectID().Hex()[18:], // lấy 6 ký tự cuối làm mã
-		CreatedAt:  time.Now(),
-		ExpiresAt:  time.Now().Add(24 * time.Hour),
-		MemberIDs:  []primitive.ObjectID{},
+		ID:        primitive.NewObjectID(),
+		Name:      name,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(gu.invite.TTL),
+		MemberIDs: []primitive.ObjectID{userObjID},
 	}
 
-	// 1️⃣ Tạo group
-	if err := gu.groupRepository.Create(ctx, group); err != nil {
+	// 1️⃣ Tạo group kèm mã mời
+	if err := withNewInviteCode(func(code string) error {
+		group.InviteCode = code
+		return gu.groupRepository.Create(ctx, group)
+	}); err != nil {
 		return nil, err
 	}
 
-	// 2️⃣ Tự động thêm user làm thành viên đầu tiên (owner)
-	// member := &domain.Member{
-	// 	ID:        primitive.NewObjectID(),
-	// 	GroupID:   group.ID,
-	// 	UserID:    userObjID,
-	// 	Lat:       0,
-	// 	Lon:       0,
-	// 	UpdatedAt: time.Now(),
-	// }
-	// _ = gu.memberRepository.Add(ctx, member)
+	// 2️⃣ Người tạo là thành viên đầu tiên (owner)
+	member := &domain.Member{
+		ID:        primitive.NewObjectID(),
+		GroupID:   group.ID,
+		UserID:    userObjID,
+		Role:      domain.GroupRoleOwner,
+		UpdatedAt: time.Now(),
+	}
+	if err := gu.memberRepository.Add(ctx, member); err != nil {
+		return nil, fmt.Errorf("failed to add member: %v", err)
+	}
+	if err := gu.userRepository.AddGroup(ctx, userObjID, group.ID); err != nil {
+		return nil, fmt.Errorf("failed to update user: %v", err)
+	}
 
 	return group, nil
 }
 
-// 📍 Lấy group bằng mã invite
+// 📍 Xem trước group bằng mã invite, mã phải còn hạn và còn lượt
 func (gu *groupUsecase) GetByInviteCode(c context.Context, code string) (*domain.Group, error) {
 	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
 	defer cancel()
 
-	group, err := gu.groupRepository.GetByInviteCode(ctx, code)
+	group, err := gu.groupByInvite(ctx, code)
 	if err != nil {
 		return nil, err
 	}
-	return &group, nil
+	if err := CheckInvite(group, time.Now()); err != nil {
+		return nil, err
+	}
+	return group, nil
 }
 
-// 📍 Xóa group
+// 📍 Xóa group, chỉ owner
 func (gu *groupUsecase) DeleteGroup(c context.Context, userID string, groupID primitive.ObjectID) error {
 	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
 	defer cancel()
 
-	// kiểm tra user có tồn tại không
-	_, err := gu.userRepository.GetByID(ctx, userID)
+	group, roles, err := gu.groupWithRoles(ctx, groupID)
 	if err != nil {
-		return errors.New("user not found")
+		return err
 	}
+	role, err := actorRole(roles, userID)
+	if err != nil {
+		return err
+	}
+	if role != domain.GroupRoleOwner {
+		return domain.ErrGroupForbidden
+	}
+	return gu.deleteGroup(ctx, group.ID)
+}
 
-	return gu.groupRepository.Delete(ctx, groupID)
+// 📍 Rời nhóm
+func (gu *groupUsecase) LeaveGroup(c context.Context, userID string, groupID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
+	defer cancel()
+
+	group, roles, err := gu.groupWithRoles(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	role, err := actorRole(roles, userID)
+	if err != nil {
+		return err
+	}
+	if role == domain.GroupRoleOwner {
+		if len(group.MemberIDs) > 1 {
+			return domain.ErrOwnerMustTransfer
+		}
+		// owner là người cuối cùng -> nhóm không còn ai, xóa luôn
+		return gu.deleteGroup(ctx, group.ID)
+	}
+	uid, _ := primitive.ObjectIDFromHex(userID)
+	return gu.removeFromGroup(ctx, group.ID, uid)
+}
+
+// 📍 Kick thành viên: chỉ kick được người có role thấp hơn mình
+func (gu *groupUsecase) RemoveMember(c context.Context, actorID string, groupID primitive.ObjectID, memberID string) error {
+	if actorID == memberID {
+		return gu.LeaveGroup(c, actorID, groupID)
+	}
+
+	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
+	defer cancel()
+
+	group, roles, err := gu.groupWithRoles(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	role, err := actorRole(roles, actorID)
+	if err != nil {
+		return err
+	}
+	target, targetRole, err := targetRole(roles, memberID)
+	if err != nil {
+		return err
+	}
+	if role.Rank() < domain.GroupRoleAdmin.Rank() || role.Rank() <= targetRole.Rank() {
+		return domain.ErrGroupForbidden
+	}
+	return gu.removeFromGroup(ctx, group.ID, target)
+}
+
+// 📍 Đổi role admin <-> member, chỉ owner
+func (gu *groupUsecase) SetMemberRole(c context.Context, actorID string, groupID primitive.ObjectID, memberID string, newRole domain.GroupRole) error {
+	if newRole != domain.GroupRoleAdmin && newRole != domain.GroupRoleMember {
+		return domain.ErrInvalidGroupRole
+	}
+
+	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
+	defer cancel()
+
+	group, roles, err := gu.groupWithRoles(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	role, err := actorRole(roles, actorID)
+	if err != nil {
+		return err
+	}
+	target, targetRole, err := targetRole(roles, memberID)
+	if err != nil {
+		return err
+	}
+	// owner không tự hạ quyền, phải chuyển owner cho người khác
+	if role != domain.GroupRoleOwner || targetRole == domain.GroupRoleOwner {
+		return domain.ErrGroupForbidden
+	}
+	if targetRole == newRole {
+		return nil
+	}
+	return gu.memberRepository.SetRole(ctx, group.ID, target, newRole)
 }
 
-func (gu *groupUsecase) GetInviteCodeByGroupID(c context.Context, groupID primitive.ObjectID) (string, error) {
+// 📍 Chuyển owner, owner cũ thành admin
+func (gu *groupUsecase) TransferOwnership(c context.Context, actorID string, groupID primitive.ObjectID, newOwnerID string) error {
 	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
 	defer cancel()
 
+	group, roles, err := gu.groupWithRoles(ctx, groupID)
+	if err != nil {
+		return err
+	}
+	role, err := actorRole(roles, actorID)
+	if err != nil {
+		return err
+	}
+	target, _, err := targetRole(roles, newOwnerID)
+	if err != nil {
+		return err
+	}
+	if role != domain.GroupRoleOwner {
+		return domain.ErrGroupForbidden
+	}
+	if actorID == newOwnerID {
+		return nil
+	}
+
+	// nâng người mới trước: lỗi giữa chừng thì nhóm có 2 owner chứ không bị mất owner
+	if err := gu.memberRepository.SetRole(ctx, group.ID, target, domain.GroupRoleOwner); err != nil {
+		return err
+	}
+	actor, _ := primitive.ObjectIDFromHex(actorID)
+	return gu.memberRepository.SetRole(ctx, group.ID, actor, domain.GroupRoleAdmin)
+}
+
+// groupWithRoles: group và role của từng thành viên trong group.memberIDs
+func (gu *groupUsecase) groupWithRoles(ctx context.Context, groupID primitive.ObjectID) (*domain.Group, map[string]domain.GroupRole, error) {
 	group, err := gu.groupRepository.GetByID(ctx, groupID)
 	if err != nil {
-		return "", err
+		return nil, nil, err
+	}
+	members, err := gu.memberRepository.ListByGroup(ctx, groupID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &group, GroupRoles(group.MemberIDs, members), nil
+}
+
+// GroupRoles: role theo userID (hex) cho mọi id trong memberIDs.
+// Bản ghi member của người đã rời nhóm bị bỏ qua; thành viên chưa có bản ghi là member.
+// Owner của nhóm tạo trước khi có role được ghi lúc khởi động (repository.BackfillGroupOwners).
+func GroupRoles(memberIDs []primitive.ObjectID, members []domain.Member) map[string]domain.GroupRole {
+	roles := make(map[string]domain.GroupRole, len(memberIDs))
+	for _, id := range memberIDs {
+		roles[id.Hex()] = domain.GroupRoleMember
+	}
+	for _, m := range members {
+		key := m.UserID.Hex()
+		if _, ok := roles[key]; ok && m.Role.Rank() > roles[key].Rank() {
+			roles[key] = m.Role.Normalize()
+		}
+	}
+	return roles
+}
+
+func actorRole(roles map[string]domain.GroupRole, actorID string) (domain.GroupRole, error) {
+	role, ok := roles[actorID]
+	if !ok {
+		return "", domain.ErrNotGroupMember
+	}
+	return role, nil
+}
+
+func targetRole(roles map[string]domain.GroupRole, userID string) (primitive.ObjectID, domain.GroupRole, error) {
+	role, ok := roles[userID]
+	if !ok {
+		return primitive.NilObjectID, "", domain.ErrGroupMemberNotFound
+	}
+	id, _ := primitive.ObjectIDFromHex(userID)
+	return id, role, nil
+}
+
+// removeFromGroup bỏ quyền xem (group.memberIDs) trước, rồi tới user.groupIDs và bản ghi member
+func (gu *groupUsecase) removeFromGroup(ctx context.Context, groupID, userID primitive.ObjectID) error {
+	if err := gu.groupRepository.RemoveMember(ctx, groupID, userID); err != nil {
+		return fmt.Errorf("failed to update group: %v", err)
+	}
+	if err := gu.userRepository.RemoveGroup(ctx, userID, groupID); err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+	if err := gu.memberRepository.Remove(ctx, groupID, userID); err != nil {
+		return fmt.Errorf("failed to remove member: %v", err)
+	}
+	return nil
+}
+
+func (gu *groupUsecase) deleteGroup(ctx context.Context, groupID primitive.ObjectID) error {
+	if err := gu.groupRepository.Delete(ctx, groupID); err != nil {
+		return err
+	}
+	if err := gu.memberRepository.DeleteByGroup(ctx, groupID); err != nil {
+		return fmt.Errorf("failed to remove members: %v", err)
+	}
+	if err := gu.userRepository.RemoveGroupFromAll(ctx, groupID); err != nil {
+		return fmt.Errorf("failed to update users: %v", err)
+	}
+	return nil
+}
+
+// 📍 Mã mời hiện tại, mọi thành viên xem được
+func (gu *groupUsecase) GetInvite(c context.Context, userID string, groupID primitive.ObjectID) (*domain.Invite, error) {
+	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
+	defer cancel()
+
+	group, err := gu.memberGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.InviteCode == "" {
+		return nil, domain.ErrInviteNotEnabled
+	}
+	return groupInvite(group), nil
+}
+
+// 📍 Deep link / nội dung QR của mã mời; mã hết hạn hay hết lượt thì phải xin admin tạo mã mới
+func (gu *groupUsecase) InviteLink(c context.Context, userID string, groupID primitive.ObjectID) (*domain.InviteLink, error) {
+	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
+	defer cancel()
+
+	group, err := gu.memberGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckInvite(group, time.Now()); err != nil {
+		return nil, err
 	}
-	return group.InviteCode, nil
+	return &domain.InviteLink{
+		Code:      group.InviteCode,
+		Link:      strings.TrimRight(gu.invite.LinkBase, "/") + "/" + group.InviteCode,
+		ExpiresAt: group.ExpiresAt,
+	}, nil
+}
+
+// 📍 Tạo mã mới (mã cũ hết hiệu lực ngay), chỉ owner / admin
+func (gu *groupUsecase) RotateInvite(c context.Context, userID string, groupID primitive.ObjectID, ttl time.Duration, maxUses int) (*domain.Invite, error) {
+	// 0 = mặc định / không giới hạn, số âm là lỗi của người gọi
+	if ttl < 0 || maxUses < 0 {
+		return nil, domain.ErrInviteSettings
+	}
+	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
+	defer cancel()
+
+	group, err := gu.adminGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		ttl = gu.invite.TTL
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	if err := withNewInviteCode(func(code string) error {
+		group.InviteCode = code
+		return gu.groupRepository.SetInvite(ctx, group.ID, code, expiresAt, maxUses)
+	}); err != nil {
+		return nil, err
+	}
+	group.ExpiresAt = expiresAt
+	group.InviteMaxUses = maxUses
+	group.InviteUses = 0
+	return groupInvite(group), nil
+}
+
+// 📍 Thu hồi mã, không ai join được tới khi tạo mã mới; chỉ owner / admin
+func (gu *groupUsecase) RevokeInvite(c context.Context, userID string, groupID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(c, gu.contextTimeout)
+	defer cancel()
+
+	group, err := gu.adminGroup(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	return gu.groupRepository.RevokeInvite(ctx, group.ID)
 }
 
 func (u *groupUsecase) JoinGroup(ctx context.Context, userID string, inviteCode string) error {
@@ -114,9 +398,9 @@ func (u *groupUsecase) JoinGroup(ctx context.Context, userID string, inviteCode
 	defer cancel()
 
 	// 1️⃣ Tìm group theo invite code
-	group, err := u.groupRepository.GetByInviteCode(c, inviteCode)
+	group, err := u.groupByInvite(c, inviteCode)
 	if err != nil {
-		return fmt.Errorf("invalid invite code")
+		return err
 	}
 
 	// 2️⃣ Convert userID → ObjectID
@@ -125,46 +409,130 @@ func (u *groupUsecase) JoinGroup(ctx context.Context, userID string, inviteCode
 		return fmt.Errorf("invalid user id")
 	}
 
-	// 3️⃣ Kiểm tra xem user đã ở trong group chưa
-	existingMembers, err := u.memberRepository.ListByGroup(c, group.ID)
+	// 3️⃣ Kiểm tra xem user đã ở trong group chưa, mã còn hạn / còn lượt không
+	if containsID(group.MemberIDs, uid) {
+		return domain.ErrAlreadyInGroup
+	}
+	now := time.Now()
+	if err := CheckInvite(group, now); err != nil {
+		return err
+	}
+	// trừ lượt có điều kiện: người khác vừa dùng lượt cuối hoặc mã vừa bị đổi thì dừng
+	ok, err := u.groupRepository.UseInvite(c, group.ID, group.InviteCode, now)
 	if err != nil {
-		return fmt.Errorf("failed to check members: %v", err)
+		return err
 	}
-
-	for _, m := range existingMembers {
-		if m.UserID == uid {
-			return fmt.Errorf("user already in group")
-		}
+	if !ok {
+		return domain.ErrInviteExhausted
 	}
 
-	// 3️⃣ Thêm member mới vào collection members
+	// 4️⃣ Thêm member mới vào collection members
 	member := &domain.Member{
 		ID:        primitive.NewObjectID(),
 		GroupID:   group.ID,
 		UserID:    uid,
-		Lat:       0,
-		Lon:       0,
-		UpdatedAt: time.Now(),
+		Role:      domain.GroupRoleMember,
+		UpdatedAt: now,
 	}
-
-	// ➕ Thêm record member mới
 	if err := u.memberRepository.Add(c, member); err != nil {
 		return fmt.Errorf("failed to add member: %v", err)
 	}
 
-	// 4️⃣ Thêm userID vào group.memberIDs
+	// 5️⃣ Thêm groupID vào user.groupIds
+	if err := u.userRepository.AddGroup(c, uid, group.ID); err != nil {
+		return fmt.Errorf("failed to update user: %v", err)
+	}
+
+	// 6️⃣ Thêm userID vào group.memberIDs (sau cùng: từ đây mới có quyền xem nhóm)
 	if err := u.groupRepository.AddMember(c, group.ID, uid); err != nil {
 		return fmt.Errorf("failed to update group: %v", err)
 	}
 
-	// 5️⃣ Thêm groupID vào user.groupIds
-	if err := u.userRepository.AddGroup(c, uid, group.ID); err != nil {
-		return fmt.Errorf("failed to update user: %v", err)
+	return nil
+}
+
+// groupByInvite: mã người dùng gõ được chuẩn hóa trước khi tìm
+func (gu *groupUsecase) groupByInvite(ctx context.Context, code string) (*domain.Group, error) {
+	code = invite.Normalize(code)
+	if !invite.Valid(code) {
+		return nil, domain.ErrInvalidInvite
+	}
+	group, err := gu.groupRepository.GetByInviteCode(ctx, code)
+	if err != nil {
+		return nil, domain.ErrInvalidInvite
 	}
+	return &group, nil
+}
 
+// CheckInvite: mã của group còn dùng được tại thời điểm now không
+func CheckInvite(group *domain.Group, now time.Time) error {
+	switch {
+	case group.InviteCode == "":
+		return domain.ErrInviteNotEnabled
+	case !now.Before(group.ExpiresAt):
+		return domain.ErrInviteExpired
+	case group.InviteMaxUses > 0 && group.InviteUses >= group.InviteMaxUses:
+		return domain.ErrInviteExhausted
+	}
 	return nil
 }
 
+func groupInvite(group *domain.Group) *domain.Invite {
+	return &domain.Invite{
+		GroupID:   group.ID.Hex(),
+		Code:      group.InviteCode,
+		ExpiresAt: group.ExpiresAt,
+		MaxUses:   group.InviteMaxUses,
+		Uses:      group.InviteUses,
+	}
+}
+
+// withNewInviteCode gọi save với mã mới tới khi không bị trùng
+func withNewInviteCode(save func(code string) error) error {
+	for i := 0; i < maxInviteCodeTries; i++ {
+		code, err := invite.NewCode()
+		if err != nil {
+			return err
+		}
+		if err := save(code); !errors.Is(err, domain.ErrInviteCodeTaken) {
+			return err
+		}
+	}
+	return domain.ErrInviteCodeTaken
+}
+
+// memberGroup: group nếu userID là thành viên
+func (gu *groupUsecase) memberGroup(ctx context.Context, userID string, groupID primitive.ObjectID) (*domain.Group, error) {
+	uid, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("invalid user id")
+	}
+	group, err := gu.groupRepository.GetByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if !containsID(group.MemberIDs, uid) {
+		return nil, domain.ErrNotGroupMember
+	}
+	return &group, nil
+}
+
+// adminGroup: group nếu userID là owner / admin
+func (gu *groupUsecase) adminGroup(ctx context.Context, userID string, groupID primitive.ObjectID) (*domain.Group, error) {
+	group, roles, err := gu.groupWithRoles(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	role, err := actorRole(roles, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role.Rank() < domain.GroupRoleAdmin.Rank() {
+		return nil, domain.ErrGroupForbidden
+	}
+	return group, nil
+}
+
 // 📍 Lấy thông tin Group từ string ID
 func (u *groupUsecase) GetGroupByIDString(ctx context.Context, idStr string) (*domain.Group, error) {
 	c, cancel := context.WithTimeout(ctx, u.contextTimeout)
@@ -185,63 +553,145 @@ func (u *groupUsecase) GetGroupByIDString(ctx context.Context, idStr string) (*d
 	return &group, nil
 }
 
-func (gu *groupUsecase) GetMemberInGroup(ctx context.Context, groupID, memberID string) (*domain.GroupMemberDetail, error) {
+// GetMemberInGroup chỉ cho thành viên cùng nhóm xem; vị trí theo cài đặt chia sẻ của member
+func (gu *groupUsecase) GetMemberInGroup(ctx context.Context, viewerID, groupID, memberID string) (*domain.GroupMemberDetail, error) {
+	uID, err := primitive.ObjectIDFromHex(memberID)
+	if err != nil {
+		return nil, err
+	}
+	members, err := gu.listMembers(ctx, viewerID, groupID, []primitive.ObjectID{uID})
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("user not in this group")
+	}
+	return &members[0], nil
+}
+
+func (gu *groupUsecase) ListMembers(ctx context.Context, viewerID, groupID string) ([]domain.GroupMemberDetail, error) {
+	return gu.listMembers(ctx, viewerID, groupID, nil)
+}
+
+// listMembers: chi tiết các thành viên (only = nil là mọi thành viên).
+// Số query cố định: group, members, users, locations, cài đặt chia sẻ, SOS.
+func (gu *groupUsecase) listMembers(ctx context.Context, viewerID, groupID string, only []primitive.ObjectID) ([]domain.GroupMemberDetail, error) {
+	ctx, cancel := context.WithTimeout(ctx, gu.contextTimeout)
+	defer cancel()
 
-	// Convert IDs
 	gID, err := primitive.ObjectIDFromHex(groupID)
 	if err != nil {
 		return nil, err
 	}
-	uID, err := primitive.ObjectIDFromHex(memberID)
+	vID, err := primitive.ObjectIDFromHex(viewerID)
 	if err != nil {
 		return nil, err
 	}
-	// Step 1: Load group
 	group, err := gu.groupRepository.GetByID(ctx, gID)
 	if err != nil {
 		return nil, err
 	}
+	if !containsID(group.MemberIDs, vID) {
+		return nil, domain.ErrNotGroupMember
+	}
 
-	// Step 2: Check membership
-	isMember := false
-	for _, id := range group.MemberIDs {
-		if id == uID {
-			isMember = true
-			break
+	ids := group.MemberIDs
+	if only != nil {
+		ids = nil
+		for _, id := range only {
+			if containsID(group.MemberIDs, id) {
+				ids = append(ids, id)
+			}
 		}
 	}
-	if !isMember {
-		return nil, fmt.Errorf("user not in this group")
+	// người xem luôn được load để tính quyền và khoảng cách
+	load := ids
+	if !containsID(ids, vID) {
+		load = append(append([]primitive.ObjectID{}, ids...), vID)
 	}
 
-	// Step 3: Load user info
-	user, err := gu.userRepository.GetByID(ctx, uID.Hex())
+	members, err := gu.memberRepository.ListByGroup(ctx, gID)
 	if err != nil {
 		return nil, err
 	}
+	roles := GroupRoles(group.MemberIDs, members)
+
+	users, err := gu.userRepository.GetByIDs(ctx, load)
+	if err != nil {
+		return nil, err
+	}
+	var viewer *domain.User
+	hexIDs := make([]string, len(users))
+	for i := range users {
+		hexIDs[i] = users[i].ID.Hex()
+		if users[i].ID == vID {
+			viewer = &users[i]
+		}
+	}
+	if viewer == nil {
+		return nil, errors.New("user not found")
+	}
 
-	// Step 4: Load latest location for this user
-	loc, err := gu.locationRepository.GetByUserID(ctx, uID.Hex())
+	decisions, err := gu.sharing.DecideMany(ctx, *viewer, users)
 	if err != nil {
-		// location optional -> return user only
-		loc = nil
+		return nil, err
+	}
+	locs, err := gu.locationRepository.GetByUserIDs(ctx, hexIDs)
+	if err != nil {
+		return nil, err
+	}
+	locByUser := make(map[string]*domain.Location, len(locs))
+	for i := range locs {
+		locByUser[locs[i].ID] = &locs[i]
+	}
+	viewerLoc := locByUser[viewerID]
+
+	now := time.Now()
+	byID := make(map[primitive.ObjectID]*domain.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
 	}
+	out := make([]domain.GroupMemberDetail, 0, len(ids))
+	for _, id := range ids {
+		user := byID[id]
+		if user == nil {
+			continue // user đã bị xóa
+		}
+		hex := id.Hex()
+		out = append(out, memberDetail(*user, roles[hex], locByUser[hex], decisions[hex], viewerLoc, now))
+	}
+	return out, nil
+}
 
-	// Step 5: Combine response
-	resp := &domain.GroupMemberDetail{
+// memberDetail ghép thông tin user với vị trí đã làm mờ theo quyết định chia sẻ
+func memberDetail(user domain.User, role domain.GroupRole, loc *domain.Location, d domain.SharingDecision, viewerLoc *domain.Location, now time.Time) domain.GroupMemberDetail {
+	detail := domain.GroupMemberDetail{
 		ID:    user.ID.Hex(),
 		Name:  user.Name,
 		Phone: user.Phone,
+		Role:  role,
 	}
-
-	if loc != nil {
-		resp.Location = domain.MemberLocation{
-			AccuracyM:   loc.AccuracyM,
-			Status:      loc.Status,
-			UpdatedAt:   loc.UpdatedAt,
-			Coordinates: loc.Location,
-		}
+	if loc == nil || !d.Allowed {
+		return detail
 	}
 
-	return resp, nil
+	loc = CoarsenLocation(loc, d.Precision)
+	detail.LocationShared = true
+	detail.Location = domain.MemberLocation{
+		AccuracyM:   loc.AccuracyM,
+		Type:        loc.Location.Type,
+		Status:      loc.Status,
+		UpdatedAt:   loc.UpdatedAt,
+		Coordinates: loc.Location,
+		AgeSec:      max(0, now.Unix()-loc.UpdatedAt),
+		Stale:       loc.StaleSince > 0,
+		StaleSince:  loc.StaleSince,
+		LastStatus:  loc.LastStatus,
+	}
+	if viewerLoc != nil {
+		dist := geo.DistanceMeters(viewerLoc.Location.Coordinates[1], viewerLoc.Location.Coordinates[0],
+			loc.Location.Coordinates[1], loc.Location.Coordinates[0])
+		detail.DistanceM = &dist
+	}
+	return detail
 }
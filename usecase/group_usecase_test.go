@@ -0,0 +1,302 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGroupRoles(t *testing.T) {
+	owner, admin, legacy, left := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	memberIDs := []primitive.ObjectID{owner, admin, legacy}
+	members := []domain.Member{
+		{UserID: owner, Role: domain.GroupRoleOwner},
+		{UserID: admin, Role: domain.GroupRoleMember},
+		{UserID: admin, Role: domain.GroupRoleAdmin}, // bản ghi trùng: lấy role cao nhất
+		{UserID: legacy}, // bản ghi cũ chưa có role
+		{UserID: left, Role: domain.GroupRoleAdmin}, // đã rời nhóm
+	}
+
+	roles := usecase.GroupRoles(memberIDs, members)
+
+	assert.Equal(t, map[string]domain.GroupRole{
+		owner.Hex():  domain.GroupRoleOwner,
+		admin.Hex():  domain.GroupRoleAdmin,
+		legacy.Hex(): domain.GroupRoleMember,
+	}, roles)
+}
+
+func TestGroupRoleRank(t *testing.T) {
+	assert.Greater(t, domain.GroupRoleOwner.Rank(), domain.GroupRoleAdmin.Rank())
+	assert.Greater(t, domain.GroupRoleAdmin.Rank(), domain.GroupRoleMember.Rank())
+	assert.Equal(t, domain.GroupRoleMember.Rank(), domain.GroupRole("").Rank())
+	assert.Equal(t, domain.GroupRoleMember, domain.GroupRole("superuser").Normalize())
+}
+
+func TestCheckInvite(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	active := domain.Group{InviteCode: "ABCD2345", ExpiresAt: now.Add(time.Hour)}
+
+	assert.NoError(t, usecase.CheckInvite(&active, now))
+
+	revoked := active
+	revoked.InviteCode = ""
+	assert.ErrorIs(t, usecase.CheckInvite(&revoked, now), domain.ErrInviteNotEnabled)
+
+	assert.ErrorIs(t, usecase.CheckInvite(&active, now.Add(time.Hour)), domain.ErrInviteExpired)
+
+	limited := active
+	limited.InviteMaxUses, limited.InviteUses = 3, 2
+	assert.NoError(t, usecase.CheckInvite(&limited, now))
+	limited.InviteUses = 3
+	assert.ErrorIs(t, usecase.CheckInvite(&limited, now), domain.ErrInviteExhausted)
+}
+
+type groupMemberRepo struct {
+	domain.MemberRepository
+	members []domain.Member
+}
+
+func (f *groupMemberRepo) ListByGroup(ctx context.Context, groupID primitive.ObjectID) ([]domain.Member, error) {
+	var out []domain.Member
+	for _, m := range f.members {
+		if m.GroupID == groupID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+type groupLocationRepo struct {
+	domain.LocationRepository
+	locs map[string]domain.Location
+}
+
+func (f groupLocationRepo) GetByUserIDs(ctx context.Context, userIDs []string) ([]domain.Location, error) {
+	var out []domain.Location
+	for _, id := range userIDs {
+		if l, ok := f.locs[id]; ok {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func memberLoc(id string, lat, lon float64, updatedAt int64) domain.Location {
+	return domain.Location{
+		ID: id, Status: "SAFE", UpdatedAt: updatedAt, AccuracyM: 10,
+		Location: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{lon, lat}},
+	}
+}
+
+func TestGroupListMembers(t *testing.T) {
+	f := newSharingFixture()
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	// carol chỉ chia sẻ cho family, làm mờ theo khu vực; bob mất tín hiệu
+	f.settings.byUser[f.carol.ID.Hex()] = &domain.SharingSettings{
+		UserID: f.carol.ID.Hex(), GroupIDs: []primitive.ObjectID{f.family}, Precision: domain.SharingArea,
+	}
+	bobLoc := memberLoc(f.bob.ID.Hex(), 10.78, 106.70, now-3600)
+	bobLoc.Status, bobLoc.LastStatus, bobLoc.StaleSince = domain.LocationUnknown, "DANGER", now-600
+	locs := groupLocationRepo{locs: map[string]domain.Location{
+		f.alice.ID.Hex(): memberLoc(f.alice.ID.Hex(), 10.77, 106.70, now),
+		f.bob.ID.Hex():   bobLoc,
+		f.carol.ID.Hex(): memberLoc(f.carol.ID.Hex(), 10.77654, 106.70123, now),
+	}}
+	uc := usecase.NewGroupUsecase(f.groups, &groupMemberRepo{}, f.users, locs, f.usecase(), usecase.InviteConfig{}, time.Second)
+
+	members, err := uc.ListMembers(ctx, f.alice.ID.Hex(), f.family.Hex())
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	byName := map[string]domain.GroupMemberDetail{}
+	for _, m := range members {
+		byName[m.Name] = m
+	}
+
+	bob := byName["bob"]
+	assert.True(t, bob.LocationShared)
+	assert.Equal(t, bobLoc.Location.Coordinates, bob.Location.Coordinates.Coordinates)
+	assert.True(t, bob.Location.Stale)
+	assert.Equal(t, "DANGER", bob.Location.LastStatus)
+	assert.Equal(t, now-600, bob.Location.StaleSince)
+	assert.GreaterOrEqual(t, bob.Location.AgeSec, int64(3600))
+	require.NotNil(t, bob.DistanceM)
+	assert.InDelta(t, 1112, *bob.DistanceM, 5)
+
+	carol := byName["carol"]
+	assert.True(t, carol.LocationShared)
+	assert.NotEqual(t, [2]float64{106.70123, 10.77654}, carol.Location.Coordinates.Coordinates)
+	assert.Greater(t, carol.Location.AccuracyM, 10.0)
+	assert.False(t, carol.Location.Stale)
+
+	// dave cùng nhóm work nhưng carol không chia sẻ cho work; dave chưa có vị trí nên không có khoảng cách
+	members, err = uc.ListMembers(ctx, f.dave.ID.Hex(), f.work.Hex())
+	require.NoError(t, err)
+	for _, m := range members {
+		assert.Nil(t, m.DistanceM, m.Name)
+		if m.Name == "carol" {
+			assert.False(t, m.LocationShared)
+			assert.Zero(t, m.Location)
+		}
+	}
+
+	// không phải thành viên
+	_, err = uc.ListMembers(ctx, f.dave.ID.Hex(), f.family.Hex())
+	assert.ErrorIs(t, err, domain.ErrNotGroupMember)
+}
+
+func TestSharingDecideMany(t *testing.T) {
+	f := newSharingFixture()
+	ctx := context.Background()
+	uc := f.usecase()
+
+	f.settings.byUser[f.alice.ID.Hex()] = &domain.SharingSettings{
+		UserID: f.alice.ID.Hex(), GroupIDs: []primitive.ObjectID{}, Precision: domain.SharingApprox,
+		PausedUntil: time.Now().Add(time.Hour).Unix(),
+	}
+	f.settings.byUser[f.carol.ID.Hex()] = &domain.SharingSettings{
+		UserID: f.carol.ID.Hex(), GroupIDs: []primitive.ObjectID{f.work}, Precision: domain.SharingApprox,
+	}
+
+	d, err := uc.DecideMany(ctx, f.bob, []domain.User{f.alice, f.bob, f.carol})
+	require.NoError(t, err)
+	assert.False(t, d[f.alice.ID.Hex()].Allowed) // tạm dừng
+	assert.Equal(t, domain.SharingDecision{Allowed: true, Precision: domain.SharingExact}, d[f.bob.ID.Hex()])
+	assert.False(t, d[f.carol.ID.Hex()].Allowed) // chỉ chia sẻ cho work
+
+	d, err = uc.DecideMany(ctx, f.dave, []domain.User{f.carol})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SharingDecision{Allowed: true, Precision: domain.SharingApprox}, d[f.carol.ID.Hex()])
+
+	// SOS bỏ qua tạm dừng
+	f.sos.active[f.alice.ID.Hex()] = true
+	d, err = uc.DecideMany(ctx, f.bob, []domain.User{f.alice})
+	require.NoError(t, err)
+	assert.Equal(t, domain.SharingDecision{Allowed: true, Precision: domain.SharingExact, SOS: true}, d[f.alice.ID.Hex()])
+}
+
+func (f *groupMemberRepo) SetRole(ctx context.Context, groupID, userID primitive.ObjectID, role domain.GroupRole) error {
+	for i, m := range f.members {
+		if m.GroupID == groupID && m.UserID == userID {
+			f.members[i].Role = role
+			return nil
+		}
+	}
+	f.members = append(f.members, domain.Member{GroupID: groupID, UserID: userID, Role: role})
+	return nil
+}
+
+func (f *groupMemberRepo) Remove(ctx context.Context, groupID, userID primitive.ObjectID) error {
+	var out []domain.Member
+	for _, m := range f.members {
+		if m.GroupID != groupID || m.UserID != userID {
+			out = append(out, m)
+		}
+	}
+	f.members = out
+	return nil
+}
+
+func (f *groupMemberRepo) DeleteByGroup(ctx context.Context, groupID primitive.ObjectID) error {
+	var out []domain.Member
+	for _, m := range f.members {
+		if m.GroupID != groupID {
+			out = append(out, m)
+		}
+	}
+	f.members = out
+	return nil
+}
+
+// family: alice owner, bob admin, carol admin; thêm dave làm member
+func newRoleFixture() (*sharingFixture, *groupMemberRepo, domain.GroupUsecase) {
+	f := newSharingFixture()
+	g := f.groups.groups[f.family]
+	g.MemberIDs = append(g.MemberIDs, f.dave.ID)
+	f.groups.groups[f.family] = g
+	f.dave.GroupIDs = append(f.dave.GroupIDs, f.family)
+	f.users.users[f.dave.ID.Hex()] = f.dave
+
+	members := &groupMemberRepo{members: []domain.Member{
+		{GroupID: f.family, UserID: f.alice.ID, Role: domain.GroupRoleOwner},
+		{GroupID: f.family, UserID: f.bob.ID, Role: domain.GroupRoleAdmin},
+		{GroupID: f.family, UserID: f.carol.ID, Role: domain.GroupRoleAdmin},
+		{GroupID: f.family, UserID: f.dave.ID, Role: domain.GroupRoleMember},
+	}}
+	uc := usecase.NewGroupUsecase(f.groups, members, f.users, groupLocationRepo{}, f.usecase(), usecase.InviteConfig{}, time.Second)
+	return f, members, uc
+}
+
+func TestGroupRemoveMemberPermissions(t *testing.T) {
+	f, _, uc := newRoleFixture()
+	ctx := context.Background()
+
+	// admin không kick được admin khác hay owner
+	assert.ErrorIs(t, uc.RemoveMember(ctx, f.bob.ID.Hex(), f.family, f.carol.ID.Hex()), domain.ErrGroupForbidden)
+	assert.ErrorIs(t, uc.RemoveMember(ctx, f.bob.ID.Hex(), f.family, f.alice.ID.Hex()), domain.ErrGroupForbidden)
+	// member không kick được ai
+	assert.ErrorIs(t, uc.RemoveMember(ctx, f.dave.ID.Hex(), f.family, f.bob.ID.Hex()), domain.ErrGroupForbidden)
+
+	// admin kick member: bỏ khỏi nhóm và khỏi user.groupIDs
+	require.NoError(t, uc.RemoveMember(ctx, f.bob.ID.Hex(), f.family, f.dave.ID.Hex()))
+	assert.NotContains(t, f.groups.groups[f.family].MemberIDs, f.dave.ID)
+	assert.NotContains(t, f.users.users[f.dave.ID.Hex()].GroupIDs, f.family)
+
+	// owner kick được admin
+	require.NoError(t, uc.RemoveMember(ctx, f.alice.ID.Hex(), f.family, f.carol.ID.Hex()))
+	assert.NotContains(t, f.groups.groups[f.family].MemberIDs, f.carol.ID)
+}
+
+func TestGroupOwnerLeaveAndTransfer(t *testing.T) {
+	f, members, uc := newRoleFixture()
+	ctx := context.Background()
+
+	// còn người khác thì owner phải chuyển quyền trước
+	assert.ErrorIs(t, uc.LeaveGroup(ctx, f.alice.ID.Hex(), f.family), domain.ErrOwnerMustTransfer)
+	// chỉ owner chuyển được
+	assert.ErrorIs(t, uc.TransferOwnership(ctx, f.bob.ID.Hex(), f.family, f.bob.ID.Hex()), domain.ErrGroupForbidden)
+
+	require.NoError(t, uc.TransferOwnership(ctx, f.alice.ID.Hex(), f.family, f.dave.ID.Hex()))
+	roles := usecase.GroupRoles(f.groups.groups[f.family].MemberIDs, members.members)
+	assert.Equal(t, domain.GroupRoleOwner, roles[f.dave.ID.Hex()])
+	assert.Equal(t, domain.GroupRoleAdmin, roles[f.alice.ID.Hex()])
+
+	// owner cũ giờ là admin, rời nhóm được
+	require.NoError(t, uc.LeaveGroup(ctx, f.alice.ID.Hex(), f.family))
+	assert.NotContains(t, f.groups.groups[f.family].MemberIDs, f.alice.ID)
+	assert.Contains(t, f.groups.groups, f.family)
+}
+
+func TestGroupLastMemberLeaveDeletesGroup(t *testing.T) {
+	f, members, uc := newRoleFixture()
+	ctx := context.Background()
+
+	for _, u := range []domain.User{f.bob, f.carol, f.dave} {
+		require.NoError(t, uc.LeaveGroup(ctx, u.ID.Hex(), f.family))
+	}
+	require.Equal(t, []primitive.ObjectID{f.alice.ID}, f.groups.groups[f.family].MemberIDs)
+
+	// owner là người cuối cùng: nhóm bị xóa cùng bản ghi member và groupID trong user
+	require.NoError(t, uc.LeaveGroup(ctx, f.alice.ID.Hex(), f.family))
+	assert.NotContains(t, f.groups.groups, f.family)
+	assert.Empty(t, members.members)
+	assert.NotContains(t, f.users.users[f.alice.ID.Hex()].GroupIDs, f.family)
+}
+
+func TestGroupRotateInviteRejectsNegativeSettings(t *testing.T) {
+	f, _, uc := newRoleFixture()
+	ctx := context.Background()
+
+	_, err := uc.RotateInvite(ctx, f.alice.ID.Hex(), f.family, -time.Hour, 0)
+	assert.ErrorIs(t, err, domain.ErrInviteSettings)
+	_, err = uc.RotateInvite(ctx, f.alice.ID.Hex(), f.family, 0, -1)
+	assert.ErrorIs(t, err, domain.ErrInviteSettings)
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+)
+
+const (
+	trackDefaultMinMoveM  = 25.0
+	trackDefaultHeartbeat = 15 * time.Minute
+	trackDefaultRetention = 30 * 24 * time.Hour
+	trackAccuracyCapM     = 100.0 // độ sai GPS được coi là "chưa di chuyển" tối đa chừng này
+	trackMaxPoints        = 5000
+)
+
+type TrackConfig struct {
+	Retention time.Duration // điểm cũ hơn bị TTL index xóa
+	MinMoveM  float64       // đi ít hơn chừng này (hoặc trong độ sai GPS) thì coi là đứng yên
+	Heartbeat time.Duration // đứng yên lâu hơn chừng này vẫn thêm điểm mới để trail có mốc thời gian
+}
+
+// TrackRecorder ghi lịch sử vị trí, gộp các fix đứng yên vào điểm trước đó
+type TrackRecorder struct {
+	repo domain.LocationHistoryRepository
+	cfg  TrackConfig
+}
+
+func NewTrackRecorder(repo domain.LocationHistoryRepository, cfg TrackConfig) *TrackRecorder {
+	if cfg.Retention <= 0 {
+		cfg.Retention = trackDefaultRetention
+	}
+	if cfg.MinMoveM <= 0 {
+		cfg.MinMoveM = trackDefaultMinMoveM
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = trackDefaultHeartbeat
+	}
+	return &TrackRecorder{repo: repo, cfg: cfg}
+}
+
+// Record ghi các fix của user (tăng dần theo thời gian). Fix cũ hơn cuối trail (gửi bù khi offline
+// trong lúc trail đã đi tiếp) được chèn thành điểm riêng nếu chưa có điểm nào phủ thời điểm đó,
+// nên gửi lại cùng một batch không tạo điểm trùng.
+func (r *TrackRecorder) Record(ctx context.Context, userID string, locs ...*domain.Location) error {
+	prev, err := r.repo.Last(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	touched := false
+	flush := func() error {
+		if !touched {
+			return nil
+		}
+		touched = false
+		return r.repo.Touch(ctx, prev.ID, prev.LastSeenAt, prev.ExpiresAt)
+	}
+
+	for _, loc := range locs {
+		if prev != nil && loc.UpdatedAt <= prev.LastSeenAt {
+			if err := r.backfill(ctx, userID, loc); err != nil {
+				return err
+			}
+			continue
+		}
+		if prev != nil && r.stationary(prev, loc) {
+			prev.LastSeenAt, prev.ExpiresAt = loc.UpdatedAt, r.expiry(loc.UpdatedAt)
+			touched = true
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+
+		p := r.point(userID, loc)
+		if err := r.repo.Append(ctx, p); err != nil {
+			return err
+		}
+		prev = p
+	}
+	return flush()
+}
+
+// backfill chèn fix cũ vào giữa trail, bỏ qua nếu đã nằm trong một điểm (đã ghi trước đó)
+func (r *TrackRecorder) backfill(ctx context.Context, userID string, loc *domain.Location) error {
+	covered, err := r.repo.Covers(ctx, userID, loc.UpdatedAt)
+	if err != nil || covered {
+		return err
+	}
+	return r.repo.Append(ctx, r.point(userID, loc))
+}
+
+func (r *TrackRecorder) point(userID string, loc *domain.Location) *domain.TrackPoint {
+	return &domain.TrackPoint{
+		UserID:     userID,
+		Location:   domain.GeoPoint{Type: "Point", Coordinates: loc.Location.Coordinates},
+		AccuracyM:  loc.AccuracyM,
+		Status:     loc.Status,
+		Timestamp:  loc.UpdatedAt,
+		LastSeenAt: loc.UpdatedAt,
+		ExpiresAt:  r.expiry(loc.UpdatedAt),
+	}
+}
+
+// stationary: cùng status, chưa quá heartbeat và chưa ra khỏi vòng sai số của điểm trước
+func (r *TrackRecorder) stationary(prev *domain.TrackPoint, loc *domain.Location) bool {
+	if prev.Status != loc.Status || loc.UpdatedAt-prev.Timestamp >= int64(r.cfg.Heartbeat/time.Second) {
+		return false
+	}
+	threshold := math.Max(r.cfg.MinMoveM, math.Min(math.Max(prev.AccuracyM, loc.AccuracyM), trackAccuracyCapM))
+	d := geo.DistanceMeters(prev.Location.Coordinates[1], prev.Location.Coordinates[0], loc.Location.Coordinates[1], loc.Location.Coordinates[0])
+	return d <= threshold
+}
+
+func (r *TrackRecorder) expiry(at int64) time.Time {
+	return time.Unix(at, 0).Add(r.cfg.Retention)
+}
+
+type trackUsecase struct {
+	repo     domain.LocationHistoryRepository
+	userRepo domain.UserRepository
+	sharing  domain.SharingUsecase
+	timeout  time.Duration
+}
+
+func NewTrackUsecase(repo domain.LocationHistoryRepository, userRepo domain.UserRepository, sharing domain.SharingUsecase, timeout time.Duration) domain.TrackUsecase {
+	return &trackUsecase{repo: repo, userRepo: userRepo, sharing: sharing, timeout: timeout}
+}
+
+func (u *trackUsecase) Track(ctx context.Context, viewerID, userID string, from, to time.Time) (*domain.Track, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	precision, err := u.canView(ctx, viewerID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	points, truncated, err := u.repo.Range(ctx, userID, from.Unix(), to.Unix(), trackMaxPoints)
+	if err != nil {
+		return nil, err
+	}
+	for i := range points {
+		points[i].Location.Coordinates, points[i].AccuracyM = CoarsenPoint(points[i].Location.Coordinates, points[i].AccuracyM, precision)
+	}
+	return &domain.Track{UserID: userID, From: from.Unix(), To: to.Unix(), Points: points, Truncated: truncated}, nil
+}
+
+// canView: chính user và coordinator / admin (tìm người mất tích) xem chính xác,
+// người cùng nhóm xem theo cài đặt chia sẻ của user
+func (u *trackUsecase) canView(ctx context.Context, viewerID, userID string) (string, error) {
+	if viewerID == userID {
+		return domain.SharingExact, nil
+	}
+	viewer, err := u.userRepo.GetByID(ctx, viewerID)
+	if err != nil {
+		return "", domain.ErrTrackForbidden
+	}
+	if viewer.Role == domain.RoleCoordinator || viewer.Role == domain.RoleAdmin {
+		return domain.SharingExact, nil
+	}
+	d, err := u.sharing.Decide(ctx, viewerID, userID)
+	if err != nil || !d.Allowed {
+		return "", domain.ErrTrackForbidden
+	}
+	return d.Precision, nil
+}
@@ -0,0 +1,135 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeHistoryRepo struct {
+	points []domain.TrackPoint
+}
+
+func (f *fakeHistoryRepo) Append(ctx context.Context, p *domain.TrackPoint) error {
+	for _, q := range f.points {
+		if q.UserID == p.UserID && q.Timestamp == p.Timestamp {
+			return nil
+		}
+	}
+	p.ID = primitive.NewObjectID()
+	f.points = append(f.points, *p)
+	return nil
+}
+
+func (f *fakeHistoryRepo) Touch(ctx context.Context, id primitive.ObjectID, seenAt int64, expiresAt time.Time) error {
+	for i := range f.points {
+		if f.points[i].ID == id {
+			f.points[i].LastSeenAt, f.points[i].ExpiresAt = seenAt, expiresAt
+		}
+	}
+	return nil
+}
+
+func (f *fakeHistoryRepo) Last(ctx context.Context, userID string) (*domain.TrackPoint, error) {
+	var last *domain.TrackPoint
+	for i := range f.points {
+		if last == nil || f.points[i].Timestamp > last.Timestamp {
+			p := f.points[i]
+			last = &p
+		}
+	}
+	return last, nil
+}
+
+func (f *fakeHistoryRepo) Covers(ctx context.Context, userID string, at int64) (bool, error) {
+	for _, p := range f.points {
+		if p.Timestamp <= at && at <= p.LastSeenAt {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeHistoryRepo) Range(ctx context.Context, userID string, from, to int64, limit int) ([]domain.TrackPoint, bool, error) {
+	return f.points, false, nil
+}
+
+func fix(lat, lon, acc float64, status string, at int64) *domain.Location {
+	return &domain.Location{
+		AccuracyM: acc,
+		Status:    status,
+		UpdatedAt: at,
+		Location:  domain.GeoPoint{Type: "Point", Coordinates: [2]float64{lon, lat}},
+	}
+}
+
+func TestTrackRecorder(t *testing.T) {
+	ctx := context.Background()
+	cfg := usecase.TrackConfig{MinMoveM: 25, Heartbeat: 15 * time.Minute}
+
+	t.Run("stationary fixes extend the last point", func(t *testing.T) {
+		repo := &fakeHistoryRepo{}
+		rec := usecase.NewTrackRecorder(repo, cfg)
+
+		assert.NoError(t, rec.Record(ctx, "u1", fix(10, 106, 10, "SAFE", 1000)))
+		assert.NoError(t, rec.Record(ctx, "u1", fix(10.0001, 106, 10, "SAFE", 1060))) // ~11m
+		assert.NoError(t, rec.Record(ctx, "u1", fix(10.0001, 106, 10, "SAFE", 1120)))
+
+		assert.Len(t, repo.points, 1)
+		assert.Equal(t, int64(1000), repo.points[0].Timestamp)
+		assert.Equal(t, int64(1120), repo.points[0].LastSeenAt)
+	})
+
+	t.Run("movement, status change and heartbeat add points", func(t *testing.T) {
+		repo := &fakeHistoryRepo{}
+		rec := usecase.NewTrackRecorder(repo, cfg)
+
+		assert.NoError(t, rec.Record(ctx, "u1",
+			fix(10, 106, 10, "SAFE", 1000),
+			fix(10.001, 106, 10, "SAFE", 1060),   // ~110m
+			fix(10.001, 106, 10, "DANGER", 1120), // đổi status
+			fix(10.001, 106, 10, "DANGER", 1120+16*60),
+		))
+		assert.Len(t, repo.points, 4)
+	})
+
+	t.Run("poor accuracy widens the stationary radius", func(t *testing.T) {
+		repo := &fakeHistoryRepo{}
+		rec := usecase.NewTrackRecorder(repo, cfg)
+
+		assert.NoError(t, rec.Record(ctx, "u1", fix(10, 106, 80, "SAFE", 1000), fix(10.0005, 106, 80, "SAFE", 1060))) // ~55m
+		assert.Len(t, repo.points, 1)
+	})
+
+	t.Run("resent fixes are ignored", func(t *testing.T) {
+		repo := &fakeHistoryRepo{}
+		rec := usecase.NewTrackRecorder(repo, cfg)
+
+		batch := []*domain.Location{fix(10, 106, 10, "SAFE", 1000), fix(10.001, 106, 10, "SAFE", 1060)}
+		assert.NoError(t, rec.Record(ctx, "u1", batch...))
+		assert.NoError(t, rec.Record(ctx, "u1", batch...))
+		assert.Len(t, repo.points, 2)
+	})
+
+	t.Run("offline fixes older than the trail end are backfilled once", func(t *testing.T) {
+		repo := &fakeHistoryRepo{}
+		rec := usecase.NewTrackRecorder(repo, cfg)
+
+		// trail đã đi tiếp qua fix live
+		assert.NoError(t, rec.Record(ctx, "u1", fix(10, 106, 10, "SAFE", 1000), fix(10.01, 106, 10, "SAFE", 2000)))
+
+		// batch offline của khoảng 1000..2000 tới sau
+		batch := []*domain.Location{fix(10.003, 106, 10, "SAFE", 1300), fix(10.006, 106, 10, "SAFE", 1600)}
+		assert.NoError(t, rec.Record(ctx, "u1", batch...))
+		assert.Len(t, repo.points, 4)
+
+		// gửi lại không thêm điểm
+		assert.NoError(t, rec.Record(ctx, "u1", batch...))
+		assert.Len(t, repo.points, 4)
+	})
+}
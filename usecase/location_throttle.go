@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+)
+
+const (
+	throttleDefaultWindow    = 5 * time.Second
+	throttleDefaultHeartbeat = 5 * time.Minute
+	throttleDefaultMinMoveM  = 10.0
+)
+
+type ThrottleConfig struct {
+	Window    time.Duration // mỗi user ghi tối đa một fix trong khoảng này, fix mới nhất thắng
+	Heartbeat time.Duration // đứng yên vẫn ghi lại sau khoảng này để vị trí không bị coi là cũ
+	MinMoveM  float64       // ngưỡng di chuyển tối thiểu khi fix có độ sai nhỏ hơn
+}
+
+// ThrottleAction: việc cần làm với một fix
+type ThrottleAction int
+
+const (
+	ThrottlePersist  ThrottleAction = iota // ghi ngay
+	ThrottleSkip                           // bỏ: đứng yên, status không đổi
+	ThrottleSchedule                       // giữ lại, ghi sau Delay (nếu không có fix mới hơn thay thế)
+	ThrottleQueued                         // đã thay fix đang chờ, lịch ghi đã có
+)
+
+type ThrottleDecision struct {
+	Action ThrottleAction
+	Delay  time.Duration
+}
+
+// LocationThrottle gộp fix location theo từng user (trong bộ nhớ)
+type LocationThrottle struct {
+	cfg    ThrottleConfig
+	mu     sync.Mutex
+	states map[string]*throttleState
+	swept  time.Time
+}
+
+type throttleState struct {
+	last      *domain.Location // fix ghi gần nhất
+	lastAt    time.Time
+	pending   *domain.Location
+	scheduled bool
+}
+
+func NewLocationThrottle(cfg ThrottleConfig) *LocationThrottle {
+	if cfg.Window <= 0 {
+		cfg.Window = throttleDefaultWindow
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = throttleDefaultHeartbeat
+	}
+	if cfg.MinMoveM <= 0 {
+		cfg.MinMoveM = throttleDefaultMinMoveM
+	}
+	return &LocationThrottle{cfg: cfg, states: map[string]*throttleState{}}
+}
+
+// Offer quyết định fix mới của user được ghi ngay, bỏ qua hay chờ gộp
+func (t *LocationThrottle) Offer(userID string, loc *domain.Location, now time.Time) ThrottleDecision {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.swept) > t.cfg.Heartbeat {
+		t.sweepLocked(now.Add(-2 * t.cfg.Heartbeat))
+		t.swept = now
+	}
+
+	st := t.states[userID]
+	if st == nil {
+		st = &throttleState{}
+		t.states[userID] = st
+	}
+	persist := func() ThrottleDecision {
+		st.last, st.lastAt, st.pending = loc, now, nil
+		return ThrottleDecision{Action: ThrottlePersist}
+	}
+
+	if st.last == nil {
+		return persist()
+	}
+	// chuyển sang DANGER: không chờ
+	if loc.Status == "DANGER" && st.last.Status != "DANGER" {
+		return persist()
+	}
+
+	statusChanged := loc.Status != st.last.Status
+	if !statusChanged && !t.moved(st.last, loc) && now.Sub(st.lastAt) < t.cfg.Heartbeat {
+		return ThrottleDecision{Action: ThrottleSkip}
+	}
+
+	if st.scheduled {
+		st.pending = loc
+		return ThrottleDecision{Action: ThrottleQueued}
+	}
+	if elapsed := now.Sub(st.lastAt); elapsed < t.cfg.Window {
+		st.pending, st.scheduled = loc, true
+		return ThrottleDecision{Action: ThrottleSchedule, Delay: t.cfg.Window - elapsed}
+	}
+	return persist()
+}
+
+// Take lấy fix đang chờ khi tới lịch ghi (nil nếu đã bị fix DANGER ghi thay)
+func (t *LocationThrottle) Take(userID string, now time.Time) *domain.Location {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st := t.states[userID]
+	if st == nil {
+		return nil
+	}
+	st.scheduled = false
+	loc := st.pending
+	if loc != nil {
+		st.last, st.lastAt, st.pending = loc, now, nil
+	}
+	return loc
+}
+
+// sweepLocked xóa trạng thái của user không còn gửi location
+func (t *LocationThrottle) sweepLocked(idleSince time.Time) {
+	for id, st := range t.states {
+		if !st.scheduled && st.lastAt.Before(idleSince) {
+			delete(t.states, id)
+		}
+	}
+}
+
+// moved: đi xa hơn bán kính sai số của fix mới (tối thiểu MinMoveM)
+func (t *LocationThrottle) moved(prev, loc *domain.Location) bool {
+	d := geo.DistanceMeters(prev.Location.Coordinates[1], prev.Location.Coordinates[0], loc.Location.Coordinates[1], loc.Location.Coordinates[0])
+	return d > math.Max(loc.AccuracyM, t.cfg.MinMoveM)
+}
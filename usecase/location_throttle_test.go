@@ -0,0 +1,48 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocationThrottle(t *testing.T) {
+	cfg := usecase.ThrottleConfig{Window: 5 * time.Second, Heartbeat: 5 * time.Minute, MinMoveM: 10}
+	t0 := time.Unix(1700000000, 0)
+
+	t.Run("stationary fixes are skipped until heartbeat", func(t *testing.T) {
+		th := usecase.NewLocationThrottle(cfg)
+
+		assert.Equal(t, usecase.ThrottlePersist, th.Offer("u1", fix(10, 106, 20, "SAFE", 0), t0).Action)
+		assert.Equal(t, usecase.ThrottleSkip, th.Offer("u1", fix(10.0001, 106, 20, "SAFE", 0), t0.Add(30*time.Second)).Action) // ~11m < 20m
+		assert.Equal(t, usecase.ThrottlePersist, th.Offer("u1", fix(10.0001, 106, 20, "SAFE", 0), t0.Add(6*time.Minute)).Action)
+	})
+
+	t.Run("latest fix wins inside the window", func(t *testing.T) {
+		th := usecase.NewLocationThrottle(cfg)
+		th.Offer("u1", fix(10, 106, 5, "SAFE", 0), t0)
+
+		d := th.Offer("u1", fix(10.001, 106, 5, "SAFE", 0), t0.Add(time.Second))
+		assert.Equal(t, usecase.ThrottleSchedule, d.Action)
+		assert.Equal(t, 4*time.Second, d.Delay)
+
+		assert.Equal(t, usecase.ThrottleQueued, th.Offer("u1", fix(10.002, 106, 5, "CAUTION", 0), t0.Add(2*time.Second)).Action)
+
+		latest := th.Take("u1", t0.Add(5*time.Second))
+		if assert.NotNil(t, latest) {
+			assert.Equal(t, "CAUTION", latest.Status)
+		}
+		assert.Nil(t, th.Take("u1", t0.Add(5*time.Second)))
+	})
+
+	t.Run("DANGER goes through immediately and replaces the pending fix", func(t *testing.T) {
+		th := usecase.NewLocationThrottle(cfg)
+		th.Offer("u1", fix(10, 106, 5, "SAFE", 0), t0)
+		th.Offer("u1", fix(10.001, 106, 5, "SAFE", 0), t0.Add(time.Second))
+
+		assert.Equal(t, usecase.ThrottlePersist, th.Offer("u1", fix(10.001, 106, 5, "DANGER", 0), t0.Add(2*time.Second)).Action)
+		assert.Nil(t, th.Take("u1", t0.Add(5*time.Second)))
+	})
+}
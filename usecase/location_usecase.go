@@ -3,6 +3,8 @@ package usecase
 import (
 	"context"
 	"errors"
+	"log"
+	"sort"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
@@ -18,18 +20,25 @@ var allowedStatus = map[string]bool{
 }
 
 type LocationUseCase struct {
-	queue   *worker.PriorityQueue
-	ws      *ws.WSManager
-	repo    domain.LocationRepository
-	timeout time.Duration
+	queue    *worker.PriorityQueue
+	ws       *ws.WSManager
+	repo     domain.LocationRepository
+	geofence *Geofence         // nil = không xét vào / ra vùng nguy hiểm
+	track    *TrackRecorder    // nil = không lưu lịch sử di chuyển
+	throttle *LocationThrottle // nil = ghi mọi fix
+	timeout  time.Duration
 }
 
-func NewLocationUC(q *worker.PriorityQueue, wsm *ws.WSManager, repo domain.LocationRepository, timeout time.Duration) *LocationUseCase {
+func NewLocationUC(q *worker.PriorityQueue, wsm *ws.WSManager, repo domain.LocationRepository, geofence *Geofence, track *TrackRecorder,
+	throttle *LocationThrottle, timeout time.Duration) *LocationUseCase {
 	return &LocationUseCase{
-		queue:   q,
-		ws:      wsm,
-		repo:    repo,
-		timeout: timeout,
+		queue:    q,
+		ws:       wsm,
+		repo:     repo,
+		geofence: geofence,
+		track:    track,
+		throttle: throttle,
+		timeout:  timeout,
 	}
 }
 
@@ -39,9 +48,31 @@ func (uc *LocationUseCase) Handle(userID string, loc *domain.Location) error {
 	}
 
 	loc.ID = userID // _id = userID
-	loc.UpdatedAt = time.Now().Unix()
+	now := time.Now()
+	loc.UpdatedAt = now.Unix()
+
+	if uc.throttle == nil {
+		uc.enqueue(userID, loc)
+		return nil
+	}
+
+	// gộp fix: đứng yên thì bỏ, trong cửa sổ thì chỉ fix mới nhất được ghi, DANGER ghi ngay
+	d := uc.throttle.Offer(userID, loc, now)
+	switch d.Action {
+	case ThrottlePersist:
+		uc.enqueue(userID, loc)
+	case ThrottleSchedule:
+		time.AfterFunc(d.Delay, func() {
+			if latest := uc.throttle.Take(userID, time.Now()); latest != nil {
+				uc.enqueue(userID, latest)
+			}
+		})
+	}
+	return nil
+}
 
-	// push job vào worker queue
+// enqueue đưa việc lưu + broadcast location vào worker queue
+func (uc *LocationUseCase) enqueue(userID string, loc *domain.Location) {
 	uc.queue.Push(worker.Job{
 		Priority: 1, // hoặc 0 tùy bạn muốn
 		Exec: func() {
@@ -49,16 +80,134 @@ func (uc *LocationUseCase) Handle(userID string, loc *domain.Location) error {
 			defer cancel()
 
 			// Lưu location
-			_ = uc.repo.Upsert(ctx, loc)
+			if err := uc.persist(ctx, userID, loc); err != nil {
+				return
+			}
 
 			// Broadcast location
 			uc.ws.BroadcastLocation(userID, loc)
+
+			// cảnh báo khi đi vào / ra zone nguy hiểm, vùng alert
+			if uc.geofence != nil {
+				uc.geofence.Enqueue(userID, loc)
+			}
 		},
 	})
+}
 
+// Save lưu location gửi qua REST ngay (không qua queue, không gộp fix) và xét geofence;
+// broadcast do người gọi tự làm qua realtime.Broadcaster
+func (uc *LocationUseCase) Save(ctx context.Context, userID string, loc *domain.Location) error {
+	if !allowedStatus[loc.Status] {
+		return errors.New("invalid status")
+	}
+	loc.ID = userID
+	loc.UpdatedAt = time.Now().Unix()
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+	if err := uc.persist(ctx, userID, loc); err != nil {
+		return err
+	}
+	if uc.geofence != nil {
+		uc.geofence.Enqueue(userID, loc)
+	}
 	return nil
 }
 
+// persist ghi location hiện tại và thêm vào trail
+func (uc *LocationUseCase) persist(ctx context.Context, userID string, loc *domain.Location) error {
+	if err := uc.repo.Upsert(ctx, loc); err != nil {
+		return err
+	}
+	if uc.track != nil {
+		if err := uc.track.Record(ctx, userID, loc); err != nil {
+			log.Println("location history: record failed:", err)
+		}
+	}
+	return nil
+}
+
+// SubmitBatch nhận các điểm location gửi bù khi offline.
+// Chỉ điểm mới nhất được ghi (và chỉ khi mới hơn bản đang lưu), các điểm cũ hơn trong batch là "superseded".
+func (uc *LocationUseCase) SubmitBatch(ctx context.Context, userID string, items []domain.BatchLocationItem) []domain.BatchItemResult {
+	results := make([]domain.BatchItemResult, len(items))
+	newest := -1
+	var valid []int
+
+	for i, it := range items {
+		results[i].Key = it.Key
+		err := validateBatchItem(it.Key, it.Lat, it.Lon)
+		switch {
+		case err != nil:
+		case !allowedStatus[it.Status]:
+			err = errors.New("invalid status")
+		case it.Timestamp <= 0:
+			err = errors.New("missing timestamp")
+		}
+		if err != nil {
+			results[i].Status = domain.BatchItemInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+
+		results[i].Status = domain.BatchItemSuperseded
+		valid = append(valid, i)
+		if newest < 0 || it.Timestamp > items[newest].Timestamp {
+			newest = i
+		}
+	}
+	if newest < 0 {
+		return results
+	}
+
+	loc := batchLocation(userID, items[newest])
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	// mọi điểm hợp lệ đều vào trail (vị trí hiện tại chỉ lấy điểm mới nhất)
+	if uc.track != nil {
+		sort.SliceStable(valid, func(a, b int) bool { return items[valid[a]].Timestamp < items[valid[b]].Timestamp })
+		locs := make([]*domain.Location, len(valid))
+		for k, i := range valid {
+			locs[k] = batchLocation(userID, items[i])
+		}
+		if err := uc.track.Record(ctx, userID, locs...); err != nil {
+			log.Println("location history: record batch failed:", err)
+		}
+	}
+
+	applied, err := uc.repo.UpsertIfNewer(ctx, loc)
+	switch {
+	case err != nil:
+		results[newest].Status = domain.BatchItemFailed
+		results[newest].Error = err.Error()
+	case !applied:
+		results[newest].Status = domain.BatchItemDuplicate
+	default:
+		results[newest].Status = domain.BatchItemCreated
+		uc.ws.BroadcastLocation(userID, loc)
+		if uc.geofence != nil {
+			uc.geofence.Enqueue(userID, loc)
+		}
+	}
+	return results
+}
+
+func batchLocation(userID string, it domain.BatchLocationItem) *domain.Location {
+	return &domain.Location{
+		ID:        userID,
+		AccuracyM: it.AccuracyM,
+		Status:    it.Status,
+		UpdatedAt: it.Timestamp / 1000, // ms -> s
+		Location: domain.GeoPoint{
+			Type:        "Point",
+			Coordinates: [2]float64{it.Lon, it.Lat},
+		},
+	}
+}
+
 // Lấy tất cả userID trong bán kính km
 func (uc *LocationUseCase) GetNearbyUserIDs(ctx context.Context, lat, lon, km float64) ([]string, error) {
 	return uc.repo.GetNearbyUserIDs(ctx, lat, lon, km)
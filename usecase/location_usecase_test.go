@@ -0,0 +1,79 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeLocationRepo giữ location mới nhất trong bộ nhớ
+type fakeLocationRepo struct {
+	stored map[string]domain.Location
+}
+
+func (f *fakeLocationRepo) Upsert(ctx context.Context, loc *domain.Location) error {
+	f.stored[loc.ID] = *loc
+	return nil
+}
+
+func (f *fakeLocationRepo) UpsertIfNewer(ctx context.Context, loc *domain.Location) (bool, error) {
+	if cur, ok := f.stored[loc.ID]; ok && cur.UpdatedAt >= loc.UpdatedAt {
+		return false, nil
+	}
+	f.stored[loc.ID] = *loc
+	return true, nil
+}
+
+func (f *fakeLocationRepo) GetByUserID(ctx context.Context, userID string) (*domain.Location, error) {
+	loc := f.stored[userID]
+	return &loc, nil
+}
+
+func (f *fakeLocationRepo) GetByUserIDs(ctx context.Context, userIDs []string) ([]domain.Location, error) {
+	return nil, nil
+}
+
+func (f *fakeLocationRepo) GetNearbyUserIDs(ctx context.Context, lat, lon, km float64) ([]string, error) {
+	return nil, nil
+}
+
+func (f *fakeLocationRepo) FetchSilent(ctx context.Context, q domain.SilentQuery, limit int) ([]domain.Location, error) {
+	return nil, nil
+}
+
+func (f *fakeLocationRepo) MarkStale(ctx context.Context, userID string, updatedAt, staleSince int64) (bool, error) {
+	return false, nil
+}
+
+func TestLocationSubmitBatch(t *testing.T) {
+	repo := &fakeLocationRepo{stored: map[string]domain.Location{}}
+	uc := usecase.NewLocationUC(nil, ws.NewWSManager(), repo, nil, nil, nil, time.Second)
+
+	items := []domain.BatchLocationItem{
+		{Key: "a", Lat: 10.1, Lon: 106.1, Status: "SAFE", Timestamp: 1700000000000},
+		{Key: "b", Lat: 10.2, Lon: 106.2, Status: "CAUTION", Timestamp: 1700000060000},
+		{Key: "c", Lat: 10.3, Lon: 106.3, Status: "PANIC", Timestamp: 1700000120000},
+	}
+
+	t.Run("newest valid point wins", func(t *testing.T) {
+		res := uc.SubmitBatch(context.Background(), "u1", items)
+
+		assert.Equal(t, domain.BatchItemSuperseded, res[0].Status)
+		assert.Equal(t, domain.BatchItemCreated, res[1].Status)
+		assert.Equal(t, domain.BatchItemInvalid, res[2].Status)
+		assert.Equal(t, "CAUTION", repo.stored["u1"].Status)
+		assert.Equal(t, int64(1700000060), repo.stored["u1"].UpdatedAt)
+	})
+
+	t.Run("retry is a duplicate", func(t *testing.T) {
+		res := uc.SubmitBatch(context.Background(), "u1", items)
+
+		assert.Equal(t, domain.BatchItemDuplicate, res[1].Status)
+		assert.Equal(t, "CAUTION", repo.stored["u1"].Status)
+	})
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"log"
+	"math"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain" // generated by oapi-codegen
@@ -17,13 +18,24 @@ type ReportUseCase struct {
 	aiQueue *worker.AIQueue
 	ws      *ws.WSManager
 	repo    domain.ReportRepository
+	jobs    domain.EnrichmentJobRepository
+	retry   EnrichmentRetryConfig
 	timeout time.Duration
 	AI      *ai.Client
 
 	zoneUC domain.ZoneUsecase
 }
 
-func NewReportUC(q *worker.PriorityQueue, aiq *worker.AIQueue, wsm *ws.WSManager, repo domain.ReportRepository, zoneUC domain.ZoneUsecase, timeout time.Duration) *ReportUseCase {
+// EnrichmentRetryConfig cấu hình retry khi AI service lỗi
+type EnrichmentRetryConfig struct {
+	MaxAttempts int           // số lần gọi AI tối đa trước khi đánh dấu FAILED
+	BaseDelay   time.Duration // thời gian chờ lần retry đầu, nhân đôi sau mỗi lần
+	MaxDelay    time.Duration
+	Lease       time.Duration // thời gian giữ job khi đang xử lý
+	BatchSize   int           // số job tối đa lấy mỗi lần poll
+}
+
+func NewReportUC(q *worker.PriorityQueue, aiq *worker.AIQueue, wsm *ws.WSManager, repo domain.ReportRepository, jobs domain.EnrichmentJobRepository, zoneUC domain.ZoneUsecase, retry EnrichmentRetryConfig, timeout time.Duration) *ReportUseCase {
 	aiClient, err := ai.New()
 	if err != nil {
 		log.Fatal("Failed to create ReportUseCase:", err)
@@ -34,6 +46,8 @@ func NewReportUC(q *worker.PriorityQueue, aiq *worker.AIQueue, wsm *ws.WSManager
 		aiQueue: aiq,
 		ws:      wsm,
 		repo:    repo,
+		jobs:    jobs,
+		retry:   retry,
 		timeout: timeout,
 		AI:      aiClient,
 		zoneUC:  zoneUC,
@@ -157,8 +171,11 @@ func (uc *ReportUseCase) Handle(client *ws.Client, r *domain.Report) error {
 	if r.ID.IsZero() {
 		r.ID = primitive.NewObjectID()
 	}
+	r.EnrichmentState = domain.EnrichmentPending
+	r.Status = domain.ReportStatusOpen
 
-	// STEP 1 — Save report
+	// STEP 1 — Save report + job, sau đó mới đẩy sang AI queue
+	// để UpdateEnrichment không bao giờ chạy trước Create
 	uc.queue.Push(worker.Job{
 		Priority: 2,
 		Exec: func() {
@@ -172,82 +189,544 @@ func (uc *ReportUseCase) Handle(client *ws.Client, r *domain.Report) error {
 				})
 				return
 			}
+
+			// STEP 2 — AI analyze
+			uc.startEnrichment(ctx, r.ID, client)
 		},
 	})
 
-	// STEP 2 — AI analyze
+	return nil
+}
+
+// startEnrichment tạo job rồi đẩy sang AI queue.
+// Job được giữ trong khoảng lease, nếu server chết giữa chừng worker sẽ retry.
+// Không có AI queue (REST route) thì để EnrichmentWorker lấy job ngay lần poll tới.
+func (uc *ReportUseCase) startEnrichment(ctx context.Context, id primitive.ObjectID, client *ws.Client) {
+	runAt := time.Now()
+	if uc.aiQueue != nil {
+		runAt = runAt.Add(uc.retry.Lease)
+	}
+	job := domain.EnrichmentJob{ID: id, Status: domain.JobStatusQueued, Claim: primitive.NewObjectID()}
+	if err := uc.jobs.Enqueue(ctx, id, runAt, job.Claim); err != nil {
+		log.Println("Failed to enqueue enrichment job:", err)
+	}
+
+	if uc.aiQueue == nil {
+		return
+	}
 	uc.aiQueue.Push(func() {
-		ctx, cancel := context.WithTimeout(context.Background(), uc.timeout)
-		defer cancel()
+		uc.runEnrichment(job, client)
+	})
+}
+
+// SubmitBatch lưu các report gửi bù khi offline.
+// client_key ghép từ userID + key nên gửi lại cùng key chỉ nhận "duplicate".
+func (uc *ReportUseCase) SubmitBatch(ctx context.Context, userID string, items []domain.BatchReportItem) []domain.BatchItemResult {
+	results := make([]domain.BatchItemResult, 0, len(items))
+	for _, it := range items {
+		results = append(results, uc.submitBatchItem(ctx, userID, it))
+	}
+	return results
+}
 
-		inputText := r.Type + " " + r.Detail + " " + r.Description
+func (uc *ReportUseCase) submitBatchItem(ctx context.Context, userID string, it domain.BatchReportItem) domain.BatchItemResult {
+	res := domain.BatchItemResult{Key: it.Key}
+	if err := validateBatchItem(it.Key, it.Lat, it.Lon); err != nil {
+		res.Status = domain.BatchItemInvalid
+		res.Error = err.Error()
+		return res
+	}
 
-		urgency, category, confidence, err := uc.AI.ClassifyHazardText(ctx, inputText)
-		if err != nil {
-			// Fallback như default
-			urgency = "MEDIUM"
-			category = "OTHER"
-			confidence = 0.0
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	r := &domain.Report{
+		ClientKey:   clientReportKey(userID, it.Key),
+		UserID:      userID,
+		Type:        it.Type,
+		Detail:      it.Detail,
+		Description: it.Description,
+		Image:       it.Image,
+		Timestamp:   it.Timestamp, // giữ thời điểm tạo trên máy
+		Location: domain.GeoPoint{
+			Type:        "Point",
+			Coordinates: [2]float64{it.Lon, it.Lat},
+		},
+		UserName:        it.UserName,
+		PhoneNumber:     it.PhoneNumber,
+		Status:          domain.ReportStatusOpen,
+		EnrichmentState: domain.EnrichmentPending,
+	}
+	created, err := uc.repo.CreateOnce(ctx, r)
+	if err != nil {
+		res.Status = domain.BatchItemFailed
+		res.Error = err.Error()
+		return res
+	}
+	res.ID = r.ID.Hex()
+	if !created {
+		res.Status = domain.BatchItemDuplicate
+		return res
+	}
+
+	uc.startEnrichment(ctx, r.ID, nil)
+	res.Status = domain.BatchItemCreated
+	return res
+}
+
+// ProcessDueJobs lấy các job retry đến hạn và đẩy sang AI queue
+func (uc *ReportUseCase) ProcessDueJobs(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	jobs, err := uc.jobs.ClaimDue(ctx, time.Now(), uc.retry.Lease, uc.retry.BatchSize)
+	for _, job := range jobs {
+		job := job
+		uc.aiQueue.Push(func() {
+			uc.runEnrichment(job, nil)
+		})
+	}
+	return len(jobs), err
+}
+
+// RequeuePending tạo job cho các report chưa có kết quả AI thật (dùng khi khởi động lại)
+func (uc *ReportUseCase) RequeuePending(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	ids, err := uc.repo.FetchUnenrichedIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		if err := uc.jobs.Enqueue(ctx, id, now, primitive.NewObjectID()); err != nil {
+			return 0, err
 		}
+	}
+	return len(ids), nil
+}
+
+// runEnrichment gọi AI cho một report.
+// client != nil là lần chạy đầu ngay sau khi gửi report, kết quả trả về qua "report_created".
+// Các lần retry sau đó báo cho user qua "report_enriched".
+func (uc *ReportUseCase) runEnrichment(job domain.EnrichmentJob, client *ws.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), uc.timeout)
+	defer cancel()
+
+	// job có thể đã nằm trong AI queue quá lease và bị ClaimDue lấy lại -> để lần lấy mới chạy
+	owned, err := uc.jobs.Renew(ctx, job, time.Now().Add(uc.retry.Lease))
+	if err != nil {
+		log.Println("Failed to renew enrichment job:", err)
+		return
+	}
+	if !owned {
+		return
+	}
+
+	r, err := uc.repo.GetByID(ctx, job.ID)
+	if err != nil {
+		// job vẫn đang QUEUED, hết lease sẽ được lấy lại
+		log.Println("Failed to load report for enrichment:", err)
+		return
+	}
 
-		// Build enrichment
-		r.Enrichment = &domain.ReportEnrichment{
-			Category:    category,
-			Urgency:     urgency,
-			Summary:     r.Detail,
-			Confidence:  int(confidence * 100),
-			ExtractedAt: time.Now().Unix(),
+	attempts := job.Attempts + 1
+	inputText := r.Type + " " + r.Detail + " " + r.Description
+
+	urgency, category, confidence, aiErr := uc.AI.ClassifyHazardText(ctx, inputText)
+
+	state := domain.EnrichmentDone
+	if aiErr != nil {
+		// Fallback như default
+		urgency = "MEDIUM"
+		category = "OTHER"
+		confidence = 0.0
+
+		state = domain.EnrichmentFallback
+		if attempts >= uc.retry.MaxAttempts {
+			state = domain.EnrichmentFailed
 		}
+	}
 
-		// UpdateAI
-		if repoWithUpdate, ok := uc.repo.(interface {
-			UpdateAI(ctx context.Context, reportID string, enrichment *domain.ReportEnrichment) error
-		}); ok {
-			if err := repoWithUpdate.UpdateAI(ctx, r.ID.Hex(), r.Enrichment); err != nil {
-				uc.ws.SendToClient(client, "report_created", map[string]interface{}{
-					"ok":    false,
-					"error": "Failed to update AI enrichment: " + err.Error(),
-				})
-				return
+	// fallback chỉ ghi lần đầu, các lần lỗi sau giữ nguyên giá trị cũ
+	firstResult := r.Enrichment == nil
+	if aiErr == nil || firstResult || state != r.EnrichmentState {
+		enrichment := r.Enrichment
+		if aiErr == nil || enrichment == nil {
+			enrichment = &domain.ReportEnrichment{
+				Category:    category,
+				Urgency:     urgency,
+				Summary:     r.Detail,
+				Confidence:  int(confidence * 100),
+				ExtractedAt: time.Now().Unix(),
 			}
 		}
 
-		// STEP 3 — Update danger zone
-		riskIncrement := convertUrgencyToRisk(urgency)
-
-		lat := r.Location.Coordinates[1]
-		lon := r.Location.Coordinates[0]
+		if err := uc.repo.UpdateEnrichment(ctx, r.ID, enrichment, state); err != nil {
+			uc.notifyEnrichmentError(client, r, "Failed to update AI enrichment: "+err.Error())
+			return
+		}
+		r.Enrichment = enrichment
+		r.EnrichmentState = state
+	}
 
-		if err := uc.zoneUC.SetMaxRisk(
-			context.Background(),
-			lat,
-			lon,
-			riskIncrement,
-		); err != nil {
+	// lưu trạng thái job
+	switch {
+	case aiErr == nil:
+		err = uc.jobs.Finish(ctx, job, domain.JobStatusDone, attempts, "")
+	case state == domain.EnrichmentFailed:
+		err = uc.jobs.Finish(ctx, job, domain.JobStatusFailed, attempts, aiErr.Error())
+	default:
+		next := time.Now().Add(worker.Backoff(uc.retry.BaseDelay, uc.retry.MaxDelay, attempts))
+		err = uc.jobs.Reschedule(ctx, job, attempts, next, aiErr.Error())
+	}
+	if err != nil {
+		log.Println("Failed to update enrichment job:", err)
+	}
 
-			uc.ws.SendToClient(client, "report_created", map[string]interface{}{
-				"ok":    false,
-				"error": "Failed to update danger zone: " + err.Error(),
-			})
+	// STEP 3 — Update danger zone, chỉ phần risk chưa tính ở lần chạy trước.
+	// Report đã CLEARED / RETRACTED thì không tính risk nữa.
+	if r.IsActive() {
+		if err := uc.applyZoneRisk(ctx, r); err != nil {
+			uc.notifyEnrichmentError(client, r, "Failed to update danger zone: "+err.Error())
 			return
 		}
+	}
 
-		// SUCCESS RESPONSE
-		uc.ws.SendToClient(client, "report_created", map[string]interface{}{
-			"ok":     true,
-			"report": r,
-			"ai": map[string]interface{}{
-				"urgency":       urgency,
-				"incident_type": category,
-				"confidence":    confidence,
-			},
-		})
-	})
+	// SUCCESS RESPONSE
+	response := map[string]interface{}{
+		"ok":               true,
+		"report":           r,
+		"enrichment_state": r.EnrichmentState,
+		"ai": map[string]interface{}{
+			"urgency":       r.Enrichment.Urgency,
+			"incident_type": r.Enrichment.Category,
+			"confidence":    float64(r.Enrichment.Confidence) / 100,
+		},
+	}
+	if client != nil {
+		uc.ws.SendToClient(client, "report_created", response)
+		return
+	}
+	// retry: chỉ báo khi kết quả thật đã về hoặc đã bỏ cuộc
+	if state != domain.EnrichmentFallback {
+		uc.ws.SendToUser(r.UserID, "report_enriched", response)
+	}
+}
 
-	return nil
+// applyZoneRisk đẩy risk theo urgency của report vào zone.
+// Lần đầu (RiskApplied = 0) tính như một nguồn mới; các lần sau chỉ nâng lên mức mới nếu cao hơn,
+// nên fallback rồi kết quả thật, hay chạy lại sau khi sửa report, không bị cộng hai lần.
+func (uc *ReportUseCase) applyZoneRisk(ctx context.Context, r *domain.Report) error {
+	risk := convertUrgencyToRisk(r.Enrichment.Urgency)
+	prev := r.RiskApplied
+	if risk <= prev {
+		return nil
+	}
+	ok, err := uc.repo.SetRiskApplied(ctx, r.ID, prev, risk)
+	if err != nil || !ok {
+		return err // !ok: lần chạy khác đã ghi trước
+	}
+	r.RiskApplied = risk
+
+	zoneCtx := domain.WithZoneCause(context.Background(), domain.ZoneCause{Type: domain.ZoneCauseReport, RefID: r.ID.Hex()})
+	lat := r.Location.Coordinates[1]
+	lon := r.Location.Coordinates[0]
+	if prev == 0 {
+		err = uc.zoneUC.SetMaxRisk(zoneCtx, lat, lon, risk, r.Enrichment.Category)
+	} else {
+		err = uc.zoneUC.RaiseRisk(zoneCtx, lat, lon, risk, r.Enrichment.Category)
+	}
+	if err != nil {
+		// trả lại giá trị cũ để lần chạy sau tính tiếp
+		if _, rerr := uc.repo.SetRiskApplied(ctx, r.ID, risk, prev); rerr != nil {
+			log.Println("Failed to reset applied risk:", rerr)
+		}
+		r.RiskApplied = prev
+	}
+	return err
+}
+
+func (uc *ReportUseCase) notifyEnrichmentError(client *ws.Client, r *domain.Report, msg string) {
+	if client == nil {
+		log.Println(msg)
+		return
+	}
+	uc.ws.SendToClient(client, "report_created", map[string]interface{}{
+		"ok":       false,
+		"reportId": r.ID.Hex(),
+		"error":    msg,
+	})
 }
 
 // Lấy report gần
 func (uc *ReportUseCase) GetNearbyReports(ctx context.Context, lat, lon, km float64) ([]*domain.Report, error) {
 	return uc.repo.GetNearbyReports(ctx, lat, lon, km)
 }
+
+// GetByID lấy 1 report
+func (uc *ReportUseCase) GetByID(ctx context.Context, reportID string) (*domain.Report, error) {
+	id, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, domain.ErrReportNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+	return uc.repo.GetByID(ctx, id)
+}
+
+// ListRevisions lấy lịch sử sửa của report
+func (uc *ReportUseCase) ListRevisions(ctx context.Context, reportID string) ([]domain.ReportRevision, error) {
+	r, err := uc.GetByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+	return uc.repo.ListRevisions(ctx, r.ID)
+}
+
+// Edit cho phép tác giả sửa nội dung report. Nội dung text đổi thì chạy lại AI.
+func (uc *ReportUseCase) Edit(ctx context.Context, userID, reportID string, edit domain.ReportEdit) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	r, err := uc.loadOwnReport(ctx, userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == domain.ReportStatusRetracted {
+		return nil, domain.ErrReportRetracted
+	}
+	if edit.Revision != nil && *edit.Revision != r.Revision {
+		return nil, domain.ErrRevisionConflict
+	}
+
+	prevRevision := r.Revision
+	before := snapshotReport(r, userID)
+	oldLocation := r.Location
+
+	textChanged := false
+	if edit.Type != nil && *edit.Type != r.Type {
+		r.Type, textChanged = *edit.Type, true
+	}
+	if edit.Detail != nil && *edit.Detail != r.Detail {
+		r.Detail, textChanged = *edit.Detail, true
+	}
+	if edit.Description != nil && *edit.Description != r.Description {
+		r.Description, textChanged = *edit.Description, true
+	}
+	if edit.Image != nil {
+		r.Image = *edit.Image
+	}
+	if edit.Lat != nil {
+		r.Location.Coordinates[1] = *edit.Lat
+	}
+	if edit.Lon != nil {
+		r.Location.Coordinates[0] = *edit.Lon
+	}
+	if lat, lon := r.Location.Coordinates[1], r.Location.Coordinates[0]; lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return nil, domain.ErrInvalidReportLocation
+	}
+	if textChanged {
+		r.EnrichmentState = domain.EnrichmentPending
+	}
+
+	r.Revision = prevRevision + 1
+	r.UpdatedAt = time.Now().Unix()
+
+	if err := uc.repo.Update(ctx, r, prevRevision); err != nil {
+		return nil, err
+	}
+	// revision chỉ ghi khi update đã thành công, conflict không để lại bản ghi giả
+	if err := uc.repo.AddRevision(ctx, before); err != nil {
+		log.Println("Failed to save report revision:", err)
+	}
+
+	// dời vị trí: bỏ risk khỏi zone cũ rồi tính lại ở vị trí mới
+	if r.Location.Coordinates != oldLocation.Coordinates && r.IsActive() {
+		if err := uc.moveZoneRisk(ctx, r, oldLocation, !textChanged); err != nil {
+			log.Println("Failed to move zone risk:", err)
+		}
+	}
+
+	// chạy lại AI qua job, EnrichmentWorker sẽ lấy ở lần poll kế tiếp.
+	// Risk đã tính được lưu trên report nên lần chạy lại chỉ nâng phần chênh lệch.
+	if textChanged {
+		if err := uc.jobs.Restart(ctx, r.ID, time.Now()); err != nil {
+			log.Println("Failed to restart enrichment job:", err)
+		}
+	}
+
+	uc.broadcastReport("report_updated", r)
+	return r, nil
+}
+
+// moveZoneRisk hạ risk zone ở vị trí cũ theo các report còn lại, reset risk đã tính
+// và (nếu không chờ AI chạy lại) đẩy risk vào zone ở vị trí mới
+func (uc *ReportUseCase) moveZoneRisk(ctx context.Context, r *domain.Report, oldLocation domain.GeoPoint, reapply bool) error {
+	if r.RiskApplied == 0 {
+		return nil
+	}
+	old := *r
+	old.Location = oldLocation
+	if err := uc.releaseZoneRisk(ctx, &old); err != nil {
+		return err
+	}
+	if _, err := uc.repo.SetRiskApplied(ctx, r.ID, r.RiskApplied, 0); err != nil {
+		return err
+	}
+	r.RiskApplied = 0
+	if !reapply || r.Enrichment == nil {
+		return nil
+	}
+	return uc.applyZoneRisk(ctx, r)
+}
+
+// Retract: tác giả rút lại report
+func (uc *ReportUseCase) Retract(ctx context.Context, userID, reportID string) (*domain.Report, error) {
+	return uc.changeStatus(ctx, userID, reportID, domain.ReportStatusRetracted)
+}
+
+// Clear: tác giả xác nhận khu vực đã an toàn (vd cây đã được dọn)
+func (uc *ReportUseCase) Clear(ctx context.Context, userID, reportID string) (*domain.Report, error) {
+	return uc.changeStatus(ctx, userID, reportID, domain.ReportStatusCleared)
+}
+
+func (uc *ReportUseCase) changeStatus(ctx context.Context, userID, reportID, status string) (*domain.Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	r, err := uc.loadOwnReport(ctx, userID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status == domain.ReportStatusRetracted {
+		return nil, domain.ErrReportRetracted
+	}
+	if r.Status == status {
+		return r, nil
+	}
+
+	prevRevision := r.Revision
+	before := snapshotReport(r, userID)
+
+	r.Status = status
+	r.Revision = prevRevision + 1
+	r.UpdatedAt = time.Now().Unix()
+
+	if err := uc.repo.Update(ctx, r, prevRevision); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.AddRevision(ctx, before); err != nil {
+		log.Println("Failed to save report revision:", err)
+	}
+
+	// report không còn hiệu lực -> không tính vào risk của zone nữa.
+	// Reset risk đã tính để CLEARED -> RETRACTED không trừ lần hai
+	if err := uc.releaseZoneRisk(ctx, r); err != nil {
+		log.Println("Failed to release zone risk:", err)
+	} else if r.RiskApplied > 0 {
+		if _, err := uc.repo.SetRiskApplied(ctx, r.ID, r.RiskApplied, 0); err != nil {
+			log.Println("Failed to reset applied risk:", err)
+		}
+		r.RiskApplied = 0
+	}
+
+	uc.broadcastReport("report_status", r)
+	return r, nil
+}
+
+func (uc *ReportUseCase) loadOwnReport(ctx context.Context, userID, reportID string) (*domain.Report, error) {
+	id, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, domain.ErrReportNotFound
+	}
+	r, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userID {
+		return nil, domain.ErrNotReportAuthor
+	}
+	return r, nil
+}
+
+// releaseZoneRisk trừ phần risk report đã đẩy vào các zone chứa nó (không dưới 0),
+// giữ risk từ nguồn khác (SOS, zone tạo tay, gộp zone). Không thấp hơn mức các report OPEN khác cần, chỉ hạ, không tăng.
+func (uc *ReportUseCase) releaseZoneRisk(ctx context.Context, r *domain.Report) error {
+	if r.RiskApplied == 0 {
+		return nil // report chưa đẩy risk vào zone
+	}
+	ctx = domain.WithZoneCause(ctx, domain.ZoneCause{Type: domain.ZoneCauseReport, RefID: r.ID.Hex()})
+	lat := r.Location.Coordinates[1]
+	lon := r.Location.Coordinates[0]
+
+	zones, err := uc.zoneUC.FetchAllByLatLon(ctx, lat, lon)
+	if err != nil {
+		return err
+	}
+
+	for _, z := range zones {
+		if z.Pinned() {
+			continue
+		}
+		others, err := uc.repo.GetNearbyReports(ctx, z.Center.Coordinates[1], z.Center.Coordinates[0], z.Radius/1000)
+		if err != nil {
+			return err
+		}
+
+		risk := math.Max(z.RiskScore-r.RiskApplied, 0)
+		for _, o := range others {
+			if o.ID == r.ID || !o.IsActive() || o.Enrichment == nil {
+				continue
+			}
+			risk = math.Max(risk, convertUrgencyToRisk(o.Enrichment.Urgency))
+		}
+		if risk >= z.RiskScore {
+			continue
+		}
+
+		z.RiskScore = risk
+		z.Label = domain.RiskLabel(risk)
+		z.UpdatedAt = time.Now().UnixMilli()
+		if err := uc.zoneUC.Update(ctx, &z); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// broadcastReport gửi cho tác giả và các client đang xem vùng bản đồ có report
+func (uc *ReportUseCase) broadcastReport(destination string, r *domain.Report) {
+	if uc.ws == nil {
+		return
+	}
+	payload := map[string]interface{}{
+		"reportId":   r.ID.Hex(),
+		"status":     r.Status,
+		"revision":   r.Revision,
+		"updated_at": r.UpdatedAt,
+		"report":     r,
+	}
+	uc.ws.SendToUserAndViewport(r.UserID, destination, r.Location.Coordinates[1], r.Location.Coordinates[0], 0, payload)
+}
+
+func snapshotReport(r *domain.Report, editedBy string) *domain.ReportRevision {
+	return &domain.ReportRevision{
+		ReportID:    r.ID,
+		Revision:    r.Revision,
+		Type:        r.Type,
+		Detail:      r.Detail,
+		Description: r.Description,
+		Image:       r.Image,
+		Location:    r.Location,
+		Status:      r.Status,
+		EditedBy:    editedBy,
+		EditedAt:    time.Now().Unix(),
+	}
+}
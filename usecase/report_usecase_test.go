@@ -0,0 +1,275 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reportStore giữ report trong bộ nhớ, Update kiểm tra revision như repository thật
+type reportStore struct {
+	domain.ReportRepository
+	reports   map[primitive.ObjectID]*domain.Report
+	revisions []domain.ReportRevision
+}
+
+func (f *reportStore) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Report, error) {
+	r, ok := f.reports[id]
+	if !ok {
+		return nil, domain.ErrReportNotFound
+	}
+	cp := *r
+	return &cp, nil
+}
+
+func (f *reportStore) Update(ctx context.Context, r *domain.Report, expectedRevision int) error {
+	if f.reports[r.ID].Revision != expectedRevision {
+		return domain.ErrRevisionConflict
+	}
+	cp := *r
+	cp.RiskApplied = f.reports[r.ID].RiskApplied // Update không ghi risk_applied
+	f.reports[r.ID] = &cp
+	return nil
+}
+
+func (f *reportStore) AddRevision(ctx context.Context, rev *domain.ReportRevision) error {
+	f.revisions = append(f.revisions, *rev)
+	return nil
+}
+
+func (f *reportStore) ListRevisions(ctx context.Context, id primitive.ObjectID) ([]domain.ReportRevision, error) {
+	var out []domain.ReportRevision
+	for _, rev := range f.revisions {
+		if rev.ReportID == id {
+			out = append(out, rev)
+		}
+	}
+	return out, nil
+}
+
+func (f *reportStore) SetRiskApplied(ctx context.Context, id primitive.ObjectID, prev, risk float64) (bool, error) {
+	r := f.reports[id]
+	if r.RiskApplied != prev {
+		return false, nil
+	}
+	r.RiskApplied = risk
+	return true, nil
+}
+
+func (f *reportStore) GetNearbyReports(ctx context.Context, lat, lon, km float64) ([]*domain.Report, error) {
+	var out []*domain.Report
+	for _, r := range f.reports {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+type reportJobs struct {
+	domain.EnrichmentJobRepository
+	restarted []primitive.ObjectID
+}
+
+func (f *reportJobs) Restart(ctx context.Context, id primitive.ObjectID, runAt time.Time) error {
+	f.restarted = append(f.restarted, id)
+	return nil
+}
+
+// reportZones: một zone chứa mọi điểm
+type reportZones struct {
+	domain.ZoneUsecase
+	zone domain.Zone
+}
+
+func (f *reportZones) FetchAllByLatLon(ctx context.Context, lat, lon float64) ([]domain.Zone, error) {
+	return []domain.Zone{f.zone}, nil
+}
+
+func (f *reportZones) Update(ctx context.Context, z *domain.Zone) error {
+	f.zone = *z
+	return nil
+}
+
+type reportFixture struct {
+	store *reportStore
+	jobs  *reportJobs
+	zones *reportZones
+	uc    *usecase.ReportUseCase
+}
+
+func newReportFixture(zoneRisk float64, reports ...*domain.Report) *reportFixture {
+	f := &reportFixture{
+		store: &reportStore{reports: map[primitive.ObjectID]*domain.Report{}},
+		jobs:  &reportJobs{},
+		zones: &reportZones{zone: domain.Zone{
+			ID:        primitive.NewObjectID(),
+			Center:    domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106.7, 10.77}},
+			Radius:    1000,
+			RiskScore: zoneRisk,
+			Label:     domain.RiskLabel(zoneRisk),
+		}},
+	}
+	for _, r := range reports {
+		f.store.reports[r.ID] = r
+	}
+	f.uc = usecase.NewReportUC(nil, nil, nil, f.store, f.jobs, f.zones, usecase.EnrichmentRetryConfig{}, time.Second)
+	return f
+}
+
+func openReport(userID, urgency string, riskApplied float64) *domain.Report {
+	return &domain.Report{
+		ID:          primitive.NewObjectID(),
+		UserID:      userID,
+		Description: "cây đổ chắn đường",
+		Location:    domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106.7, 10.77}},
+		Status:      domain.ReportStatusOpen,
+		Enrichment:  &domain.ReportEnrichment{Urgency: urgency},
+		RiskApplied: riskApplied,
+		Revision:    1,
+	}
+}
+
+func TestReportEdit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("author edits text and reruns AI", func(t *testing.T) {
+		r := openReport("u1", "MEDIUM", 0)
+		f := newReportFixture(0, r)
+		desc := "nước ngập tới gối"
+
+		out, err := f.uc.Edit(ctx, "u1", r.ID.Hex(), domain.ReportEdit{Description: &desc})
+		require.NoError(t, err)
+		assert.Equal(t, 2, out.Revision)
+		assert.Equal(t, domain.EnrichmentPending, out.EnrichmentState)
+		assert.Equal(t, []primitive.ObjectID{r.ID}, f.jobs.restarted)
+
+		revs, err := f.uc.ListRevisions(ctx, r.ID.Hex())
+		require.NoError(t, err)
+		require.Len(t, revs, 1)
+		assert.Equal(t, 1, revs[0].Revision)
+		assert.Equal(t, "cây đổ chắn đường", revs[0].Description)
+	})
+
+	t.Run("only the author", func(t *testing.T) {
+		r := openReport("u1", "MEDIUM", 0)
+		f := newReportFixture(0, r)
+		desc := "x"
+
+		_, err := f.uc.Edit(ctx, "u2", r.ID.Hex(), domain.ReportEdit{Description: &desc})
+		assert.ErrorIs(t, err, domain.ErrNotReportAuthor)
+		assert.Empty(t, f.store.revisions)
+	})
+
+	t.Run("out of range location", func(t *testing.T) {
+		r := openReport("u1", "MEDIUM", 0)
+		f := newReportFixture(0, r)
+		lat, lon := 91.0, 200.0
+
+		_, err := f.uc.Edit(ctx, "u1", r.ID.Hex(), domain.ReportEdit{Lat: &lat})
+		assert.ErrorIs(t, err, domain.ErrInvalidReportLocation)
+		_, err = f.uc.Edit(ctx, "u1", r.ID.Hex(), domain.ReportEdit{Lon: &lon})
+		assert.ErrorIs(t, err, domain.ErrInvalidReportLocation)
+		assert.Equal(t, 1, f.store.reports[r.ID].Revision)
+	})
+
+	t.Run("stale revision", func(t *testing.T) {
+		r := openReport("u1", "MEDIUM", 0)
+		f := newReportFixture(0, r)
+		stale := 0
+
+		_, err := f.uc.Edit(ctx, "u1", r.ID.Hex(), domain.ReportEdit{Revision: &stale})
+		assert.ErrorIs(t, err, domain.ErrRevisionConflict)
+	})
+}
+
+func TestReportStatusTransitions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("clear then retract", func(t *testing.T) {
+		r := openReport("u1", "MEDIUM", 0)
+		f := newReportFixture(0, r)
+
+		out, err := f.uc.Clear(ctx, "u1", r.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReportStatusCleared, out.Status)
+
+		out, err = f.uc.Retract(ctx, "u1", r.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, domain.ReportStatusRetracted, out.Status)
+		assert.Equal(t, 3, out.Revision)
+
+		// đã rút thì không sửa / đổi trạng thái được nữa
+		_, err = f.uc.Clear(ctx, "u1", r.ID.Hex())
+		assert.ErrorIs(t, err, domain.ErrReportRetracted)
+		desc := "x"
+		_, err = f.uc.Edit(ctx, "u1", r.ID.Hex(), domain.ReportEdit{Description: &desc})
+		assert.ErrorIs(t, err, domain.ErrReportRetracted)
+
+		revs, err := f.uc.ListRevisions(ctx, r.ID.Hex())
+		require.NoError(t, err)
+		require.Len(t, revs, 2)
+		assert.Equal(t, domain.ReportStatusOpen, revs[0].Status)
+		assert.Equal(t, domain.ReportStatusCleared, revs[1].Status)
+	})
+
+	t.Run("only the author", func(t *testing.T) {
+		r := openReport("u1", "MEDIUM", 0)
+		f := newReportFixture(0, r)
+
+		_, err := f.uc.Retract(ctx, "u2", r.ID.Hex())
+		assert.ErrorIs(t, err, domain.ErrNotReportAuthor)
+		_, err = f.uc.Clear(ctx, "u2", r.ID.Hex())
+		assert.ErrorIs(t, err, domain.ErrNotReportAuthor)
+		assert.Equal(t, domain.ReportStatusOpen, f.store.reports[r.ID].Status)
+	})
+}
+
+func TestReportReleaseZoneRisk(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps alert risk", func(t *testing.T) {
+		// report MEDIUM (0.6) + một SOS (0.2)
+		r := openReport("u1", "MEDIUM", 0.6)
+		f := newReportFixture(0.8, r)
+
+		_, err := f.uc.Retract(ctx, "u1", r.ID.Hex())
+		require.NoError(t, err)
+		assert.InDelta(t, 0.2, f.zones.zone.RiskScore, 1e-9)
+		assert.Zero(t, f.store.reports[r.ID].RiskApplied)
+	})
+
+	t.Run("not below other open reports", func(t *testing.T) {
+		r := openReport("u1", "HIGH", 0.9)
+		other := openReport("u2", "LOW", 0.3)
+		f := newReportFixture(0.9, r, other)
+
+		_, err := f.uc.Clear(ctx, "u1", r.ID.Hex())
+		require.NoError(t, err)
+		assert.InDelta(t, 0.3, f.zones.zone.RiskScore, 1e-9)
+	})
+
+	t.Run("report without applied risk", func(t *testing.T) {
+		r := openReport("u1", "HIGH", 0)
+		f := newReportFixture(0.5, r)
+
+		_, err := f.uc.Retract(ctx, "u1", r.ID.Hex())
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, f.zones.zone.RiskScore)
+	})
+
+	t.Run("clear then retract releases once", func(t *testing.T) {
+		r := openReport("u1", "MEDIUM", 0.6)
+		f := newReportFixture(1, r)
+
+		_, err := f.uc.Clear(ctx, "u1", r.ID.Hex())
+		require.NoError(t, err)
+		_, err = f.uc.Retract(ctx, "u1", r.ID.Hex())
+		require.NoError(t, err)
+		assert.InDelta(t, 0.4, f.zones.zone.RiskScore, 1e-9)
+	})
+}
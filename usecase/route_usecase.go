@@ -0,0 +1,387 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/roadgraph"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/textnorm"
+)
+
+const (
+	routeSampleStepM   = 25.0   // khoảng cách giữa các điểm lấy mẫu risk dọc đường
+	routeSnapMaxM      = 500.0  // origin / destination phải cách đường tối đa chừng này
+	routeBufferM       = 2000.0 // lấy dữ liệu rộng hơn đường để đường tránh có chỗ vòng
+	routeMaxSpanDeg    = 1.0    // ~110km, quá thì không đánh giá
+	routeMaxPoints     = 5000
+	blockingReportM    = 100.0 // bán kính report chặn đường
+	alertRouteRisk     = 0.9   // alert đang mở tính như vùng HIGH
+	routeMediumPenalty = 3.0   // hệ số chi phí khi tìm đường tránh
+	routeHighPenalty   = 50.0  // HIGH vẫn đi được (để thoát khỏi vùng đang đứng) nhưng rất đắt
+)
+
+// hazard của report làm đường không đi được
+var blockingHazards = []string{textnorm.HazardFlood, textnorm.HazardLandslide, textnorm.HazardAccident, textnorm.HazardFire}
+
+type routeUsecase struct {
+	zoneRepo   domain.ZoneRepository
+	cellRepo   domain.CellRepository
+	reportRepo domain.ReportRepository
+	alertRepo  domain.AlertRepository
+	graph      *roadgraph.Graph // nil = không có file đồ thị đường
+	timeout    time.Duration
+}
+
+func NewRouteUsecase(zoneRepo domain.ZoneRepository, cellRepo domain.CellRepository, reportRepo domain.ReportRepository,
+	alertRepo domain.AlertRepository, graph *roadgraph.Graph, timeout time.Duration) domain.RouteUsecase {
+	return &routeUsecase{
+		zoneRepo:   zoneRepo,
+		cellRepo:   cellRepo,
+		reportRepo: reportRepo,
+		alertRepo:  alertRepo,
+		graph:      graph,
+		timeout:    timeout,
+	}
+}
+
+// Assess đánh giá risk của đường đi và gợi ý đường tránh vùng HIGH nếu cần
+func (u *routeUsecase) Assess(ctx context.Context, req domain.RouteAssessRequest) (*domain.RouteAssessResponse, error) {
+	from, to, err := routeEndpoints(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp domain.RouteAssessResponse
+	source, path := domain.RouteSourcePolyline, req.Polyline
+	if len(path) < 2 {
+		source, path = domain.RouteSourceDirect, []domain.LatLon{from, to}
+		if u.graph == nil {
+			resp.AlternativeError = domain.ErrNoRoadGraph.Error()
+		} else if p, err := u.roadPath(from, to, nil); err != nil {
+			// không đi được trên đồ thị: vẫn đánh giá đường thẳng
+			resp.AlternativeError = err.Error()
+		} else {
+			source, path = domain.RouteSourceRoadGraph, p
+		}
+	}
+
+	b, err := routeBounds(append([]domain.LatLon{from, to}, path...))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	field, err := u.loadRisk(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Route = field.assess(source, path)
+	if resp.Route.Label != "HIGH" && !resp.Route.Blocked {
+		return &resp, nil
+	}
+	if u.graph == nil {
+		resp.AlternativeError = domain.ErrNoRoadGraph.Error()
+		return &resp, nil
+	}
+
+	alt, err := u.roadPath(from, to, field.edgeCost(u.graph, b))
+	switch {
+	case err != nil:
+		resp.AlternativeError = err.Error()
+	default:
+		a := field.assess(domain.RouteSourceRoadGraph, alt)
+		if a.HighM < resp.Route.HighM || (resp.Route.Blocked && !a.Blocked) {
+			resp.Alternative = &a
+			resp.AlternativeError = ""
+		} else {
+			resp.AlternativeError = domain.ErrNoSaferRoute.Error()
+		}
+	}
+	return &resp, nil
+}
+
+func routeEndpoints(req domain.RouteAssessRequest) (domain.LatLon, domain.LatLon, error) {
+	var from, to domain.LatLon
+	switch {
+	case len(req.Polyline) >= 2:
+		if len(req.Polyline) > routeMaxPoints {
+			return from, to, domain.ErrRouteTooLong
+		}
+		for _, p := range req.Polyline {
+			if !validLatLon(p) {
+				return from, to, domain.ErrRouteInput
+			}
+		}
+		from, to = req.Polyline[0], req.Polyline[len(req.Polyline)-1]
+	case req.Origin != nil && req.Destination != nil:
+		from, to = *req.Origin, *req.Destination
+		if !validLatLon(from) || !validLatLon(to) {
+			return from, to, domain.ErrRouteInput
+		}
+	default:
+		return from, to, domain.ErrRouteInput
+	}
+	return from, to, nil
+}
+
+func validLatLon(p domain.LatLon) bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// routeBounds: khung bao các điểm cộng thêm routeBufferM
+func routeBounds(points []domain.LatLon) (domain.BoundsFilter, error) {
+	b := domain.BoundsFilter{MinLat: 90, MinLon: 180, MaxLat: -90, MaxLon: -180}
+	for _, p := range points {
+		b.MinLat, b.MaxLat = math.Min(b.MinLat, p.Lat), math.Max(b.MaxLat, p.Lat)
+		b.MinLon, b.MaxLon = math.Min(b.MinLon, p.Lon), math.Max(b.MaxLon, p.Lon)
+	}
+	if b.MaxLat-b.MinLat > routeMaxSpanDeg || b.MaxLon-b.MinLon > routeMaxSpanDeg {
+		return b, domain.ErrRouteTooLong
+	}
+
+	// lấy vĩ độ xa xích đạo nhất để vùng đệm theo kinh độ đủ rộng
+	dLat, dLon := geo.DegreesAround(math.Max(math.Abs(b.MinLat), math.Abs(b.MaxLat)), routeBufferM)
+	b.MinLat, b.MaxLat = math.Max(-90, b.MinLat-dLat), math.Min(90, b.MaxLat+dLat)
+	b.MinLon, b.MaxLon = b.MinLon-dLon, b.MaxLon+dLon
+	return b, nil
+}
+
+// roadPath tìm đường trên đồ thị, cost nil = ngắn nhất. Kết quả bắt đầu ở from và kết thúc ở to.
+func (u *routeUsecase) roadPath(from, to domain.LatLon, cost roadgraph.CostFunc) ([]domain.LatLon, error) {
+	a, _, okA := u.graph.Nearest(from.Lat, from.Lon, routeSnapMaxM)
+	b, _, okB := u.graph.Nearest(to.Lat, to.Lon, routeSnapMaxM)
+	if !okA || !okB {
+		return nil, domain.ErrRouteNoSnap
+	}
+	nodes, _, ok := u.graph.ShortestPath(a, b, cost)
+	if !ok {
+		return nil, domain.ErrNoRoute
+	}
+
+	path := make([]domain.LatLon, 0, len(nodes)+2)
+	path = append(path, from)
+	for _, i := range nodes {
+		n := u.graph.Node(i)
+		path = append(path, domain.LatLon{Lat: n.Lat, Lon: n.Lon})
+	}
+	return append(path, to), nil
+}
+
+// routeRisk giữ dữ liệu nguy hiểm quanh đường để tính risk tại từng điểm trong bộ nhớ
+type routeRisk struct {
+	zones    []domain.Zone
+	cells    map[string]domain.CellAggregate
+	level    int
+	alerts   []*domain.Alert
+	blocking []*domain.Report
+}
+
+type routeHit struct {
+	kind, id, detail string
+	risk             float64
+	lat, lon         float64
+}
+
+func (u *routeUsecase) loadRisk(ctx context.Context, b domain.BoundsFilter) (*routeRisk, error) {
+	f := &routeRisk{cells: map[string]domain.CellAggregate{}, level: domain.CellLevel}
+
+	var err error
+	if f.zones, err = u.zoneRepo.FetchInBounds(ctx, b.MinLat, b.MinLon, b.MaxLat, b.MaxLon); err != nil {
+		return nil, err
+	}
+	if f.alerts, err = u.alertRepo.FetchActiveInBounds(ctx, b); err != nil {
+		return nil, err
+	}
+	reports, err := u.reportRepo.FetchActiveInBounds(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reports {
+		if isBlockingReport(r) {
+			f.blocking = append(f.blocking, r)
+		}
+	}
+
+	// đường dài thì dùng lưới thô hơn cho vừa maxGridCells
+	for f.level > domain.MinCellLevel && geo.CountBoxCells(b.MinLat, b.MinLon, b.MaxLat, b.MaxLon, f.level) > maxGridCells {
+		f.level--
+	}
+	cells, err := u.cellRepo.AggregateInBounds(ctx, b, f.level)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cells {
+		f.cells[c.Hash] = c
+	}
+	return f, nil
+}
+
+// isBlockingReport: report còn hiệu lực về ngập / sạt lở / tai nạn / cháy, không phải mức LOW
+func isBlockingReport(r *domain.Report) bool {
+	if !r.IsActive() {
+		return false
+	}
+	if r.Enrichment != nil {
+		if r.Enrichment.Urgency == "LOW" {
+			return false
+		}
+		for _, h := range blockingHazards {
+			if strings.EqualFold(r.Enrichment.Category, h) {
+				return true
+			}
+		}
+	}
+	_, ok := textnorm.Hazards.Match(r.Type+" "+r.Detail+" "+r.Description, blockingHazards...)
+	return ok
+}
+
+// at trả risk lớn nhất tại điểm, blocked = nằm trong bán kính report chặn đường.
+// hits != nil thì ghi thêm các nguồn nguy hiểm chạm điểm.
+func (f *routeRisk) at(lat, lon float64, hits *[]routeHit) (float64, bool) {
+	risk, blocked := 0.0, false
+	hit := func(h routeHit) {
+		risk = math.Max(risk, h.risk)
+		if hits != nil {
+			*hits = append(*hits, h)
+		}
+	}
+
+	for _, z := range f.zones {
+		zLat, zLon := z.Center.Coordinates[1], z.Center.Coordinates[0]
+		if geo.DistanceMeters(lat, lon, zLat, zLon) <= z.Radius {
+			hit(routeHit{kind: domain.RouteHazardZone, id: z.ID.Hex(), detail: z.Hazard, risk: z.RiskScore, lat: zLat, lon: zLon})
+		}
+	}
+	if c, ok := f.cells[geo.Encode(lat, lon, f.level)]; ok && c.CellRisk > 0 {
+		center := geo.CellCenter(c.Hash)
+		hit(routeHit{kind: domain.RouteHazardCell, id: c.Hash, risk: c.CellRisk, lat: center[1], lon: center[0]})
+	}
+	for _, a := range f.alerts {
+		aLat, aLon := a.Location.Coordinates[1], a.Location.Coordinates[0]
+		if geo.DistanceMeters(lat, lon, aLat, aLon) <= a.RadiusM {
+			hit(routeHit{kind: domain.RouteHazardAlert, id: a.ID.Hex(), detail: a.Body, risk: alertRouteRisk, lat: aLat, lon: aLon})
+		}
+	}
+	for _, r := range f.blocking {
+		rLat, rLon := r.Location.Coordinates[1], r.Location.Coordinates[0]
+		if geo.DistanceMeters(lat, lon, rLat, rLon) <= blockingReportM {
+			blocked = true
+			hit(routeHit{kind: domain.RouteHazardReport, id: r.ID.Hex(), detail: r.Type, risk: 1, lat: rLat, lon: rLon})
+		}
+	}
+	return math.Min(risk, 1), blocked
+}
+
+// assess lấy mẫu risk mỗi routeSampleStepM dọc đường và gộp thành profile
+func (f *routeRisk) assess(source string, path []domain.LatLon) domain.RouteAssessment {
+	out := domain.RouteAssessment{
+		Source:    source,
+		Path:      path,
+		Stretches: []domain.RouteStretch{},
+		Hazards:   []domain.RouteHazard{},
+	}
+	seen := map[string]int{}
+	var weighted float64
+	var hits []routeHit
+
+	for i := 1; i < len(path); i++ {
+		a, b := path[i-1], path[i]
+		segM := geo.DistanceMeters(a.Lat, a.Lon, b.Lat, b.Lon)
+		n := int(math.Max(1, math.Ceil(segM/routeSampleStepM)))
+		stepM := segM / float64(n)
+
+		for k := 0; k < n; k++ {
+			t := (float64(k) + 0.5) / float64(n)
+			hits = hits[:0]
+			risk, blocked := f.at(a.Lat+(b.Lat-a.Lat)*t, a.Lon+(b.Lon-a.Lon)*t, &hits)
+			label := domain.RiskLabel(risk)
+			fromM := out.DistanceM
+
+			out.DistanceM += stepM
+			weighted += risk * stepM
+			out.MaxRisk = math.Max(out.MaxRisk, risk)
+			out.Blocked = out.Blocked || blocked
+			switch label {
+			case "HIGH":
+				out.HighM += stepM
+			case "MEDIUM":
+				out.MediumM += stepM
+			}
+
+			if last := len(out.Stretches) - 1; last >= 0 && out.Stretches[last].Label == label && out.Stretches[last].Blocked == blocked {
+				out.Stretches[last].ToM = out.DistanceM
+				out.Stretches[last].RiskScore = math.Max(out.Stretches[last].RiskScore, risk)
+			} else {
+				out.Stretches = append(out.Stretches, domain.RouteStretch{
+					FromM: fromM, ToM: out.DistanceM, RiskScore: risk, Label: label, Blocked: blocked,
+				})
+			}
+
+			for _, h := range hits {
+				key := h.kind + "/" + h.id
+				if j, ok := seen[key]; ok {
+					out.Hazards[j].LengthM += stepM
+					continue
+				}
+				seen[key] = len(out.Hazards)
+				out.Hazards = append(out.Hazards, domain.RouteHazard{
+					Kind: h.kind, ID: h.id, RiskScore: h.risk, Label: domain.RiskLabel(h.risk), Detail: h.detail,
+					AtM: fromM, LengthM: stepM, Lat: h.lat, Lon: h.lon,
+				})
+			}
+		}
+	}
+
+	if out.DistanceM > 0 {
+		out.AvgRisk = weighted / out.DistanceM
+	}
+	out.Label = domain.RiskLabel(out.MaxRisk)
+	return out
+}
+
+// edgeCost: chi phí cạnh theo risk, không ra khỏi khung dữ liệu b, không đi qua report chặn đường
+func (f *routeRisk) edgeCost(g *roadgraph.Graph, b domain.BoundsFilter) roadgraph.CostFunc {
+	type nodeRisk struct {
+		risk    float64
+		blocked bool
+	}
+	memo := map[int]nodeRisk{}
+	riskAt := func(i int) nodeRisk {
+		if r, ok := memo[i]; ok {
+			return r
+		}
+		n := g.Node(i)
+		risk, blocked := f.at(n.Lat, n.Lon, nil)
+		memo[i] = nodeRisk{risk, blocked}
+		return memo[i]
+	}
+
+	return func(from, to int, e roadgraph.Edge) float64 {
+		a, c := g.Node(from), g.Node(to)
+		if !inBounds(b, c.Lat, c.Lon) {
+			return math.Inf(1)
+		}
+		end := riskAt(to)
+		midRisk, midBlocked := f.at((a.Lat+c.Lat)/2, (a.Lon+c.Lon)/2, nil)
+		if end.blocked || midBlocked {
+			return math.Inf(1)
+		}
+
+		risk := math.Max(end.risk, midRisk)
+		switch domain.RiskLabel(risk) {
+		case "HIGH":
+			return e.LengthM * routeHighPenalty
+		case "MEDIUM":
+			return e.LengthM * routeMediumPenalty
+		default:
+			return e.LengthM * (1 + risk)
+		}
+	}
+}
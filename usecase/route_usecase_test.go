@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/roadgraph"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hình vuông 2 chiều: 1-2-3 (qua góc đông nam) hoặc 1-4-3 (qua góc tây bắc)
+const routeSquareOSM = `<?xml version="1.0"?>
+<osm version="0.6">
+  <node id="1" lat="10.000" lon="106.000"/>
+  <node id="2" lat="10.000" lon="106.010"/>
+  <node id="3" lat="10.010" lon="106.010"/>
+  <node id="4" lat="10.010" lon="106.000"/>
+  <way id="10"><nd ref="1"/><nd ref="2"/><nd ref="3"/><tag k="highway" v="primary"/></way>
+  <way id="11"><nd ref="1"/><nd ref="4"/><nd ref="3"/><tag k="highway" v="residential"/></way>
+</osm>`
+
+var (
+	routeN1 = domain.LatLon{Lat: 10, Lon: 106}
+	routeN2 = domain.LatLon{Lat: 10, Lon: 106.01}
+	routeN3 = domain.LatLon{Lat: 10.01, Lon: 106.01}
+	routeN4 = domain.LatLon{Lat: 10.01, Lon: 106}
+)
+
+// chỉ cài các method Assess dùng, gọi method khác sẽ panic
+type routeZoneRepo struct {
+	domain.ZoneRepository
+	zones []domain.Zone
+}
+
+func (f routeZoneRepo) FetchInBounds(ctx context.Context, minLat, minLon, maxLat, maxLon float64) ([]domain.Zone, error) {
+	return f.zones, nil
+}
+
+type routeCellRepo struct{ domain.CellRepository }
+
+func (routeCellRepo) AggregateInBounds(ctx context.Context, b domain.BoundsFilter, level int) ([]domain.CellAggregate, error) {
+	return nil, nil
+}
+
+type routeReportRepo struct {
+	domain.ReportRepository
+	reports []*domain.Report
+}
+
+func (f routeReportRepo) FetchActiveInBounds(ctx context.Context, b domain.BoundsFilter) ([]*domain.Report, error) {
+	return f.reports, nil
+}
+
+type routeAlertRepo struct{ domain.AlertRepository }
+
+func (routeAlertRepo) FetchActiveInBounds(ctx context.Context, b domain.BoundsFilter) ([]*domain.Alert, error) {
+	return nil, nil
+}
+
+func routeZone(p domain.LatLon, radius, risk float64) domain.Zone {
+	return domain.Zone{
+		Center:    domain.GeoPoint{Type: "Point", Coordinates: [2]float64{p.Lon, p.Lat}},
+		Radius:    radius,
+		RiskScore: risk,
+	}
+}
+
+func newRouteUsecase(t *testing.T, zones []domain.Zone, reports []*domain.Report) domain.RouteUsecase {
+	g, err := roadgraph.ReadOSM(strings.NewReader(routeSquareOSM))
+	require.NoError(t, err)
+	return usecase.NewRouteUsecase(routeZoneRepo{zones: zones}, routeCellRepo{}, routeReportRepo{reports: reports}, routeAlertRepo{}, g, time.Second)
+}
+
+func TestRouteAssessAvoidsHighZone(t *testing.T) {
+	uc := newRouteUsecase(t, []domain.Zone{routeZone(routeN2, 300, 0.9)}, nil)
+
+	resp, err := uc.Assess(context.Background(), domain.RouteAssessRequest{
+		Polyline: []domain.LatLon{routeN1, routeN2, routeN3},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "HIGH", resp.Route.Label)
+	assert.Greater(t, resp.Route.HighM, 0.0)
+	require.NotNil(t, resp.Alternative)
+	assert.Empty(t, resp.AlternativeError)
+	assert.Equal(t, domain.RouteSourceRoadGraph, resp.Alternative.Source)
+	assert.Contains(t, resp.Alternative.Path, routeN4)
+	assert.Zero(t, resp.Alternative.HighM)
+}
+
+func TestRouteAssessPrefersMediumOverHigh(t *testing.T) {
+	// cả 2 đường đều có vùng nguy hiểm: đường tránh đi qua MEDIUM thay vì HIGH
+	uc := newRouteUsecase(t, []domain.Zone{
+		routeZone(routeN2, 300, 0.9),
+		routeZone(routeN4, 300, 0.4),
+	}, nil)
+
+	resp, err := uc.Assess(context.Background(), domain.RouteAssessRequest{
+		Polyline: []domain.LatLon{routeN1, routeN2, routeN3},
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, resp.Alternative)
+	assert.Contains(t, resp.Alternative.Path, routeN4)
+	assert.Zero(t, resp.Alternative.HighM)
+	assert.Greater(t, resp.Alternative.MediumM, 0.0)
+	assert.Equal(t, "MEDIUM", resp.Alternative.Label)
+}
+
+func TestRouteAssessNoSaferRoute(t *testing.T) {
+	// vùng HIGH phủ cả khu: đường tránh cũng HIGH suốt, không ngắn hơn đường đang đi
+	uc := newRouteUsecase(t, []domain.Zone{routeZone(domain.LatLon{Lat: 10.005, Lon: 106.005}, 3000, 0.9)}, nil)
+
+	resp, err := uc.Assess(context.Background(), domain.RouteAssessRequest{
+		Polyline: []domain.LatLon{routeN1, routeN4, routeN3},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "HIGH", resp.Route.Label)
+	assert.Nil(t, resp.Alternative)
+	assert.Equal(t, domain.ErrNoSaferRoute.Error(), resp.AlternativeError)
+}
+
+func TestRouteAssessBlockedDestination(t *testing.T) {
+	// report ngập ngay điểm đến: mọi cạnh vào điểm đến đều bị chặn
+	flood := &domain.Report{
+		Status:     domain.ReportStatusOpen,
+		Location:   domain.GeoPoint{Type: "Point", Coordinates: [2]float64{routeN3.Lon, routeN3.Lat}},
+		Enrichment: &domain.ReportEnrichment{Category: "FLOOD", Urgency: "HIGH"},
+	}
+	uc := newRouteUsecase(t, nil, []*domain.Report{flood})
+
+	resp, err := uc.Assess(context.Background(), domain.RouteAssessRequest{
+		Polyline: []domain.LatLon{routeN1, routeN2, routeN3},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, resp.Route.Blocked)
+	assert.Nil(t, resp.Alternative)
+	assert.Equal(t, domain.ErrNoRoute.Error(), resp.AlternativeError)
+}
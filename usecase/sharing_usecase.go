@@ -0,0 +1,359 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	sharingMaxPause = 7 * 24 * time.Hour
+	// user, cài đặt và thành viên nhóm dùng cho Audience được giữ lại chừng này (mỗi fix location đều gọi Audience).
+	// SOS không cache nên vẫn có hiệu lực ngay; đổi cài đặt thì xóa cache của user đó.
+	sharingAudienceTTL = 30 * time.Second
+)
+
+// level geohash dùng để làm mờ vị trí theo độ chính xác
+var precisionLevel = map[string]int{
+	domain.SharingApprox: 7,
+	domain.SharingArea:   6,
+}
+
+type sharingUsecase struct {
+	repo      domain.SharingRepository
+	userRepo  domain.UserRepository
+	groupRepo domain.GroupRepository
+	alertRepo domain.AlertRepository
+	timeout   time.Duration
+
+	mu    sync.Mutex
+	bases map[string]*audienceBase // userID -> dữ liệu Audience đã tải
+	swept time.Time
+}
+
+type audienceBase struct {
+	user     domain.User
+	settings *domain.SharingSettings
+	members  map[primitive.ObjectID][]primitive.ObjectID // groupID -> thành viên
+	loadedAt time.Time
+}
+
+func NewSharingUsecase(repo domain.SharingRepository, userRepo domain.UserRepository, groupRepo domain.GroupRepository,
+	alertRepo domain.AlertRepository, timeout time.Duration) domain.SharingUsecase {
+	return &sharingUsecase{
+		repo:      repo,
+		userRepo:  userRepo,
+		groupRepo: groupRepo,
+		alertRepo: alertRepo,
+		timeout:   timeout,
+		bases:     map[string]*audienceBase{},
+	}
+}
+
+// forget xóa dữ liệu Audience đã cache của user (sau khi đổi cài đặt)
+func (u *sharingUsecase) forget(userID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.bases, userID)
+}
+
+// audienceBase lấy user, cài đặt và thành viên các nhóm của user (một query cho mọi nhóm), có cache
+func (u *sharingUsecase) audienceBase(ctx context.Context, userID string) (*audienceBase, error) {
+	now := time.Now()
+	u.mu.Lock()
+	if now.Sub(u.swept) > sharingAudienceTTL {
+		for id, b := range u.bases {
+			if now.Sub(b.loadedAt) >= sharingAudienceTTL {
+				delete(u.bases, id)
+			}
+		}
+		u.swept = now
+	}
+	b := u.bases[userID]
+	u.mu.Unlock()
+	if b != nil && now.Sub(b.loadedAt) < sharingAudienceTTL {
+		return b, nil
+	}
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s, err := u.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := u.groupRepo.GetByIDs(ctx, user.GroupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	b = &audienceBase{user: user, settings: s, members: make(map[primitive.ObjectID][]primitive.ObjectID, len(groups)), loadedAt: now}
+	for _, g := range groups {
+		b.members[g.ID] = g.MemberIDs
+	}
+	u.mu.Lock()
+	u.bases[userID] = b
+	u.mu.Unlock()
+	return b, nil
+}
+
+func (u *sharingUsecase) settings(ctx context.Context, userID string) (*domain.SharingSettings, error) {
+	s, err := u.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = domain.DefaultSharing(userID)
+	}
+	return s, nil
+}
+
+func (u *sharingUsecase) Get(ctx context.Context, userID string) (*domain.SharingSettings, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.settings(ctx, userID)
+}
+
+// Update đổi danh sách nhóm và độ chính xác; nhóm phải là nhóm user đang tham gia
+func (u *sharingUsecase) Update(ctx context.Context, userID string, groupIDs []primitive.ObjectID, precision string) (*domain.SharingSettings, error) {
+	if !domain.ValidPrecision(precision) {
+		return nil, domain.ErrInvalidSharing
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groupIDs {
+		if !containsID(user.GroupIDs, g) {
+			return nil, domain.ErrInvalidSharing
+		}
+	}
+
+	s, err := u.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if groupIDs == nil {
+		groupIDs = []primitive.ObjectID{}
+	}
+	s.GroupIDs, s.Precision, s.UpdatedAt = groupIDs, precision, time.Now().Unix()
+	if err := u.repo.Upsert(ctx, s); err != nil {
+		return nil, err
+	}
+	u.forget(userID)
+	return s, nil
+}
+
+func (u *sharingUsecase) Pause(ctx context.Context, userID string, d time.Duration) (*domain.SharingSettings, error) {
+	if d <= 0 || d > sharingMaxPause {
+		return nil, domain.ErrInvalidSharing
+	}
+	return u.setPause(ctx, userID, time.Now().Add(d).Unix())
+}
+
+func (u *sharingUsecase) Resume(ctx context.Context, userID string) (*domain.SharingSettings, error) {
+	return u.setPause(ctx, userID, 0)
+}
+
+func (u *sharingUsecase) setPause(ctx context.Context, userID string, until int64) (*domain.SharingSettings, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s, err := u.settings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.PausedUntil, s.UpdatedAt = until, time.Now().Unix()
+	if err := u.repo.Upsert(ctx, s); err != nil {
+		return nil, err
+	}
+	u.forget(userID)
+	return s, nil
+}
+
+// sharedGroups trả về các nhóm của user được nhận vị trí và độ chính xác áp dụng
+func (u *sharingUsecase) sharedGroups(ctx context.Context, user domain.User) ([]primitive.ObjectID, domain.SharingDecision, error) {
+	userID := user.ID.Hex()
+	s, err := u.settings(ctx, userID)
+	if err != nil {
+		return nil, domain.SharingDecision{}, err
+	}
+	sos, err := u.alertRepo.HasActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, domain.SharingDecision{}, err
+	}
+	groups, d := SharedGroups(s, user.GroupIDs, sos, time.Now())
+	return groups, d, nil
+}
+
+// SharedGroups: đang SOS thì mọi nhóm thấy vị trí chính xác; đang tạm dừng thì không nhóm nào;
+// còn lại là các nhóm được chọn (rỗng = mọi nhóm) với độ chính xác đã cấu hình
+func SharedGroups(s *domain.SharingSettings, userGroups []primitive.ObjectID, sos bool, now time.Time) ([]primitive.ObjectID, domain.SharingDecision) {
+	if sos {
+		return userGroups, domain.SharingDecision{Allowed: len(userGroups) > 0, Precision: domain.SharingExact, SOS: true}
+	}
+	if s.Paused(now) {
+		return nil, domain.SharingDecision{}
+	}
+
+	groups := userGroups
+	if len(s.GroupIDs) > 0 {
+		groups = nil
+		for _, g := range userGroups {
+			if containsID(s.GroupIDs, g) {
+				groups = append(groups, g)
+			}
+		}
+	}
+	precision := s.Precision
+	if !domain.ValidPrecision(precision) {
+		precision = domain.SharingExact
+	}
+	return groups, domain.SharingDecision{Allowed: len(groups) > 0, Precision: precision}
+}
+
+// Audience: thành viên các nhóm được chia sẻ (trừ chính user) và vị trí đã làm mờ
+func (u *sharingUsecase) Audience(ctx context.Context, userID string, loc *domain.Location) (*domain.LocationAudience, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	b, err := u.audienceBase(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	sos, err := u.alertRepo.HasActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	groups, d := SharedGroups(b.settings, b.user.GroupIDs, sos, time.Now())
+
+	audience := &domain.LocationAudience{Viewers: map[string]bool{}, Groups: map[string]primitive.ObjectID{}}
+	if !d.Allowed {
+		return audience, nil
+	}
+	for _, groupID := range groups {
+		for _, memberID := range b.members[groupID] {
+			if memberID == b.user.ID || audience.Viewers[memberID.Hex()] {
+				continue
+			}
+			audience.Viewers[memberID.Hex()] = true
+			audience.Groups[memberID.Hex()] = groupID
+		}
+	}
+	audience.Location = CoarsenLocation(loc, d.Precision)
+	return audience, nil
+}
+
+// Decide: viewer xem được vị trí của userID nếu cùng ít nhất một nhóm đang được chia sẻ
+func (u *sharingUsecase) Decide(ctx context.Context, viewerID, userID string) (domain.SharingDecision, error) {
+	if viewerID == userID {
+		return domain.SharingDecision{Allowed: true, Precision: domain.SharingExact}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return domain.SharingDecision{}, err
+	}
+	viewer, err := u.userRepo.GetByID(ctx, viewerID)
+	if err != nil {
+		return domain.SharingDecision{}, err
+	}
+	groups, d, err := u.sharedGroups(ctx, user)
+	if err != nil {
+		return domain.SharingDecision{}, err
+	}
+	for _, g := range groups {
+		if containsID(viewer.GroupIDs, g) {
+			return d, nil
+		}
+	}
+	return domain.SharingDecision{}, nil
+}
+
+func (u *sharingUsecase) DecideMany(ctx context.Context, viewer domain.User, users []domain.User) (map[string]domain.SharingDecision, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID.Hex()
+	}
+	settings, err := u.repo.GetMany(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[string]*domain.SharingSettings, len(settings))
+	for i := range settings {
+		byUser[settings[i].UserID] = &settings[i]
+	}
+	sosIDs, err := u.alertRepo.ActiveUserIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	sos := make(map[string]bool, len(sosIDs))
+	for _, id := range sosIDs {
+		sos[id] = true
+	}
+
+	now := time.Now()
+	out := make(map[string]domain.SharingDecision, len(users))
+	for i, user := range users {
+		id := ids[i]
+		if user.ID == viewer.ID {
+			out[id] = domain.SharingDecision{Allowed: true, Precision: domain.SharingExact}
+			continue
+		}
+		s := byUser[id]
+		if s == nil {
+			s = domain.DefaultSharing(id)
+		}
+		groups, d := SharedGroups(s, user.GroupIDs, sos[id], now)
+		out[id] = domain.SharingDecision{}
+		for _, g := range groups {
+			if containsID(viewer.GroupIDs, g) {
+				out[id] = d
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// CoarsenLocation trả về bản sao loc với tọa độ về tâm cell theo độ chính xác,
+// độ sai số tăng lên ít nhất bằng nửa đường chéo cell
+func CoarsenLocation(loc *domain.Location, precision string) *domain.Location {
+	out := *loc
+	out.Location.Coordinates, out.AccuracyM = CoarsenPoint(loc.Location.Coordinates, loc.AccuracyM, precision)
+	return &out
+}
+
+func CoarsenPoint(coords [2]float64, accuracyM float64, precision string) ([2]float64, float64) {
+	level, ok := precisionLevel[precision]
+	if !ok {
+		return coords, accuracyM
+	}
+	hash := geo.Encode(coords[1], coords[0], level)
+	minLat, minLon, maxLat, maxLon, _ := geo.Bounds(hash)
+	halfDiag := geo.DistanceMeters(minLat, minLon, maxLat, maxLon) / 2
+	return geo.CellCenter(hash), math.Max(accuracyM, halfDiag)
+}
+
+func containsID(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// các fake dùng chung cho test chia sẻ vị trí / nhóm; chỉ cài method được dùng
+type fakeSharingRepo struct {
+	domain.SharingRepository
+	byUser map[string]*domain.SharingSettings
+}
+
+func (f *fakeSharingRepo) Get(ctx context.Context, userID string) (*domain.SharingSettings, error) {
+	if s, ok := f.byUser[userID]; ok {
+		c := *s
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (f *fakeSharingRepo) GetMany(ctx context.Context, userIDs []string) ([]domain.SharingSettings, error) {
+	var out []domain.SharingSettings
+	for _, id := range userIDs {
+		if s, ok := f.byUser[id]; ok {
+			out = append(out, *s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSharingRepo) Upsert(ctx context.Context, s *domain.SharingSettings) error {
+	c := *s
+	f.byUser[s.UserID] = &c
+	return nil
+}
+
+type fakeUserRepo struct {
+	domain.UserRepository
+	users map[string]domain.User
+	gets  int
+}
+
+func (f *fakeUserRepo) GetByID(ctx context.Context, id string) (domain.User, error) {
+	f.gets++
+	u, ok := f.users[id]
+	if !ok {
+		return domain.User{}, mongo.ErrNoDocuments
+	}
+	return u, nil
+}
+
+func (f *fakeUserRepo) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.User, error) {
+	var out []domain.User
+	for _, id := range ids {
+		if u, ok := f.users[id.Hex()]; ok {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeUserRepo) RemoveGroup(ctx context.Context, userID, groupID primitive.ObjectID) error {
+	u := f.users[userID.Hex()]
+	u.GroupIDs = withoutID(u.GroupIDs, groupID)
+	f.users[userID.Hex()] = u
+	return nil
+}
+
+func (f *fakeUserRepo) RemoveGroupFromAll(ctx context.Context, groupID primitive.ObjectID) error {
+	for id, u := range f.users {
+		u.GroupIDs = withoutID(u.GroupIDs, groupID)
+		f.users[id] = u
+	}
+	return nil
+}
+
+func withoutID(ids []primitive.ObjectID, id primitive.ObjectID) []primitive.ObjectID {
+	out := []primitive.ObjectID{}
+	for _, x := range ids {
+		if x != id {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+type fakeGroupRepo struct {
+	domain.GroupRepository
+	groups map[primitive.ObjectID]domain.Group
+}
+
+func (f *fakeGroupRepo) GetByID(ctx context.Context, id primitive.ObjectID) (domain.Group, error) {
+	g, ok := f.groups[id]
+	if !ok {
+		return domain.Group{}, mongo.ErrNoDocuments
+	}
+	return g, nil
+}
+
+func (f *fakeGroupRepo) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]domain.Group, error) {
+	var out []domain.Group
+	for _, id := range ids {
+		if g, ok := f.groups[id]; ok {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeGroupRepo) RemoveMember(ctx context.Context, groupID, userID primitive.ObjectID) error {
+	g := f.groups[groupID]
+	g.MemberIDs = withoutID(g.MemberIDs, userID)
+	f.groups[groupID] = g
+	return nil
+}
+
+func (f *fakeGroupRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	delete(f.groups, id)
+	return nil
+}
+
+type fakeSOSRepo struct {
+	domain.AlertRepository
+	active map[string]bool
+}
+
+func (f *fakeSOSRepo) HasActiveByUser(ctx context.Context, userID string) (bool, error) {
+	return f.active[userID], nil
+}
+
+func (f *fakeSOSRepo) ActiveUserIDs(ctx context.Context, userIDs []string) ([]string, error) {
+	var out []string
+	for _, id := range userIDs {
+		if f.active[id] {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+// sharingFixture: alice, bob, carol cùng nhóm family; dave ở nhóm khác
+type sharingFixture struct {
+	users                   *fakeUserRepo
+	settings                *fakeSharingRepo
+	sos                     *fakeSOSRepo
+	groups                  *fakeGroupRepo
+	alice, bob, carol, dave domain.User
+	family, work            primitive.ObjectID
+}
+
+func newSharingFixture() *sharingFixture {
+	f := &sharingFixture{family: primitive.NewObjectID(), work: primitive.NewObjectID()}
+	mk := func(name string, groups ...primitive.ObjectID) domain.User {
+		return domain.User{ID: primitive.NewObjectID(), Name: name, GroupIDs: groups}
+	}
+	f.alice, f.bob, f.carol = mk("alice", f.family), mk("bob", f.family), mk("carol", f.family, f.work)
+	f.dave = mk("dave", f.work)
+
+	f.users = &fakeUserRepo{users: map[string]domain.User{}}
+	for _, u := range []domain.User{f.alice, f.bob, f.carol, f.dave} {
+		f.users.users[u.ID.Hex()] = u
+	}
+	f.groups = &fakeGroupRepo{groups: map[primitive.ObjectID]domain.Group{
+		f.family: {ID: f.family, Name: "family", MemberIDs: []primitive.ObjectID{f.alice.ID, f.bob.ID, f.carol.ID}},
+		f.work:   {ID: f.work, Name: "work", MemberIDs: []primitive.ObjectID{f.carol.ID, f.dave.ID}},
+	}}
+	f.settings = &fakeSharingRepo{byUser: map[string]*domain.SharingSettings{}}
+	f.sos = &fakeSOSRepo{active: map[string]bool{}}
+	return f
+}
+
+func (f *sharingFixture) usecase() domain.SharingUsecase {
+	return usecase.NewSharingUsecase(f.settings, f.users, f.groups, f.sos, time.Second)
+}
+
+func TestSharedGroups(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	family, work := primitive.NewObjectID(), primitive.NewObjectID()
+	userGroups := []primitive.ObjectID{family, work}
+
+	t.Run("default shares exact with every group", func(t *testing.T) {
+		groups, d := usecase.SharedGroups(domain.DefaultSharing("u1"), userGroups, false, now)
+		assert.Equal(t, userGroups, groups)
+		assert.True(t, d.Allowed)
+		assert.Equal(t, domain.SharingExact, d.Precision)
+	})
+
+	t.Run("selected groups and precision", func(t *testing.T) {
+		s := &domain.SharingSettings{GroupIDs: []primitive.ObjectID{family}, Precision: domain.SharingArea}
+		groups, d := usecase.SharedGroups(s, userGroups, false, now)
+		assert.Equal(t, []primitive.ObjectID{family}, groups)
+		assert.Equal(t, domain.SharingArea, d.Precision)
+	})
+
+	t.Run("paused shares with nobody", func(t *testing.T) {
+		s := &domain.SharingSettings{Precision: domain.SharingExact, PausedUntil: now.Add(time.Hour).Unix()}
+		groups, d := usecase.SharedGroups(s, userGroups, false, now)
+		assert.Empty(t, groups)
+		assert.False(t, d.Allowed)
+	})
+
+	t.Run("active SOS overrides pause, groups and precision", func(t *testing.T) {
+		s := &domain.SharingSettings{GroupIDs: []primitive.ObjectID{family}, Precision: domain.SharingArea, PausedUntil: now.Add(time.Hour).Unix()}
+		groups, d := usecase.SharedGroups(s, userGroups, true, now)
+		assert.Equal(t, userGroups, groups)
+		assert.True(t, d.SOS)
+		assert.Equal(t, domain.SharingExact, d.Precision)
+	})
+}
+
+func TestCoarsenPoint(t *testing.T) {
+	p := [2]float64{106.70123, 10.77654}
+
+	exact, acc := usecase.CoarsenPoint(p, 8, domain.SharingExact)
+	assert.Equal(t, p, exact)
+	assert.Equal(t, 8.0, acc)
+
+	approx, acc := usecase.CoarsenPoint(p, 8, domain.SharingApprox)
+	assert.LessOrEqual(t, geo.DistanceMeters(p[1], p[0], approx[1], approx[0]), acc)
+	assert.InDelta(t, 100, acc, 20)
+
+	area, acc := usecase.CoarsenPoint(p, 8, domain.SharingArea)
+	assert.LessOrEqual(t, geo.DistanceMeters(p[1], p[0], area[1], area[0]), acc)
+	assert.Greater(t, acc, 500.0)
+}
+
+func TestAudience(t *testing.T) {
+	f := newSharingFixture()
+	uc := f.usecase()
+	ctx := context.Background()
+	loc := &domain.Location{Location: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106.70123, 10.77654}}}
+
+	a, err := uc.Audience(ctx, f.carol.ID.Hex(), loc)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{f.alice.ID.Hex(): true, f.bob.ID.Hex(): true, f.dave.ID.Hex(): true}, a.Viewers)
+	assert.Equal(t, loc.Location.Coordinates, a.Location.Location.Coordinates)
+
+	// fix tiếp theo dùng lại user / nhóm đã tải
+	_, err = uc.Audience(ctx, f.carol.ID.Hex(), loc)
+	require.NoError(t, err)
+	assert.Equal(t, 1, f.users.gets)
+
+	// đổi cài đặt có hiệu lực ngay: chỉ nhóm work, làm mờ theo khu vực
+	_, err = uc.Update(ctx, f.carol.ID.Hex(), []primitive.ObjectID{f.work}, domain.SharingArea)
+	require.NoError(t, err)
+	a, err = uc.Audience(ctx, f.carol.ID.Hex(), loc)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{f.dave.ID.Hex(): true}, a.Viewers)
+	assert.NotEqual(t, loc.Location.Coordinates, a.Location.Location.Coordinates)
+
+	// tạm dừng: không ai nhận, trừ khi đang SOS
+	_, err = uc.Pause(ctx, f.carol.ID.Hex(), time.Hour)
+	require.NoError(t, err)
+	a, err = uc.Audience(ctx, f.carol.ID.Hex(), loc)
+	require.NoError(t, err)
+	assert.Empty(t, a.Viewers)
+
+	f.sos.active[f.carol.ID.Hex()] = true
+	a, err = uc.Audience(ctx, f.carol.ID.Hex(), loc)
+	require.NoError(t, err)
+	assert.Len(t, a.Viewers, 3)
+	assert.Equal(t, loc.Location.Coordinates, a.Location.Location.Coordinates)
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	shelterDefaultKm    = 20
+	shelterDefaultLimit = 5
+	shelterMaxLimit     = 50
+	// lấy dư ứng viên vì một phần sẽ bị loại (đầy, trong zone HIGH, thiếu tiện ích)
+	shelterCandidateFactor = 5
+	shelterMaxCandidates   = 250
+)
+
+type shelterUsecase struct {
+	repo     domain.ShelterRepository
+	zoneRepo domain.ZoneRepository
+	notifier domain.ShelterNotifier // nil = không đẩy realtime
+	timeout  time.Duration
+}
+
+func NewShelterUsecase(repo domain.ShelterRepository, zoneRepo domain.ZoneRepository, notifier domain.ShelterNotifier, timeout time.Duration) domain.ShelterUsecase {
+	return &shelterUsecase{
+		repo:     repo,
+		zoneRepo: zoneRepo,
+		notifier: notifier,
+		timeout:  timeout,
+	}
+}
+
+func (u *shelterUsecase) notify(eventType string, s *domain.Shelter) {
+	if u.notifier == nil {
+		return
+	}
+	u.notifier.NotifyShelter(domain.ShelterEvent{
+		Type:      eventType,
+		Shelter:   *s,
+		Available: s.Available(),
+		Full:      s.Available() == 0,
+	})
+}
+
+func (u *shelterUsecase) Create(ctx context.Context, s *domain.Shelter) error {
+	if !s.Valid() {
+		return domain.ErrInvalidShelter
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s.UpdatedAt = time.Now().Unix()
+	if err := u.repo.Create(ctx, s); err != nil {
+		return err
+	}
+	u.notify(domain.ShelterEventCreated, s)
+	return nil
+}
+
+func (u *shelterUsecase) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Shelter, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.repo.GetByID(ctx, id)
+}
+
+func (u *shelterUsecase) Update(ctx context.Context, s *domain.Shelter) error {
+	if !s.Valid() {
+		return domain.ErrInvalidShelter
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s.UpdatedAt = time.Now().Unix()
+	if err := u.repo.Update(ctx, s); err != nil {
+		return err
+	}
+	u.notify(domain.ShelterEventUpdated, s)
+	return nil
+}
+
+func (u *shelterUsecase) Delete(ctx context.Context, id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s, err := u.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	u.notify(domain.ShelterEventDeleted, s)
+	return nil
+}
+
+func (u *shelterUsecase) AdjustOccupancy(ctx context.Context, id primitive.ObjectID, delta int, updatedBy string) (*domain.Shelter, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s, err := u.repo.AdjustOccupancy(ctx, id, delta, updatedBy, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	u.notify(domain.ShelterEventOccupancy, s)
+	return s, nil
+}
+
+func (u *shelterUsecase) SetOccupancy(ctx context.Context, id primitive.ObjectID, occupancy int, updatedBy string) (*domain.Shelter, error) {
+	if occupancy < 0 {
+		return nil, domain.ErrInvalidShelter
+	}
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	s, err := u.repo.SetOccupancy(ctx, id, occupancy, updatedBy, time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	u.notify(domain.ShelterEventOccupancy, s)
+	return s, nil
+}
+
+func (u *shelterUsecase) FetchInBounds(ctx context.Context, b domain.BoundsFilter) ([]domain.Shelter, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+	return u.repo.FetchInBounds(ctx, b)
+}
+
+// Nearest lấy ứng viên theo $near rồi lọc trong bộ nhớ; zone chỉ query một lần cho cả nhóm ứng viên
+func (u *shelterUsecase) Nearest(ctx context.Context, q domain.ShelterQuery) ([]domain.NearestShelter, error) {
+	if q.MaxKm <= 0 {
+		q.MaxKm = shelterDefaultKm
+	}
+	if q.Limit <= 0 {
+		q.Limit = shelterDefaultLimit
+	}
+	q.Limit = min(q.Limit, shelterMaxLimit)
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	candidates, err := u.repo.FetchNearby(ctx, q.Lat, q.Lon, q.MaxKm*1000, min(q.Limit*shelterCandidateFactor, shelterMaxCandidates))
+	if err != nil {
+		return nil, err
+	}
+
+	var usable []domain.Shelter
+	for _, s := range candidates {
+		if !q.IncludeFull && s.Available() == 0 {
+			continue
+		}
+		if !hasAll(s.Accessibility, q.Accessibility) || !hasAll(s.Amenities, q.Amenities) {
+			continue
+		}
+		usable = append(usable, s)
+	}
+	if len(usable) == 0 {
+		return []domain.NearestShelter{}, nil
+	}
+
+	high, err := u.highZonesAround(ctx, usable)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]domain.NearestShelter, 0, q.Limit)
+	for _, s := range usable {
+		lat, lon := s.Location.Coordinates[1], s.Location.Coordinates[0]
+		if insideAny(high, lat, lon) {
+			continue
+		}
+		out = append(out, domain.NearestShelter{
+			Shelter:   s,
+			DistanceM: geo.DistanceMeters(q.Lat, q.Lon, lat, lon),
+			Available: s.Available(),
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].DistanceM < out[j].DistanceM })
+	if len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+// highZonesAround lấy zone HIGH trong khung bao các shelter
+func (u *shelterUsecase) highZonesAround(ctx context.Context, shelters []domain.Shelter) ([]domain.Zone, error) {
+	b := domain.BoundsFilter{MinLat: 90, MinLon: 180, MaxLat: -90, MaxLon: -180}
+	for _, s := range shelters {
+		lat, lon := s.Location.Coordinates[1], s.Location.Coordinates[0]
+		b.MinLat, b.MaxLat = math.Min(b.MinLat, lat), math.Max(b.MaxLat, lat)
+		b.MinLon, b.MaxLon = math.Min(b.MinLon, lon), math.Max(b.MaxLon, lon)
+	}
+
+	zones, err := u.zoneRepo.FetchInBounds(ctx, b.MinLat, b.MinLon, b.MaxLat, b.MaxLon)
+	if err != nil {
+		return nil, err
+	}
+	high := zones[:0]
+	for _, z := range zones {
+		if z.Label == "HIGH" {
+			high = append(high, z)
+		}
+	}
+	return high, nil
+}
+
+func insideAny(zones []domain.Zone, lat, lon float64) bool {
+	for _, z := range zones {
+		if geo.DistanceMeters(lat, lon, z.Center.Coordinates[1], z.Center.Coordinates[0]) <= z.Radius {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAll: have chứa mọi mục trong want (không phân biệt hoa thường)
+func hasAll(have, want []string) bool {
+	for _, w := range want {
+		found := false
+		for _, h := range have {
+			if strings.EqualFold(h, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeShelterRepo struct {
+	domain.ShelterRepository
+	nearby []domain.Shelter
+}
+
+func (f fakeShelterRepo) FetchNearby(ctx context.Context, lat, lon, maxM float64, limit int) ([]domain.Shelter, error) {
+	return f.nearby, nil
+}
+
+func shelterAt(name string, lat, lon float64, capacity, occupancy int) domain.Shelter {
+	return domain.Shelter{
+		Name:      name,
+		Location:  domain.GeoPoint{Type: "Point", Coordinates: [2]float64{lon, lat}},
+		Capacity:  capacity,
+		Occupancy: occupancy,
+		Status:    domain.ShelterOpen,
+	}
+}
+
+func TestShelterNearestSkipsHighZonesAndFull(t *testing.T) {
+	shelters := []domain.Shelter{
+		shelterAt("school", 10.001, 106, 100, 10),  // trong zone HIGH
+		shelterAt("stadium", 10.0025, 106, 50, 50), // đã đầy
+		shelterAt("temple", 10.005, 106, 30, 0),    // trong zone MEDIUM, vẫn dùng được
+		shelterAt("hall", 10.003, 106, 20, 5),
+	}
+	zones := []domain.Zone{
+		{Center: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106, 10.001}}, Radius: 150, RiskScore: 0.9, Label: "HIGH"},
+		{Center: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106, 10.005}}, Radius: 150, RiskScore: 0.4, Label: "MEDIUM"},
+	}
+	uc := usecase.NewShelterUsecase(fakeShelterRepo{nearby: shelters}, routeZoneRepo{zones: zones}, nil, time.Second)
+
+	out, err := uc.Nearest(context.Background(), domain.ShelterQuery{Lat: 10, Lon: 106})
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, s := range out {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"hall", "temple"}, names)
+	assert.Equal(t, 15, out[0].Available)
+
+	// includeFull giữ shelter đầy nhưng vẫn bỏ shelter trong zone HIGH
+	out, err = uc.Nearest(context.Background(), domain.ShelterQuery{Lat: 10, Lon: 106, IncludeFull: true})
+	require.NoError(t, err)
+	assert.Len(t, out, 3)
+	assert.Equal(t, "stadium", out[0].Name)
+}
+
+func TestShelterCreateRejectsInvalidLatLon(t *testing.T) {
+	uc := usecase.NewShelterUsecase(fakeShelterRepo{}, routeZoneRepo{}, nil, time.Second)
+
+	s := shelterAt("school", 95, 106, 100, 0)
+	assert.ErrorIs(t, uc.Create(context.Background(), &s), domain.ErrInvalidShelter)
+
+	s = shelterAt("school", 10, 190, 100, 0)
+	assert.ErrorIs(t, uc.Create(context.Background(), &s), domain.ErrInvalidShelter)
+}
@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
+)
+
+const staleBatchSize = 500
+
+// StalePolicy: im lặng bao lâu thì coi vị trí là cũ.
+// Người đang DANGER hoặc ở trong zone HIGH bị coi là mất tín hiệu sớm hơn, người SAFE muộn hơn.
+type StalePolicy struct {
+	Default time.Duration
+	Safe    time.Duration
+	Urgent  time.Duration // status DANGER hoặc đang trong zone HIGH
+}
+
+// Timeout theo status cuối và việc fix cuối có nằm trong zone HIGH không
+func (p StalePolicy) Timeout(status string, inHighZone bool) time.Duration {
+	switch {
+	case status == "DANGER" || inHighZone:
+		return p.Urgent
+	case status == "SAFE":
+		return p.Safe
+	default:
+		return p.Default
+	}
+}
+
+// StaleDetector chuyển user im lặng quá lâu sang UNKNOWN và báo cho nhóm qua "member_stale"
+type StaleDetector struct {
+	repo      domain.LocationRepository
+	zoneRepo  domain.ZoneRepository
+	userRepo  domain.UserRepository
+	groupRepo domain.GroupRepository
+	ws        *ws.WSManager
+	policy    StalePolicy
+	timeout   time.Duration
+}
+
+func NewStaleDetector(repo domain.LocationRepository, zoneRepo domain.ZoneRepository, userRepo domain.UserRepository,
+	groupRepo domain.GroupRepository, wsm *ws.WSManager, policy StalePolicy, timeout time.Duration) *StaleDetector {
+	return &StaleDetector{
+		repo:      repo,
+		zoneRepo:  zoneRepo,
+		userRepo:  userRepo,
+		groupRepo: groupRepo,
+		ws:        wsm,
+		policy:    policy,
+		timeout:   timeout,
+	}
+}
+
+// MarkStale chạy một lượt, trả về số user bị chuyển sang UNKNOWN.
+// Mỗi nhóm timeout một query với mốc riêng nên mọi location lấy ra đều đã quá hạn; lấy tiếp tới khi hết.
+func (d *StaleDetector) MarkStale(ctx context.Context, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	before := func(status string, inHighZone bool) int64 {
+		return now.Add(-d.policy.Timeout(status, inHighZone)).Unix()
+	}
+	queries := []domain.SilentQuery{
+		{Statuses: []string{"DANGER"}, Before: before("DANGER", false)},
+		{Statuses: []string{"SAFE"}, Before: before("SAFE", false)},
+		{ExcludeStatuses: []string{"DANGER", "SAFE"}, Before: before("", false)},
+	}
+
+	// trong zone HIGH: một query theo vùng cho mỗi zone thay vì tra zone cho từng ứng viên
+	zones, err := d.zoneRepo.FetchAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, z := range zones {
+		if z.Label != "HIGH" {
+			continue
+		}
+		queries = append(queries, domain.SilentQuery{
+			ExcludeStatuses: []string{"DANGER"},
+			Before:          before("", true),
+			Lat:             z.Center.Coordinates[1],
+			Lon:             z.Center.Coordinates[0],
+			RadiusM:         z.Radius,
+		})
+	}
+
+	marked := 0
+	for _, q := range queries {
+		for {
+			candidates, err := d.repo.FetchSilent(ctx, q, staleBatchSize)
+			if err != nil {
+				return marked, err
+			}
+			n, err := d.markAll(ctx, candidates, now)
+			marked += n
+			if err != nil {
+				return marked, err
+			}
+			// trang chưa đầy là hết; không chuyển được ai thì dừng để không lặp mãi
+			if len(candidates) < staleBatchSize || n == 0 {
+				break
+			}
+		}
+	}
+	return marked, nil
+}
+
+func (d *StaleDetector) markAll(ctx context.Context, candidates []domain.Location, now time.Time) (int, error) {
+	marked := 0
+	for i := range candidates {
+		loc := &candidates[i]
+		ok, err := d.repo.MarkStale(ctx, loc.ID, loc.UpdatedAt, now.Unix())
+		if err != nil {
+			return marked, err
+		}
+		if !ok {
+			continue // vừa có fix mới
+		}
+		marked++
+
+		loc.LastStatus, loc.Status, loc.StaleSince = loc.Status, domain.LocationUnknown, now.Unix()
+		d.ws.BroadcastLocation(loc.ID, loc)
+		d.notifyGroups(ctx, loc)
+	}
+	return marked, nil
+}
+
+// notifyGroups gửi "member_stale" cho thành viên các nhóm của user, không kèm tọa độ
+func (d *StaleDetector) notifyGroups(ctx context.Context, loc *domain.Location) {
+	user, err := d.userRepo.GetByID(ctx, loc.ID)
+	if err != nil {
+		log.Println("stale location: load user failed:", err)
+		return
+	}
+
+	for _, groupID := range user.GroupIDs {
+		group, err := d.groupRepo.GetByID(ctx, groupID)
+		if err != nil {
+			continue
+		}
+		e := domain.StaleEvent{
+			GroupID:    groupID.Hex(),
+			UserID:     loc.ID,
+			UserName:   user.Name,
+			LastStatus: loc.LastStatus,
+			LastSeen:   loc.UpdatedAt,
+			StaleSince: loc.StaleSince,
+		}
+		for _, memberID := range group.MemberIDs {
+			if memberID != user.ID {
+				d.ws.SendToUser(memberID.Hex(), "member_stale", e)
+			}
+		}
+	}
+}
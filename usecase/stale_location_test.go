@@ -0,0 +1,137 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/ws"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staleLocationRepo lọc location trong bộ nhớ giống query FetchSilent
+type staleLocationRepo struct {
+	domain.LocationRepository
+	stored map[string]*domain.Location
+}
+
+func has(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *staleLocationRepo) FetchSilent(ctx context.Context, q domain.SilentQuery, limit int) ([]domain.Location, error) {
+	var out []domain.Location
+	for _, loc := range f.stored {
+		switch {
+		case loc.Status == domain.LocationUnknown, has(q.ExcludeStatuses, loc.Status):
+			continue
+		case len(q.Statuses) > 0 && !has(q.Statuses, loc.Status):
+			continue
+		case loc.UpdatedAt >= q.Before:
+			continue
+		case q.RadiusM > 0 && geo.DistanceMeters(q.Lat, q.Lon, loc.Location.Coordinates[1], loc.Location.Coordinates[0]) > q.RadiusM:
+			continue
+		}
+		out = append(out, *loc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UpdatedAt < out[j].UpdatedAt })
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (f *staleLocationRepo) MarkStale(ctx context.Context, userID string, updatedAt, staleSince int64) (bool, error) {
+	loc := f.stored[userID]
+	if loc == nil || loc.UpdatedAt != updatedAt || loc.Status == domain.LocationUnknown {
+		return false, nil
+	}
+	loc.LastStatus, loc.Status, loc.StaleSince = loc.Status, domain.LocationUnknown, staleSince
+	return true, nil
+}
+
+type staleZoneRepo struct {
+	domain.ZoneRepository
+	zones []domain.Zone
+}
+
+func (f staleZoneRepo) FetchAll(ctx context.Context) ([]domain.Zone, error) {
+	return f.zones, nil
+}
+
+// staleUserRepo: user không thuộc nhóm nào
+type staleUserRepo struct{ domain.UserRepository }
+
+func (staleUserRepo) GetByID(ctx context.Context, id string) (domain.User, error) {
+	return domain.User{}, nil
+}
+
+var stalePolicy = usecase.StalePolicy{Default: 2 * time.Hour, Safe: 6 * time.Hour, Urgent: 30 * time.Minute}
+
+func TestStalePolicyTimeout(t *testing.T) {
+	assert.Equal(t, 30*time.Minute, stalePolicy.Timeout("DANGER", false))
+	assert.Equal(t, 30*time.Minute, stalePolicy.Timeout("SAFE", true))
+	assert.Equal(t, 6*time.Hour, stalePolicy.Timeout("SAFE", false))
+	assert.Equal(t, 2*time.Hour, stalePolicy.Timeout("CAUTION", false))
+	assert.Equal(t, 2*time.Hour, stalePolicy.Timeout("", false))
+}
+
+func TestStaleDetectorMarkStale(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ago := func(d time.Duration) int64 { return now.Add(-d).Unix() }
+	at := func(id, status string, lat float64, updatedAt int64) *domain.Location {
+		return &domain.Location{
+			ID: id, Status: status, UpdatedAt: updatedAt,
+			Location: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106, lat}},
+		}
+	}
+
+	repo := &staleLocationRepo{stored: map[string]*domain.Location{}}
+	// nhiều user SAFE im lặng 3h (chưa quá hạn) xếp trước, không được chặn các user khác
+	for i := 0; i < 1200; i++ {
+		id := fmt.Sprintf("safe-%d", i)
+		repo.stored[id] = at(id, "SAFE", 11, ago(3*time.Hour+time.Duration(i)*time.Second))
+	}
+	repo.stored["danger"] = at("danger", "DANGER", 11, ago(40*time.Minute))
+	repo.stored["caution"] = at("caution", "CAUTION", 11, ago(150*time.Minute))
+	repo.stored["in-high"] = at("in-high", "SAFE", 10, ago(40*time.Minute))
+	repo.stored["fresh"] = at("fresh", "CAUTION", 10, ago(10*time.Minute))
+	// quá hạn nhiều hơn một trang: phải lấy hết
+	for i := 0; i < 700; i++ {
+		id := fmt.Sprintf("gone-%d", i)
+		repo.stored[id] = at(id, "SAFE", 11, ago(7*time.Hour))
+	}
+
+	zones := staleZoneRepo{zones: []domain.Zone{
+		{Center: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106, 10}}, Radius: 500, Label: "HIGH"},
+		{Center: domain.GeoPoint{Type: "Point", Coordinates: [2]float64{106, 11}}, Radius: 500, Label: "MEDIUM"},
+	}}
+	d := usecase.NewStaleDetector(repo, zones, staleUserRepo{}, nil, ws.NewWSManager(), stalePolicy, time.Second)
+
+	marked, err := d.MarkStale(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 703, marked)
+
+	for _, id := range []string{"danger", "caution", "in-high", "gone-0", "gone-699"} {
+		assert.Equal(t, domain.LocationUnknown, repo.stored[id].Status, id)
+	}
+	assert.Equal(t, "DANGER", repo.stored["danger"].LastStatus)
+	assert.Equal(t, "CAUTION", repo.stored["fresh"].Status)
+	assert.Equal(t, "SAFE", repo.stored["safe-0"].Status)
+
+	// lượt sau không còn gì để chuyển
+	marked, err = d.MarkStale(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, marked)
+}
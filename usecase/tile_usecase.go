@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/tile"
+)
+
+const (
+	tileBuffer = 1.0 / 16 // lấy thêm dữ liệu quanh tile để cụm / polygon ở mép không bị cắt cụt
+
+	// cell / zone được xóa cache khi thay đổi nên giữ lâu; report / alert chỉ dựa vào TTL
+	tileTTLInvalidated = 10 * time.Minute
+	tileTTLShort       = 30 * time.Second
+	tileMaxAge         = 30 * time.Second
+
+	clusterSize    = tile.Extent / 16 // ~16px trên tile 256px
+	noClusterZoom  = 17               // từ zoom này hiện từng điểm
+	maxCellsInTile = 64               // số cell tối đa theo chiều ngang của tile
+)
+
+type tileUsecase struct {
+	cellRepo   domain.CellRepository
+	zoneRepo   domain.ZoneRepository
+	reportRepo domain.ReportRepository
+	alertRepo  domain.AlertRepository
+	cache      *tile.Cache
+	timeout    time.Duration
+}
+
+func NewTileUsecase(cellRepo domain.CellRepository, zoneRepo domain.ZoneRepository, reportRepo domain.ReportRepository,
+	alertRepo domain.AlertRepository, cache *tile.Cache, timeout time.Duration) domain.TileUsecase {
+	return &tileUsecase{
+		cellRepo:   cellRepo,
+		zoneRepo:   zoneRepo,
+		reportRepo: reportRepo,
+		alertRepo:  alertRepo,
+		cache:      cache,
+		timeout:    timeout,
+	}
+}
+
+// Tile trả tile từ cache hoặc dựng mới
+func (u *tileUsecase) Tile(ctx context.Context, layer, format string, z, x, y int) (*domain.Tile, error) {
+	switch layer {
+	case domain.TileLayerCells, domain.TileLayerZones, domain.TileLayerReports, domain.TileLayerAlerts:
+	default:
+		return nil, domain.ErrUnknownTileLayer
+	}
+	if format != domain.TileFormatMVT && format != domain.TileFormatPNG {
+		return nil, domain.ErrUnsupportedTileFormat
+	}
+	if !tile.Valid(z, x, y) {
+		return nil, domain.ErrInvalidTile
+	}
+
+	key := fmt.Sprintf("%s/%s/%d/%d/%d", layer, format, z, x, y)
+	if t, ok := u.cache.Get(key); ok {
+		return t, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, u.timeout)
+	defer cancel()
+
+	minLat, minLon, maxLat, maxLon := tile.Bounds(z, x, y, tileBuffer)
+	b := domain.BoundsFilter{MinLat: minLat, MinLon: minLon, MaxLat: maxLat, MaxLon: maxLon}
+	proj := tile.NewProjector(z, x, y)
+
+	var (
+		features []tile.Feature
+		spots    []tile.Spot
+		err      error
+	)
+	switch layer {
+	case domain.TileLayerCells:
+		features, spots, err = u.cellFeatures(ctx, b, z, proj)
+	case domain.TileLayerZones:
+		features, spots, err = u.zoneFeatures(ctx, b, z, proj)
+	case domain.TileLayerReports:
+		features, spots, err = u.reportFeatures(ctx, b, z, proj)
+	case domain.TileLayerAlerts:
+		features, spots, err = u.alertFeatures(ctx, b, z, proj)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := domain.Tile{MaxAge: tileMaxAge}
+	if format == domain.TileFormatPNG {
+		t.ContentType = "image/png"
+		if t.Data, err = tile.RenderHeatmap(spots); err != nil {
+			return nil, err
+		}
+	} else {
+		t.ContentType = "application/vnd.mapbox-vector-tile"
+		t.Data = tile.EncodeMVT(tile.Layer{Name: layer, Features: features})
+	}
+	sum := sha1.Sum(t.Data)
+	t.ETag = `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	ttl := tileTTLShort
+	if layer == domain.TileLayerCells || layer == domain.TileLayerZones {
+		ttl = tileTTLInvalidated
+	}
+	u.cache.Put(key, layer, b, t, ttl)
+	return &t, nil
+}
+
+// cellLevelForZoom chọn level lưới sao cho mỗi tile có không quá maxCellsInTile cell theo chiều ngang
+func cellLevelForZoom(z int) int {
+	tileWidth := 360 / math.Pow(2, float64(z))
+	level := domain.MinCellLevel
+	for l := domain.MinCellLevel; l <= domain.CellLevel; l++ {
+		_, lonDeg := geo.CellSize(l)
+		if tileWidth/lonDeg > maxCellsInTile {
+			break
+		}
+		level = l
+	}
+	return level
+}
+
+func (u *tileUsecase) cellFeatures(ctx context.Context, b domain.BoundsFilter, z int, proj tile.Projector) ([]tile.Feature, []tile.Spot, error) {
+	level := cellLevelForZoom(z)
+	cells, err := u.cellRepo.AggregateInBounds(ctx, b, level)
+	if err != nil {
+		return nil, nil, err
+	}
+	zones, err := u.zoneRepo.FetchInBounds(ctx, b.MinLat, b.MinLon, b.MaxLat, b.MaxLon)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		features []tile.Feature
+		spots    []tile.Spot
+	)
+	for _, c := range ProjectZones(cells, zones, b, level) {
+		minLat, minLon, maxLat, maxLon, ok := geo.Bounds(c.Hash)
+		if !ok {
+			continue
+		}
+		x0, y0 := proj.Point(maxLat, minLon)
+		x1, y1 := proj.Point(minLat, maxLon)
+		features = append(features, tile.Feature{
+			Type:     tile.GeomPolygon,
+			Geometry: [][][2]float64{{{x0, y0}, {x1, y0}, {x1, y1}, {x0, y1}, {x0, y0}}},
+			Props: map[string]interface{}{
+				"hash":      c.Hash,
+				"level":     c.Level,
+				"riskScore": c.RiskScore,
+				"cellRisk":  c.CellRisk,
+				"zoneRisk":  c.ZoneRisk,
+				"count":     c.Count,
+				"label":     c.Label,
+			},
+		})
+		spots = append(spots, tile.Spot{
+			X: (x0 + x1) / 2, Y: (y0 + y1) / 2, Radius: math.Abs(x1-x0) / 2, Weight: math.Min(c.RiskScore, 1),
+		})
+	}
+	return features, spots, nil
+}
+
+func (u *tileUsecase) zoneFeatures(ctx context.Context, b domain.BoundsFilter, z int, proj tile.Projector) ([]tile.Feature, []tile.Spot, error) {
+	zones, err := u.zoneRepo.FetchInBounds(ctx, b.MinLat, b.MinLon, b.MaxLat, b.MaxLon)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	segments := 16
+	if z >= 12 {
+		segments = 48
+	}
+
+	var (
+		features []tile.Feature
+		spots    []tile.Spot
+	)
+	for _, zn := range zones {
+		lat, lon := zn.Center.Coordinates[1], zn.Center.Coordinates[0]
+		cx, cy := proj.Point(lat, lon)
+
+		ring := geo.CircleRing(lon, lat, zn.Radius, segments)
+		projected := make([][2]float64, len(ring))
+		for i, p := range ring {
+			px, py := proj.Point(p[1], p[0])
+			projected[i] = [2]float64{px, py}
+		}
+
+		props := map[string]interface{}{
+			"id":        zn.ID.Hex(),
+			"riskScore": zn.RiskScore,
+			"label":     zn.Label,
+			"hazard":    zn.Hazard,
+			"declared":  zn.Declared,
+			"locked":    zn.Locked,
+		}
+		radius := zn.Radius / proj.MetersPerUnit(lat)
+		if radius < 2 {
+			// zone nhỏ hơn 1 đơn vị tile ở zoom này: vẽ thành điểm
+			features = append(features, tile.Feature{Type: tile.GeomPoint, Geometry: [][][2]float64{{{cx, cy}}}, Props: props})
+		} else {
+			features = append(features, tile.Feature{Type: tile.GeomPolygon, Geometry: [][][2]float64{projected}, Props: props})
+		}
+		spots = append(spots, tile.Spot{X: cx, Y: cy, Radius: radius, Weight: math.Min(zn.RiskScore, 1)})
+	}
+	return features, spots, nil
+}
+
+func (u *tileUsecase) reportFeatures(ctx context.Context, b domain.BoundsFilter, z int, proj tile.Projector) ([]tile.Feature, []tile.Spot, error) {
+	reports, err := u.reportRepo.FetchActiveInBounds(ctx, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	points := make([]tile.Point, 0, len(reports))
+	for _, r := range reports {
+		px, py := proj.Point(r.Location.Coordinates[1], r.Location.Coordinates[0])
+		urgency := ""
+		if r.Enrichment != nil {
+			urgency = r.Enrichment.Urgency
+		}
+		points = append(points, tile.Point{
+			X: px, Y: py, Weight: convertUrgencyToRisk(urgency), ID: r.ID.Hex(),
+			Props: map[string]interface{}{"type": r.Type, "urgency": urgency, "status": r.Status},
+		})
+	}
+	features, spots := clusterFeatures(points, z)
+	return features, spots, nil
+}
+
+func (u *tileUsecase) alertFeatures(ctx context.Context, b domain.BoundsFilter, z int, proj tile.Projector) ([]tile.Feature, []tile.Spot, error) {
+	alerts, err := u.alertRepo.FetchActiveInBounds(ctx, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	points := make([]tile.Point, 0, len(alerts))
+	for _, a := range alerts {
+		lat := a.Location.Coordinates[1]
+		px, py := proj.Point(lat, a.Location.Coordinates[0])
+		points = append(points, tile.Point{
+			X: px, Y: py, Weight: 1, ID: a.ID.Hex(),
+			Props: map[string]interface{}{"radius_m": a.RadiusM, "status": a.Status},
+		})
+	}
+	features, spots := clusterFeatures(points, z)
+	return features, spots, nil
+}
+
+// clusterFeatures gom điểm theo zoom: cụm có count > 1, điểm lẻ giữ id và thuộc tính gốc
+func clusterFeatures(points []tile.Point, z int) ([]tile.Feature, []tile.Spot) {
+	size := float64(clusterSize)
+	if z >= noClusterZoom {
+		size = 0
+	}
+
+	var (
+		features []tile.Feature
+		spots    []tile.Spot
+	)
+	for _, c := range tile.ClusterPoints(points, size) {
+		props := map[string]interface{}{"count": c.Count, "maxWeight": c.MaxWeight}
+		if c.Count == 1 {
+			props["id"] = c.Point.ID
+			for k, v := range c.Point.Props {
+				props[k] = v
+			}
+		}
+		features = append(features, tile.Feature{Type: tile.GeomPoint, Geometry: [][][2]float64{{{c.X, c.Y}}}, Props: props})
+		// cụm lớn vẽ rộng hơn
+		spots = append(spots, tile.Spot{X: c.X, Y: c.Y, Radius: 160 * math.Sqrt(float64(c.Count)), Weight: c.MaxWeight})
+	}
+	return features, spots
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/internal/geo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PlanZoneMerges tìm các nhóm zone chồng lấn nhau quá ngưỡng và tính zone thay thế.
+// overlap: tỉ lệ diện tích giao / diện tích zone nhỏ hơn (0..1).
+// maxRadius: không gộp nếu vòng tròn bao kết quả lớn hơn (m), 0 = không giới hạn.
+// Zone người điều phối giữ (Locked / Override) giữ nguyên, không tham gia gộp.
+func PlanZoneMerges(zones []domain.Zone, overlap, maxRadius float64) []domain.ZoneMerge {
+	sorted := make([]domain.Zone, 0, len(zones))
+	for _, z := range zones {
+		if !z.Pinned() {
+			sorted = append(sorted, z)
+		}
+	}
+	// zone risk cao nhất làm gốc, các thuộc tính decay lấy theo nó
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].RiskScore > sorted[j].RiskScore })
+
+	consumed := make([]bool, len(sorted))
+	var merges []domain.ZoneMerge
+
+	for i := range sorted {
+		if consumed[i] {
+			continue
+		}
+		cur := sorted[i]
+		sources := []domain.Zone{sorted[i]}
+
+		// zone gộp lớn dần nên lặp lại tới khi không gộp thêm được
+		for changed := true; changed; {
+			changed = false
+			for j := i + 1; j < len(sorted); j++ {
+				if consumed[j] || circleOverlapRatio(cur, sorted[j]) < overlap {
+					continue
+				}
+				center, radius := enclosingCircle(cur, sorted[j])
+				if maxRadius > 0 && radius > maxRadius {
+					continue
+				}
+
+				cur.Center.Coordinates = center
+				cur.Radius = radius
+				cur.Declared = cur.Declared || sorted[j].Declared
+				sources = append(sources, sorted[j])
+				consumed[j] = true
+				changed = true
+			}
+		}
+
+		if len(sources) > 1 {
+			consumed[i] = true
+			cur.Label = domain.RiskLabel(cur.RiskScore)
+			merges = append(merges, domain.ZoneMerge{Zone: cur, Sources: sources})
+		}
+	}
+	return merges
+}
+
+// circleOverlapRatio = diện tích phần giao / diện tích vòng nhỏ hơn
+func circleOverlapRatio(a, b domain.Zone) float64 {
+	r1, r2 := a.Radius, b.Radius
+	if r1 <= 0 || r2 <= 0 {
+		return 0
+	}
+	d := geo.DistanceMeters(a.Center.Coordinates[1], a.Center.Coordinates[0], b.Center.Coordinates[1], b.Center.Coordinates[0])
+	small := math.Min(r1, r2)
+
+	switch {
+	case d >= r1+r2:
+		return 0
+	case d <= math.Abs(r1-r2):
+		return 1
+	}
+
+	// diện tích hình thấu kính giao nhau của 2 hình tròn
+	area := r1*r1*math.Acos((d*d+r1*r1-r2*r2)/(2*d*r1)) +
+		r2*r2*math.Acos((d*d+r2*r2-r1*r1)/(2*d*r2)) -
+		0.5*math.Sqrt((-d+r1+r2)*(d+r1-r2)*(d-r1+r2)*(d+r1+r2))
+	return area / (math.Pi * small * small)
+}
+
+// enclosingCircle trả về vòng tròn nhỏ nhất bao cả 2 zone ([lon, lat], bán kính m)
+func enclosingCircle(a, b domain.Zone) ([2]float64, float64) {
+	lat1, lon1 := a.Center.Coordinates[1], a.Center.Coordinates[0]
+	lat2, lon2 := b.Center.Coordinates[1], b.Center.Coordinates[0]
+	d := geo.DistanceMeters(lat1, lon1, lat2, lon2)
+
+	if d+b.Radius <= a.Radius {
+		return a.Center.Coordinates, a.Radius
+	}
+	if d+a.Radius <= b.Radius {
+		return b.Center.Coordinates, b.Radius
+	}
+
+	radius := (d + a.Radius + b.Radius) / 2
+	// tâm nằm trên đoạn nối 2 tâm, cách tâm a một đoạn (radius - a.Radius)
+	t := (radius - a.Radius) / d
+	return [2]float64{lon1 + (lon2-lon1)*t, lat1 + (lat2-lat1)*t}, radius
+}
+
+// Coalesce gộp các zone chồng lấn, lưu lineage và xóa zone cũ.
+// Lần gộp bị ngắt trước đó (lineage chưa applied) được làm tiếp trước.
+func (zu *zoneUsecase) Coalesce(ctx context.Context, overlap, maxRadius float64) ([]domain.ZoneMerge, error) {
+	ctx2, cancel := context.WithTimeout(ctx, zu.contextTimeout)
+	defer cancel()
+
+	pending, err := zu.zoneRepository.FetchPendingLineages(ctx2)
+	if err != nil {
+		return nil, err
+	}
+	done := make([]domain.ZoneMerge, 0, len(pending))
+	for i := range pending {
+		l := &pending[i]
+		if err := zu.finishMerge(ctx2, l); err != nil {
+			return done, err
+		}
+		done = append(done, domain.ZoneMerge{Zone: l.Zone, Sources: l.Sources})
+	}
+
+	zones, err := zu.zoneRepository.FetchAll(ctx2)
+	if err != nil {
+		return done, err
+	}
+
+	for _, m := range PlanZoneMerges(zones, overlap, maxRadius) {
+		m, err := zu.applyMerge(ctx2, m)
+		if err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// coalesceAround gộp zone vừa tạo với các zone lân cận (chế độ inline),
+// chỉ thực hiện các lần gộp có chứa zone đó
+func (zu *zoneUsecase) coalesceAround(ctx context.Context, z *domain.Zone) error {
+	reach := z.Radius + zu.merge.MaxRadius
+	if zu.merge.MaxRadius <= 0 {
+		reach = z.Radius + defaultInlineMergeReach
+	}
+	minLat, minLon, maxLat, maxLon := geo.BoundsAround(z.Center.Coordinates[1], z.Center.Coordinates[0], reach)
+
+	zones, err := zu.zoneRepository.FetchInBounds(ctx, minLat, minLon, maxLat, maxLon)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range PlanZoneMerges(zones, zu.merge.Overlap, zu.merge.MaxRadius) {
+		if !mergeIncludes(m, z.ID) {
+			continue
+		}
+		if _, err := zu.applyMerge(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// khi không giới hạn bán kính gộp, inline chỉ xét zone trong phạm vi này (m)
+const defaultInlineMergeReach = 5000
+
+func mergeIncludes(m domain.ZoneMerge, id primitive.ObjectID) bool {
+	for _, s := range m.Sources {
+		if s.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// applyMerge lưu lineage (chưa applied) trước rồi mới xóa / tạo zone,
+// nên nếu bị ngắt giữa chừng thì lượt Coalesce sau làm tiếp từ lineage
+func (zu *zoneUsecase) applyMerge(ctx context.Context, m domain.ZoneMerge) (domain.ZoneMerge, error) {
+	merged := m.Zone
+	merged.ID = primitive.NewObjectID()
+	m.Zone = merged
+
+	ids := make([]primitive.ObjectID, len(m.Sources))
+	for i, s := range m.Sources {
+		ids[i] = s.ID
+	}
+
+	lineage := &domain.ZoneLineage{
+		ZoneID:    merged.ID,
+		SourceIDs: ids,
+		Sources:   m.Sources,
+		Zone:      merged,
+		MergedAt:  time.Now().UnixMilli(),
+	}
+	if err := zu.zoneRepository.AddLineage(ctx, lineage); err != nil {
+		return m, err
+	}
+	return m, zu.finishMerge(ctx, lineage)
+}
+
+// finishMerge áp dụng lineage rồi ghi history và báo "merged"
+func (zu *zoneUsecase) finishMerge(ctx context.Context, l *domain.ZoneLineage) error {
+	if err := zu.zoneRepository.ApplyLineage(ctx, l); err != nil {
+		return err
+	}
+
+	// history của zone mới bắt đầu từ lúc gộp, trỏ về lineage
+	mergeCtx := domain.WithZoneCause(ctx, domain.ZoneCause{Type: domain.ZoneCauseMerge, RefID: l.ID.Hex()})
+	merged := l.Zone
+	zu.record(mergeCtx, &merged, 0, "")
+
+	replaced := make([]string, len(l.SourceIDs))
+	for i, id := range l.SourceIDs {
+		replaced[i] = id.Hex()
+	}
+	zu.notify(mergeCtx, domain.ZoneEvent{Type: domain.ZoneEventMerged, Zone: merged, Replaced: replaced})
+	return nil
+}
@@ -0,0 +1,60 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/usecase"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func zoneAt(lat, lon, radius, risk float64) domain.Zone {
+	return domain.Zone{
+		ID:        primitive.NewObjectID(),
+		Center:    domain.GeoPoint{Type: "Point", Coordinates: [2]float64{lon, lat}},
+		Radius:    radius,
+		RiskScore: risk,
+	}
+}
+
+func TestPlanZoneMerges(t *testing.T) {
+	// ~111m mỗi 0.001 độ lat
+	a := zoneAt(10.000, 106.7, 300, 0.4)
+	b := zoneAt(10.001, 106.7, 300, 0.9) // chồng lấn nhiều với a
+	far := zoneAt(10.100, 106.7, 300, 0.5)
+
+	t.Run("merges overlapping zones", func(t *testing.T) {
+		merges := usecase.PlanZoneMerges([]domain.Zone{a, b, far}, 0.5, 0)
+
+		assert.Len(t, merges, 1)
+		m := merges[0]
+		assert.Len(t, m.Sources, 2)
+		assert.Equal(t, 0.9, m.Zone.RiskScore)
+		assert.Equal(t, "HIGH", m.Zone.Label)
+		// vòng bao: (111 + 300 + 300) / 2
+		assert.InDelta(t, 355.6, m.Zone.Radius, 1)
+		assert.InDelta(t, 10.0005, m.Zone.Center.Coordinates[1], 0.0001)
+	})
+
+	t.Run("locked zone is kept", func(t *testing.T) {
+		locked := b
+		locked.Locked = true
+		assert.Empty(t, usecase.PlanZoneMerges([]domain.Zone{a, locked, far}, 0.5, 0))
+	})
+
+	t.Run("override zone is kept", func(t *testing.T) {
+		override := b
+		override.Override = true
+		assert.Empty(t, usecase.PlanZoneMerges([]domain.Zone{a, override, far}, 0.5, 0))
+	})
+
+	t.Run("threshold not reached", func(t *testing.T) {
+		c := zoneAt(10.005, 106.7, 300, 0.4) // cách ~555m, giao rất ít
+		assert.Empty(t, usecase.PlanZoneMerges([]domain.Zone{a, c}, 0.5, 0))
+	})
+
+	t.Run("max radius", func(t *testing.T) {
+		assert.Empty(t, usecase.PlanZoneMerges([]domain.Zone{a, b}, 0.5, 320))
+	})
+}
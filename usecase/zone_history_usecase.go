@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type zoneHistoryUsecase struct {
+	repo           domain.ZoneHistoryRepository
+	contextTimeout time.Duration
+}
+
+func NewZoneHistoryUsecase(repo domain.ZoneHistoryRepository, timeout time.Duration) domain.ZoneHistoryUsecase {
+	return &zoneHistoryUsecase{
+		repo:           repo,
+		contextTimeout: timeout,
+	}
+}
+
+// History lấy chuỗi risk của một zone trong khoảng [from, to]
+func (u *zoneHistoryUsecase) History(ctx context.Context, zoneID primitive.ObjectID, from, to time.Time) ([]domain.ZoneRiskPoint, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.ListByZone(ctx, zoneID, from, to)
+}
+
+// Aggregate tổng hợp risk trong bounding box theo từng bucket thời gian
+func (u *zoneHistoryUsecase) Aggregate(ctx context.Context, b domain.BoundsFilter, from, to time.Time, bucket time.Duration) ([]domain.ZoneRiskBucket, error) {
+	ctx, cancel := context.WithTimeout(ctx, u.contextTimeout)
+	defer cancel()
+	return u.repo.AggregateInBounds(ctx, b, from, to, bucket)
+}
@@ -2,6 +2,8 @@ package usecase
 
 import (
 	"context"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
@@ -10,22 +12,78 @@ import (
 
 type zoneUsecase struct {
 	zoneRepository domain.ZoneRepository
+	history        domain.ZoneHistoryRepository // nil = không ghi history
+	notifier       domain.ZoneNotifier          // nil = không đẩy thay đổi realtime
+	merge          domain.ZoneMergeConfig
 	contextTimeout time.Duration
 }
 
-func NewZoneUsecase(repo domain.ZoneRepository, timeout time.Duration) domain.ZoneUsecase {
+func NewZoneUsecase(repo domain.ZoneRepository, history domain.ZoneHistoryRepository, notifier domain.ZoneNotifier, merge domain.ZoneMergeConfig, timeout time.Duration) domain.ZoneUsecase {
 	return &zoneUsecase{
 		zoneRepository: repo,
+		history:        history,
+		notifier:       notifier,
+		merge:          merge,
 		contextTimeout: timeout,
 	}
 }
 
+// notify báo thay đổi zone cho notifier (client đang xem vùng đó)
+func (zu *zoneUsecase) notify(ctx context.Context, e domain.ZoneEvent) {
+	if zu.notifier == nil {
+		return
+	}
+	if e.Cause == "" {
+		e.Cause = domain.ZoneCauseFrom(ctx).Type
+	}
+	zu.notifier.NotifyZone(e)
+}
+
+// record ghi một điểm history với cause lấy từ context
+func (zu *zoneUsecase) record(ctx context.Context, z *domain.Zone, prevRisk float64, prevLabel string) {
+	cause := domain.ZoneCauseFrom(ctx)
+	zu.recordPoints(ctx, domain.ZoneRiskPoint{
+		ZoneID:    z.ID,
+		Time:      time.Now(),
+		RiskScore: z.RiskScore,
+		Label:     z.Label,
+		PrevRisk:  prevRisk,
+		PrevLabel: prevLabel,
+		Cause:     cause.Type,
+		RefID:     cause.RefID,
+		Center:    z.Center,
+	})
+}
+
+// lỗi ghi history không làm hỏng thao tác chính
+func (zu *zoneUsecase) recordPoints(ctx context.Context, points ...domain.ZoneRiskPoint) {
+	if zu.history == nil {
+		return
+	}
+	if err := zu.history.Add(ctx, points...); err != nil {
+		log.Println("Failed to record zone history:", err)
+	}
+}
+
 // Create tạo một zone mới
 func (zu *zoneUsecase) Create(ctx context.Context, z *domain.Zone) error {
 	ctx, cancel := context.WithTimeout(ctx, zu.contextTimeout)
 	defer cancel()
 	z.UpdatedAt = time.Now().UnixMilli()
-	return zu.zoneRepository.Create(ctx, z)
+	z.BaseRisk = z.RiskScore
+	if err := zu.zoneRepository.Create(ctx, z); err != nil {
+		return err
+	}
+	zu.record(ctx, z, 0, "")
+	zu.notify(ctx, domain.ZoneEvent{Type: domain.ZoneEventCreated, Zone: *z})
+	return nil
+}
+
+// GetByID lấy một zone
+func (zu *zoneUsecase) GetByID(ctx context.Context, id primitive.ObjectID) (*domain.Zone, error) {
+	ctx, cancel := context.WithTimeout(ctx, zu.contextTimeout)
+	defer cancel()
+	return zu.zoneRepository.GetByID(ctx, id)
 }
 
 // FetchAll lấy tất cả zone
@@ -68,39 +126,57 @@ func (zu *zoneUsecase) AddRiskOrCreate(ctx context.Context, lat, lon, riskIncrem
 			},
 			Radius:    defaultRadius,
 			RiskScore: riskIncrement,
-			Label:     "LOW",
+			BaseRisk:  riskIncrement,
+			Label:     domain.RiskLabel(riskIncrement),
 			UpdatedAt: time.Now().UnixMilli(),
 		}
-		return zu.zoneRepository.Create(ctx2, newZone)
-	}
-	getLabel := func(riskscorein float64) string {
-		switch {
-		case riskscorein < 0.3:
-			return "LOW"
-		case riskscorein < 0.6:
-			return "MEDIUM"
-		default:
-			return "HIGH"
+		if err := zu.zoneRepository.Create(ctx2, newZone); err != nil {
+			return err
+		}
+		zu.record(ctx2, newZone, 0, "")
+		zu.notify(ctx2, domain.ZoneEvent{Type: domain.ZoneEventCreated, Zone: *newZone})
+
+		if zu.merge.Inline {
+			if err := zu.coalesceAround(ctx2, newZone); err != nil {
+				log.Println("Inline zone merge failed:", err)
+			}
 		}
+		return nil
 	}
 
-	// 3️⃣ Nếu có zone → tăng risk
+	// 3️⃣ Nếu có zone → tăng risk (trừ zone đã được đặt tay)
 	for _, z := range zones {
+		if z.Pinned() {
+			continue
+		}
+		prevRisk, prevLabel := z.RiskScore, z.Label
 		z.RiskScore += riskIncrement
 		if z.RiskScore > 1 {
 			z.RiskScore = 1
 		}
-		z.Label = getLabel(z.RiskScore)
+		z.Label = domain.RiskLabel(z.RiskScore)
 		z.UpdatedAt = time.Now().UnixMilli()
 		if err := zu.zoneRepository.Update(ctx2, &z); err != nil {
 			return err
 		}
+		zu.record(ctx2, &z, prevRisk, prevLabel)
+		zu.notify(ctx2, domain.ZoneEvent{Type: domain.ZoneEventUpdated, Zone: z, PreviousLabel: prevLabel})
 	}
 
 	return nil
 }
 
-func (zu *zoneUsecase) SetMaxRisk(ctx context.Context, lat, lon, newRisk float64) error {
+// SetMaxRisk nâng risk các zone chứa điểm; hazard của report có risk cao nhất quyết định half-life khi decay
+func (zu *zoneUsecase) SetMaxRisk(ctx context.Context, lat, lon, newRisk float64, hazard string) error {
+	return zu.raiseRisk(ctx, lat, lon, newRisk, hazard, true)
+}
+
+func (zu *zoneUsecase) RaiseRisk(ctx context.Context, lat, lon, newRisk float64, hazard string) error {
+	return zu.raiseRisk(ctx, lat, lon, newRisk, hazard, false)
+}
+
+// raiseRisk nâng risk các zone chứa điểm lên newRisk; bump = zone đã cao hơn thì vẫn cộng 0.1 (thêm một nguồn mới)
+func (zu *zoneUsecase) raiseRisk(ctx context.Context, lat, lon, newRisk float64, hazard string, bump bool) error {
 	ctx2, cancel := context.WithTimeout(ctx, zu.contextTimeout)
 	defer cancel()
 
@@ -109,34 +185,33 @@ func (zu *zoneUsecase) SetMaxRisk(ctx context.Context, lat, lon, newRisk float64
 		return err
 	}
 
-	// Helper map risk -> label
-	getLabel := func(r float64) string {
-		switch {
-		case r < 0.3:
-			return "LOW"
-		case r < 0.6:
-			return "MEDIUM"
-		default:
-			return "HIGH"
-		}
-	}
-
 	for _, z := range zones {
+		if z.Pinned() {
+			continue
+		}
+		prevRisk, prevLabel := z.RiskScore, z.Label
 		if z.RiskScore < newRisk {
 			z.RiskScore = newRisk
-		} else {
+			if hazard != "" {
+				z.Hazard = strings.ToUpper(hazard)
+			}
+		} else if bump {
 			z.RiskScore += 0.1
 			if z.RiskScore > 1.0 {
 				z.RiskScore = 1.0
 			}
+		} else {
+			continue
 		}
 
-		z.Label = getLabel(z.RiskScore)
+		z.Label = domain.RiskLabel(z.RiskScore)
 		z.UpdatedAt = time.Now().UnixMilli()
 
 		if err := zu.zoneRepository.Update(ctx2, &z); err != nil {
 			return err
 		}
+		zu.record(ctx2, &z, prevRisk, prevLabel)
+		zu.notify(ctx2, domain.ZoneEvent{Type: domain.ZoneEventUpdated, Zone: z, PreviousLabel: prevLabel})
 	}
 
 	return nil
@@ -147,7 +222,68 @@ func (zu *zoneUsecase) Update(ctx context.Context, z *domain.Zone) error {
 	ctx2, cancel := context.WithTimeout(ctx, zu.contextTimeout)
 	defer cancel()
 
-	return zu.zoneRepository.Update(ctx2, z)
+	prev, err := zu.zoneRepository.GetByID(ctx2, z.ID)
+	if err != nil {
+		return err
+	}
+	if err := zu.zoneRepository.Update(ctx2, z); err != nil {
+		return err
+	}
+	if prev.RiskScore != z.RiskScore || prev.Label != z.Label {
+		zu.record(ctx2, z, prev.RiskScore, prev.Label)
+	}
+	zu.notify(ctx2, domain.ZoneEvent{Type: domain.ZoneEventUpdated, Zone: *z, PreviousLabel: prev.Label})
+	return nil
+}
+
+// ApplyDecay ghi kết quả decay: cập nhật risk hàng loạt và xóa zone đã hết risk
+func (zu *zoneUsecase) ApplyDecay(ctx context.Context, updates []domain.ZoneRiskUpdate, removed []domain.Zone) error {
+	ctx2, cancel := context.WithTimeout(ctx, zu.contextTimeout)
+	defer cancel()
+
+	if err := zu.zoneRepository.BulkUpdateRisk(ctx2, updates); err != nil {
+		return err
+	}
+
+	ids := make([]primitive.ObjectID, len(removed))
+	for i, z := range removed {
+		ids[i] = z.ID
+	}
+	if err := zu.zoneRepository.DeleteMany(ctx2, ids); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	points := make([]domain.ZoneRiskPoint, 0, len(updates)+len(removed))
+	for _, u := range updates {
+		points = append(points, domain.ZoneRiskPoint{
+			ZoneID: u.ID, Time: now, RiskScore: u.RiskScore, Label: u.Label,
+			PrevRisk: u.PrevRisk, PrevLabel: u.PrevLabel, Cause: domain.ZoneCauseDecay, Center: u.Center,
+		})
+	}
+	for _, z := range removed {
+		points = append(points, domain.ZoneRiskPoint{
+			ZoneID: z.ID, Time: now, PrevRisk: z.RiskScore, PrevLabel: z.Label,
+			Cause: domain.ZoneCauseDecay, Center: z.Center, Removed: true,
+		})
+	}
+	zu.recordPoints(ctx2, points...)
+
+	for _, u := range updates {
+		zu.notify(ctx2, domain.ZoneEvent{
+			Type: domain.ZoneEventDecayed,
+			Zone: domain.Zone{
+				ID: u.ID, Center: u.Center, Radius: u.Radius,
+				RiskScore: u.RiskScore, Label: u.Label,
+			},
+			PreviousLabel: u.PrevLabel,
+			Cause:         domain.ZoneCauseDecay,
+		})
+	}
+	for _, z := range removed {
+		zu.notify(ctx2, domain.ZoneEvent{Type: domain.ZoneEventDeleted, Zone: z, PreviousLabel: z.Label, Cause: domain.ZoneCauseDecay})
+	}
+	return nil
 }
 
 // Delete zone theo ID
@@ -155,11 +291,20 @@ func (zu *zoneUsecase) Delete(ctx context.Context, id primitive.ObjectID) error
 	ctx2, cancel := context.WithTimeout(ctx, zu.contextTimeout)
 	defer cancel()
 
-	if repoWithDelete, ok := zu.zoneRepository.(interface {
-		Delete(ctx context.Context, id primitive.ObjectID) error
-	}); ok {
-		return repoWithDelete.Delete(ctx2, id)
+	prev, err := zu.zoneRepository.GetByID(ctx2, id)
+	if err != nil {
+		return err
+	}
+	if err := zu.zoneRepository.Delete(ctx2, id); err != nil {
+		return err
 	}
 
+	// điểm history cuối đánh dấu zone đã bị xóa
+	cause := domain.ZoneCauseFrom(ctx2)
+	zu.recordPoints(ctx2, domain.ZoneRiskPoint{
+		ZoneID: prev.ID, Time: time.Now(), PrevRisk: prev.RiskScore, PrevLabel: prev.Label,
+		Cause: cause.Type, RefID: cause.RefID, Center: prev.Center, Removed: true,
+	})
+	zu.notify(ctx2, domain.ZoneEvent{Type: domain.ZoneEventDeleted, Zone: *prev, PreviousLabel: prev.Label})
 	return nil
 }
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// EnrichmentSource là phần usecase mà worker cần để retry AI enrichment
+type EnrichmentSource interface {
+	// RequeuePending tạo lại job cho các report chưa có kết quả AI (chạy khi khởi động)
+	RequeuePending(ctx context.Context) (int, error)
+	// ProcessDueJobs lấy các job đến hạn và đẩy sang AIQueue
+	ProcessDueJobs(ctx context.Context) (int, error)
+}
+
+type EnrichmentWorker struct {
+	source   EnrichmentSource
+	interval time.Duration
+}
+
+func NewEnrichmentWorker(source EnrichmentSource, interval time.Duration) *EnrichmentWorker {
+	return &EnrichmentWorker{
+		source:   source,
+		interval: interval,
+	}
+}
+
+func (w *EnrichmentWorker) Start() {
+	go func() {
+		// sweeper: report bị mất job do restart sẽ được đưa lại vào hàng đợi
+		if n, err := w.source.RequeuePending(context.Background()); err != nil {
+			log.Println("enrichment sweeper failed:", err)
+		} else if n > 0 {
+			log.Printf("enrichment sweeper requeued %d reports\n", n)
+		}
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := w.source.ProcessDueJobs(context.Background()); err != nil {
+				log.Println("enrichment worker failed:", err)
+			}
+		}
+	}()
+}
+
+// Backoff tính thời gian chờ trước lần thử thứ attempt (bắt đầu từ 1): base * 2^(attempt-1), tối đa max
+func Backoff(base, max time.Duration, attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := base
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	if d > max {
+		return max
+	}
+	return d
+}
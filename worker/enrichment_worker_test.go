@@ -0,0 +1,29 @@
+package worker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/worker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	base := 30 * time.Second
+	max := 10 * time.Minute
+
+	t.Run("exponential", func(t *testing.T) {
+		assert.Equal(t, 30*time.Second, worker.Backoff(base, max, 1))
+		assert.Equal(t, 60*time.Second, worker.Backoff(base, max, 2))
+		assert.Equal(t, 120*time.Second, worker.Backoff(base, max, 3))
+	})
+
+	t.Run("capped", func(t *testing.T) {
+		assert.Equal(t, max, worker.Backoff(base, max, 6))
+		assert.Equal(t, max, worker.Backoff(base, max, 100))
+	})
+
+	t.Run("invalid attempt", func(t *testing.T) {
+		assert.Equal(t, base, worker.Backoff(base, max, 0))
+	})
+}
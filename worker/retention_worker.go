@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Pruner xóa dữ liệu cũ hơn mốc before
+type Pruner interface {
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RetentionWorker định kỳ xóa dữ liệu quá thời gian lưu trữ; retention <= 0 = giữ mãi
+type RetentionWorker struct {
+	name      string
+	pruner    Pruner
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewRetentionWorker(name string, pruner Pruner, retention, interval time.Duration) *RetentionWorker {
+	return &RetentionWorker{
+		name:      name,
+		pruner:    pruner,
+		retention: retention,
+		interval:  interval,
+	}
+}
+
+func (w *RetentionWorker) Start() {
+	if w.retention <= 0 {
+		log.Printf("Retention %s disabled, keeping data forever", w.name)
+		return
+	}
+
+	go func() {
+		w.run()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			w.run()
+		}
+	}()
+}
+
+func (w *RetentionWorker) run() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	n, err := w.pruner.Prune(ctx, time.Now().Add(-w.retention))
+	if err != nil {
+		log.Printf("Retention %s failed: %v", w.name, err)
+		return
+	}
+	if n > 0 {
+		log.Printf("Retention %s: removed %d documents", w.name, n)
+	}
+}
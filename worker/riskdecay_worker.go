@@ -2,19 +2,87 @@ package worker
 
 import (
 	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
 )
 
+// DecayPolicy: risk giảm theo hàm mũ kể từ Zone.UpdatedAt,
+// mỗi HalfLife thời gian risk còn một nửa.
+type DecayPolicy struct {
+	HalfLife        map[string]time.Duration // theo Zone.Hazard (FLOOD, FIRE, ...)
+	DefaultHalfLife time.Duration
+	DeclaredFloor   float64 // zone được công bố chính thức không xuống dưới mức này
+	RemoveBelow     float64 // zone thường có risk thấp hơn mức này sẽ bị xóa
+}
+
+// Risk tính risk hiện tại của zone; remove = true nếu zone nên bị xóa
+func (p DecayPolicy) Risk(z domain.Zone, now time.Time) (risk float64, remove bool) {
+	base := z.BaseRisk
+	if base == 0 {
+		// zone cũ chưa có baseRisk
+		base = z.RiskScore
+	}
+
+	risk = base
+	halfLife := p.halfLife(z.Hazard)
+	if z.UpdatedAt > 0 && halfLife > 0 {
+		age := now.Sub(time.UnixMilli(z.UpdatedAt))
+		if age > 0 {
+			risk = base * math.Pow(0.5, float64(age)/float64(halfLife))
+		}
+	}
+
+	if z.Declared {
+		return math.Max(risk, math.Min(p.DeclaredFloor, base)), false
+	}
+	return risk, risk < p.RemoveBelow
+}
+
+func (p DecayPolicy) halfLife(hazard string) time.Duration {
+	if d, ok := p.HalfLife[strings.ToUpper(hazard)]; ok {
+		return d
+	}
+	return p.DefaultHalfLife
+}
+
+// ParseHalfLives đọc chuỗi dạng "FLOOD=24,FIRE=6,DEFAULT=6" (đơn vị giờ)
+func ParseHalfLives(s string) (map[string]time.Duration, error) {
+	out := map[string]time.Duration{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid half-life entry %q", part)
+		}
+		hours, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+		if err != nil || hours <= 0 {
+			return nil, fmt.Errorf("invalid half-life hours %q", part)
+		}
+		out[strings.ToUpper(strings.TrimSpace(kv[0]))] = time.Duration(hours * float64(time.Hour))
+	}
+	return out, nil
+}
+
 type RiskDecayWorker struct {
 	zoneUC   domain.ZoneUsecase
+	policy   DecayPolicy
 	interval time.Duration
 }
 
-func NewRiskDecayWorker(zoneUC domain.ZoneUsecase, interval time.Duration) *RiskDecayWorker {
+// NewRiskDecayWorker: thay đổi zone được ZoneUsecase đẩy tới client qua notifier
+func NewRiskDecayWorker(zoneUC domain.ZoneUsecase, policy DecayPolicy, interval time.Duration) *RiskDecayWorker {
 	return &RiskDecayWorker{
 		zoneUC:   zoneUC,
+		policy:   policy,
 		interval: interval,
 	}
 }
@@ -30,41 +98,45 @@ func (w *RiskDecayWorker) Start() {
 	}()
 }
 
-// DecayAllZones chạy giảm risk cho tất cả zone
+// DecayAllZones tính lại risk cho tất cả zone rồi ghi một lần
 func (w *RiskDecayWorker) DecayAllZones() {
 	ctx := context.Background()
 	zones, err := w.zoneUC.FetchAll(ctx)
 	if err != nil {
+		log.Println("Risk decay: fetch zones failed:", err)
 		return
 	}
 
+	now := time.Now()
+	var (
+		updates []domain.ZoneRiskUpdate
+		removed []domain.Zone
+	)
+
 	for _, z := range zones {
-		newRisk := z.RiskScore - 0.1
-		if newRisk <= 0 {
-			// Xóa zone nếu risk <= 0
-			_ = w.zoneUC.Delete(ctx, z.ID)
+		if z.Locked {
+			continue
+		}
+		risk, remove := w.policy.Risk(z, now)
+		if remove {
+			removed = append(removed, z)
 			continue
 		}
 
-		if newRisk > 1.0 {
-			newRisk = 1.0
+		risk = math.Min(risk, 1.0)
+		label := domain.RiskLabel(risk)
+		// bỏ qua thay đổi quá nhỏ để không ghi DB mỗi tick
+		if label == z.Label && math.Abs(risk-z.RiskScore) < 0.01 {
+			continue
 		}
 
-		// Cập nhật risk + label
-		z.RiskScore = newRisk
-		z.Label = getLabel(newRisk)
-		_ = w.zoneUC.Update(ctx, &z)
+		updates = append(updates, domain.ZoneRiskUpdate{
+			ID: z.ID, RiskScore: risk, Label: label,
+			PrevRisk: z.RiskScore, PrevLabel: z.Label, Center: z.Center, Radius: z.Radius,
+		})
 	}
-}
 
-// Helper label
-func getLabel(r float64) string {
-	switch {
-	case r < 0.3:
-		return "LOW"
-	case r < 0.6:
-		return "MEDIUM"
-	default:
-		return "HIGH"
+	if err := w.zoneUC.ApplyDecay(ctx, updates, removed); err != nil {
+		log.Println("Risk decay: apply failed:", err)
 	}
 }
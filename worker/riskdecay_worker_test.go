@@ -0,0 +1,53 @@
+package worker_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/worker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecayPolicyRisk(t *testing.T) {
+	now := time.Now()
+	policy := worker.DecayPolicy{
+		HalfLife:        map[string]time.Duration{"FLOOD": 24 * time.Hour},
+		DefaultHalfLife: 2 * time.Hour,
+		DeclaredFloor:   0.6,
+		RemoveBelow:     0.05,
+	}
+	updatedAt := now.Add(-24 * time.Hour).UnixMilli()
+
+	t.Run("half-life per hazard", func(t *testing.T) {
+		risk, remove := policy.Risk(domain.Zone{BaseRisk: 0.8, Hazard: "flood", UpdatedAt: updatedAt}, now)
+		assert.InDelta(t, 0.4, risk, 0.001)
+		assert.False(t, remove)
+	})
+
+	t.Run("short default half-life removes zone", func(t *testing.T) {
+		_, remove := policy.Risk(domain.Zone{BaseRisk: 0.8, UpdatedAt: updatedAt}, now)
+		assert.True(t, remove)
+	})
+
+	t.Run("declared zone keeps floor", func(t *testing.T) {
+		risk, remove := policy.Risk(domain.Zone{BaseRisk: 0.9, Declared: true, UpdatedAt: updatedAt}, now)
+		assert.InDelta(t, 0.6, risk, 0.001)
+		assert.False(t, remove)
+	})
+
+	t.Run("legacy zone without baseRisk", func(t *testing.T) {
+		risk, _ := policy.Risk(domain.Zone{RiskScore: 0.5, Hazard: "FLOOD", UpdatedAt: now.UnixMilli()}, now)
+		assert.InDelta(t, 0.5, risk, 0.001)
+	})
+}
+
+func TestParseHalfLives(t *testing.T) {
+	hl, err := worker.ParseHalfLives("flood=24, FIRE=1.5")
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, hl["FLOOD"])
+	assert.Equal(t, 90*time.Minute, hl["FIRE"])
+
+	_, err = worker.ParseHalfLives("FLOOD")
+	assert.Error(t, err)
+}
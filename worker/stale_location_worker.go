@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StaleMarker chuyển location im lặng quá lâu sang UNKNOWN
+type StaleMarker interface {
+	MarkStale(ctx context.Context, now time.Time) (int, error)
+}
+
+// StaleLocationWorker định kỳ tìm user mất tín hiệu
+type StaleLocationWorker struct {
+	marker   StaleMarker
+	interval time.Duration
+}
+
+func NewStaleLocationWorker(marker StaleMarker, interval time.Duration) *StaleLocationWorker {
+	return &StaleLocationWorker{marker: marker, interval: interval}
+}
+
+func (w *StaleLocationWorker) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			w.Run()
+		}
+	}()
+}
+
+func (w *StaleLocationWorker) Run() {
+	n, err := w.marker.MarkStale(context.Background(), time.Now())
+	if err != nil {
+		log.Println("Stale location check failed:", err)
+	}
+	if n > 0 {
+		log.Printf("Stale location: %d users marked UNKNOWN", n)
+	}
+}
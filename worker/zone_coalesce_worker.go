@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/Storm-Watch-Platform/Storm_Watch_Backend/domain"
+)
+
+// ZoneCoalesceWorker định kỳ gộp các zone chồng lấn nhau
+type ZoneCoalesceWorker struct {
+	zoneUC    domain.ZoneUsecase
+	overlap   float64
+	maxRadius float64
+	interval  time.Duration
+}
+
+func NewZoneCoalesceWorker(zoneUC domain.ZoneUsecase, overlap, maxRadius float64, interval time.Duration) *ZoneCoalesceWorker {
+	return &ZoneCoalesceWorker{
+		zoneUC:    zoneUC,
+		overlap:   overlap,
+		maxRadius: maxRadius,
+		interval:  interval,
+	}
+}
+
+func (w *ZoneCoalesceWorker) Start() {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			w.Run()
+		}
+	}()
+}
+
+// Run chạy một lượt gộp; ZoneUsecase báo "merged" / "zone_replaced" qua notifier
+func (w *ZoneCoalesceWorker) Run() {
+	if _, err := w.zoneUC.Coalesce(context.Background(), w.overlap, w.maxRadius); err != nil {
+		log.Println("Zone coalesce failed:", err)
+	}
+}